@@ -45,11 +45,11 @@ const notesDaemon = `
 Notes:
   - To start API open terminal window and run chifra daemon.
   - See the API documentation (https://trueblocks.io/api) for more information.
+  - With --monitor, the monitors are freshened each time the scraper writes a chunk or updates the stage.
   - The --port option is deprecated, use --url instead.
   - The --grpc option is deprecated, there is no replacement.
   - The --api option is deprecated, there is no replacement.
-  - The --scrape option is deprecated, use chifra scrape instead.
-  - The --monitor option is deprecated, use chifra monitors --watch instead.`
+  - The --scrape option is deprecated, use chifra scrape instead.`
 
 func init() {
 	var capabilities caps.Capability // capabilities for chifra daemon
@@ -68,19 +68,19 @@ func init() {
 One of [ off | on ]`)
 	daemonCmd.Flags().StringVarP(&daemonPkg.GetOptions().Scrape, "scrape", "s", "", `deprecated, use chifra scrape instead (hidden)
 One of [ off | blooms | index ]`)
-	daemonCmd.Flags().BoolVarP(&daemonPkg.GetOptions().Monitor, "monitor", "m", false, `deprecated, use chifra monitors --watch instead (hidden)`)
+	daemonCmd.Flags().BoolVarP(&daemonPkg.GetOptions().Monitor, "monitor", "m", false, `run the monitor watcher (chifra monitors --watch) alongside the API server`)
+	daemonCmd.Flags().StringVarP(&daemonPkg.GetOptions().Watchlist, "watchlist", "", "", `available with --monitor option only, a file containing the addresses to watch (all existing monitors by default)`)
+	daemonCmd.Flags().StringVarP(&daemonPkg.GetOptions().Commands, "commands", "", "", `available with --monitor option only, the file containing the list of commands to apply to each watched address`)
 	if os.Getenv("TEST_MODE") != "true" {
 		_ = daemonCmd.Flags().MarkHidden("port")
 		_ = daemonCmd.Flags().MarkHidden("grpc")
 		_ = daemonCmd.Flags().MarkHidden("api")
 		_ = daemonCmd.Flags().MarkHidden("scrape")
-		_ = daemonCmd.Flags().MarkHidden("monitor")
 	}
 	_ = daemonCmd.Flags().MarkDeprecated("port", "The --port option has been deprecated.")
 	_ = daemonCmd.Flags().MarkDeprecated("grpc", "The --grpc option has been deprecated.")
 	_ = daemonCmd.Flags().MarkDeprecated("api", "The --api option has been deprecated.")
 	_ = daemonCmd.Flags().MarkDeprecated("scrape", "The --scrape option has been deprecated.")
-	_ = daemonCmd.Flags().MarkDeprecated("monitor", "The --monitor option has been deprecated.")
 	globals.InitGlobals("daemon", daemonCmd, &daemonPkg.GetOptions().Globals, capabilities)
 
 	daemonCmd.SetUsageTemplate(UsageWithNotes(notesDaemon))
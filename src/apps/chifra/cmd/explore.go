@@ -67,6 +67,8 @@ func init() {
 	// EXISTING_CODE
 	_ = exploreCmd.Flags().MarkHidden("verbose")
 	_ = exploreCmd.Flags().MarkHidden("fmt")
+	_ = exploreCmd.Flags().MarkHidden("fields")
+	_ = exploreCmd.Flags().MarkHidden("where")
 	// EXISTING_CODE
 
 	chifraCmd.AddCommand(exploreCmd)
@@ -59,7 +59,14 @@ Notes:
   - If the --reversed option is present, the appearance list is reversed prior to all processing (including filtering).
   - The --decache option will remove all cache items (blocks, transactions, traces, etc.) for the given address(es).
   - The --withdrawals option is only available on certain chains. It is ignored otherwise.
-  - The --traces option requires your RPC to provide trace data. See the README for more information.`
+  - The --traces option requires your RPC to provide trace data. See the README for more information.
+  - The --currency option reads daily rates (units of the currency per US dollar) from fxRates.csv in the configuration folder. Its columns are date, currency, and rate.
+  - The --gains option values each lot at the spotPrice of the statement that acquired it. Unpriced statements are treated as having a price of zero. The block and record options choose which disposals (or summaries) are reported, but every earlier acquisition counts toward their cost basis.
+  - With --nfts, each statement records a single token id. Its balances are the address's holdings of that token id alone.
+  - The --diagnose option makes a balanceOf call for each block it probes. It may be slow against a remote node.
+  - The --journal option names accounts using the templates in the [settings.journal] section of trueBlocks.toml. Counterparties are named from the names database.
+  - With --entity, each line of the file starts with an address (lines starting with # are ignored). Otherwise, the entity is every address in the names database carrying the given tag. Other addresses may not be given with --entity.
+  - With --cursor, each run exports every record after the cursor. The cursor is stored in the cache and advances only when the export completes without errors.`
 
 func init() {
 	var capabilities caps.Capability // capabilities for chifra export
@@ -89,10 +96,24 @@ func init() {
 	exportCmd.Flags().StringSliceVarP(&exportPkg.GetOptions().Topic, "topic", "B", nil, `for the --logs option only, filter logs to show only those with this topic(s)`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Reverted, "reverted", "V", false, `export only transactions that were reverted`)
 	exportCmd.Flags().StringSliceVarP(&exportPkg.GetOptions().Asset, "asset", "P", nil, `for the accounting options only, export statements only for this asset`)
+	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Nfts, "nfts", "", false, `for the accounting options only, export statements only for ERC-721 and ERC-1155 tokens`)
+	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Diagnose, "diagnose", "", false, `for the accounting options only, explain each token statement that does not reconcile by bisecting with balanceOf to find where the balance drifted (implies --statements)`)
 	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Flow, "flow", "f", "", `for the accounting options only, export statements with incoming, outgoing, or zero value
 One of [ in | out | zero ]`)
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Entity, "entity", "", "", `for the accounting options only, consolidate statements across the addresses of an entity given as a file of addresses or a tag in the names database (implies --statements)`)
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Intra, "intra", "", "eliminate", `for the --entity option only, eliminate transfers between the entity's addresses or keep them and flag the statement
+One of [ eliminate | flag ]`)
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Currency, "currency", "", "", `for the accounting options only, also report prices in this fiat currency (for example EUR or CHF) using the local FX rate table`)
+	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Gains, "gains", "", false, `for the accounting options only, export the realized gain or loss on each disposal of an asset`)
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().CostBasis, "cost_basis", "", "fifo", `for the --gains option only, the method used to match disposals with previously acquired lots
+One of [ fifo | lifo | hifo | average ]`)
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Period, "period", "", "", `for the accounting options only, summarize realized and unrealized gains per asset for each period (implies --gains)
+One of [ daily | monthly | quarterly | yearly ]`)
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Journal, "journal", "", "", `for the accounting options only, render statements as a double-entry journal in the given syntax (implies --statements)
+One of [ beancount | hledger | ledger ]`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Factory, "factory", "y", false, `for --traces only, report addresses created by (or self-destructed by) the given address(es)`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Unripe, "unripe", "u", false, `export transactions labeled unripe (i.e. less than 28 blocks old)`)
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Cursor, "cursor", "", "", `remember the last record exported for each address under this name and export only newer records on the next run with the same name`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Reversed, "reversed", "E", false, `produce results in reverse chronological order`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().NoZero, "no_zero", "z", false, `for the --count option only, suppress the display of zero appearance accounts`)
 	exportCmd.Flags().Uint64VarP((*uint64)(&exportPkg.GetOptions().FirstBlock), "first_block", "F", 0, `first block to process (inclusive)`)
@@ -34,7 +34,7 @@ func RunAbis(cmd *cobra.Command, args []string) error {
 // ServeAbis handles the abis command for the API. Returns an error.
 func ServeAbis(w http.ResponseWriter, r *http.Request) error {
 	opts := abisFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("abis", w, &opts.Globals)
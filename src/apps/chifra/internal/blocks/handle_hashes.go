@@ -41,6 +41,7 @@ func (opts *BlocksOptions) HandleHashes(rCtx *output.RenderCtx) error {
 			showProgress := opts.Globals.ShowProgress()
 			bar := logger.NewBar(logger.BarOptions{
 				Enabled: showProgress,
+				Report:  opts.Globals.ProgressReport(),
 				Total:   int64(cnt),
 			})
 
@@ -46,6 +46,7 @@ func (opts *BlocksOptions) HandleLogs(rCtx *output.RenderCtx) error {
 			showProgress := opts.Globals.ShowProgress()
 			bar := logger.NewBar(logger.BarOptions{
 				Enabled: showProgress,
+				Report:  opts.Globals.ProgressReport(),
 				Total:   int64(cnt),
 			})
 
@@ -34,7 +34,7 @@ func RunBlocks(cmd *cobra.Command, args []string) error {
 // ServeBlocks handles the blocks command for the API. Returns an error.
 func ServeBlocks(w http.ResponseWriter, r *http.Request) error {
 	opts := blocksFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("blocks", w, &opts.Globals)
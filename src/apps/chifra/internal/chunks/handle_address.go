@@ -28,10 +28,16 @@ func (opts *ChunksOptions) HandleAddresses(rCtx *output.RenderCtx, blockNums []b
 		var showAddresses func(walker *walk.CacheWalker, path string, first bool) (bool, error)
 		if opts.Globals.Verbose {
 			showAddresses = func(walker *walk.CacheWalker, path string, first bool) (bool, error) {
+				if rCtx.WasCanceled() {
+					return false, nil
+				}
 				return opts.handleResolvedRecords1(modelChan, walker, path)
 			}
 		} else {
 			showAddresses = func(walker *walk.CacheWalker, path string, first bool) (bool, error) {
+				if rCtx.WasCanceled() {
+					return false, nil
+				}
 				if path != index.ToBloomPath(path) {
 					return false, fmt.Errorf("should not happen in showAddresses")
 				}
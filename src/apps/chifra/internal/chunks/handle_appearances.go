@@ -22,6 +22,9 @@ func (opts *ChunksOptions) HandleAppearances(rCtx *output.RenderCtx, blockNums [
 
 	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
 		showAppearances := func(walker *walk.CacheWalker, path string, first bool) (bool, error) {
+			if rCtx.WasCanceled() {
+				return false, nil
+			}
 			if path != index.ToBloomPath(path) {
 				return false, fmt.Errorf("should not happen in showAppearances")
 			}
@@ -44,6 +47,9 @@ func (opts *ChunksOptions) HandleAppearances(rCtx *output.RenderCtx, blockNums [
 			}
 
 			for i := 0; i < int(indexChunk.Header.AppearanceCount); i++ {
+				if rCtx.WasCanceled() {
+					return false, nil
+				}
 				if opts.Globals.TestMode && i > walker.MaxTests() {
 					continue
 				}
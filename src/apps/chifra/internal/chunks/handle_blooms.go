@@ -21,6 +21,9 @@ func (opts *ChunksOptions) HandleBlooms(rCtx *output.RenderCtx, blockNums []base
 
 	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
 		showBloom := func(walker *walk.CacheWalker, path string, first bool) (bool, error) {
+			if rCtx.WasCanceled() {
+				return false, nil
+			}
 			if path != index.ToBloomPath(path) {
 				return false, fmt.Errorf("should not happen in showBloom")
 			}
@@ -47,6 +47,7 @@ func (opts *ChunksOptions) CheckDeep(cacheMan *manifest.Manifest, report *types.
 	showProgress := opts.Globals.ShowProgress()
 	bar := logger.NewBar(logger.BarOptions{
 		Enabled: showProgress,
+		Report:  opts.Globals.ProgressReport(),
 		Total:   int64(len(appMap)),
 	})
 
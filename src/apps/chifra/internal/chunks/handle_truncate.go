@@ -39,6 +39,7 @@ func (opts *ChunksOptions) HandleTruncate(rCtx *output.RenderCtx, blockNums []ba
 	showProgress := opts.Globals.ShowProgressNotTesting()
 	bar := logger.NewBar(logger.BarOptions{
 		Enabled: showProgress,
+		Report:  opts.Globals.ProgressReport(),
 		Total:   128,
 		Type:    logger.Expanding,
 	})
@@ -97,6 +98,7 @@ func (opts *ChunksOptions) HandleTruncate(rCtx *output.RenderCtx, blockNums []ba
 			bar.Finish(true /* newLine */)
 			bar = logger.NewBar(logger.BarOptions{
 				Enabled: showProgress,
+				Report:  opts.Globals.ProgressReport(),
 				Total:   20,
 				Type:    logger.Expanding,
 			})
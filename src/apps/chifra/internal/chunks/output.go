@@ -36,7 +36,7 @@ func RunChunks(cmd *cobra.Command, args []string) error {
 // ServeChunks handles the chunks command for the API. Returns an error.
 func ServeChunks(w http.ResponseWriter, r *http.Request) error {
 	opts := chunksFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("chunks", w, &opts.Globals)
@@ -12,8 +12,9 @@
 // are provided not only by the command line, but also the API server. We call this process the
 // flame server, which is written in Go. chifra serve is an alias for the chifra daemon command.
 //
-// In the future, this daemon may also manage other long-running processes such as chifra scrape
-// and chifra monitors, but for now, it's only managing the API server.
+// With the --monitor option, the daemon also runs the monitor watcher (see chifra monitors --watch)
+// alongside the API server. In the future, this daemon may also manage other long-running processes
+// such as chifra scrape.
 //
 // If the default port for the API server is in use, you may change it with the --url option.
 //
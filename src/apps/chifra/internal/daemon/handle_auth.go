@@ -0,0 +1,293 @@
+package daemonPkg
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+	"golang.org/x/time/rate"
+)
+
+const (
+	scopeNone  = "none"
+	scopeRead  = "read"
+	scopeAdmin = "admin"
+)
+
+// maxAnonymousClients bounds the number of anonymous clients whose rate limits are remembered. Once
+// it is reached, the client seen least recently is forgotten.
+const maxAnonymousClients = 10000
+
+// Authenticator checks each request's API key against the keys in the daemon's settings, makes
+// sure the key's scope allows the request, and applies the key's rate limit. With no keys in the
+// settings, every request is allowed, subject only to the anonymous rate limit.
+type Authenticator struct {
+	keys       []*apiKey
+	anonymous  string
+	anonRps    float64
+	anonMutex  sync.Mutex
+	anonLimits map[string]*list.Element
+	anonOrder  *list.List // of *anonymousClient, most recently seen first
+}
+
+type anonymousClient struct {
+	addr    string
+	limiter *rate.Limiter
+}
+
+type apiKey struct {
+	name    string
+	digest  [sha256.Size]byte
+	scope   string
+	limiter *rate.Limiter
+}
+
+// NewAuthenticator returns an authenticator for the daemon's settings or an error if they are invalid
+func NewAuthenticator(settings configtypes.DaemonGroup) (*Authenticator, error) {
+	a := &Authenticator{
+		anonymous:  scopeAdmin,
+		anonRps:    settings.AnonymousMaxRps,
+		anonLimits: make(map[string]*list.Element),
+		anonOrder:  list.New(),
+	}
+	if settings.AnonymousMaxRps < 0 {
+		return nil, errors.New("anonymousMaxRps may not be negative")
+	}
+
+	seen := make(map[[sha256.Size]byte]bool)
+	for i, key := range settings.Keys {
+		name := key.Name
+		if name == "" {
+			name = fmt.Sprintf("key %d", i+1)
+		}
+		if key.Key == "" {
+			return nil, fmt.Errorf("%s has no key", name)
+		}
+		scope := strings.ToLower(key.Scope)
+		if scope == "" {
+			scope = scopeRead
+		} else if scope != scopeRead && scope != scopeAdmin {
+			return nil, fmt.Errorf("the scope of %s must be read or admin", name)
+		}
+		if key.MaxRps < 0 {
+			return nil, fmt.Errorf("the maxRps of %s may not be negative", name)
+		}
+		digest := sha256.Sum256([]byte(key.Key))
+		if seen[digest] {
+			return nil, fmt.Errorf("the key of %s is used more than once", name)
+		}
+		seen[digest] = true
+		a.keys = append(a.keys, &apiKey{
+			name:    name,
+			digest:  digest,
+			scope:   scope,
+			limiter: newRateLimiter(key.MaxRps, key.Burst),
+		})
+	}
+
+	if len(a.keys) > 0 {
+		switch strings.ToLower(settings.Anonymous) {
+		case "", scopeNone:
+			a.anonymous = scopeNone
+		case scopeRead:
+			a.anonymous = scopeRead
+		default:
+			return nil, errors.New("anonymous must be none or read")
+		}
+	}
+
+	return a, nil
+}
+
+// newRateLimiter returns a limiter allowing rps requests per second with bursts of burst requests
+// (by default, one second's worth). A zero rate is not limited.
+func newRateLimiter(rps float64, burst uint64) *rate.Limiter {
+	if rps <= 0 {
+		return nil
+	}
+	if burst == 0 {
+		burst = uint64(math.Max(1, math.Ceil(rps)))
+	}
+	return rate.NewLimiter(rate.Limit(rps), int(burst))
+}
+
+// Handler authenticates each request before passing it on
+func (a *Authenticator) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := requestKey(r)
+
+		var scope, keyName string
+		var limiter *rate.Limiter
+		if token == "" {
+			scope, limiter = a.anonymous, a.anonymousLimiter(r)
+		} else {
+			key := a.lookup(token)
+			if key == nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="chifra"`)
+				RespondWithError(w, http.StatusUnauthorized, errors.New("invalid API key"))
+				return
+			}
+			scope, keyName, limiter = key.scope, key.name, key.limiter
+		}
+
+		if required := requiredScope(r); !allows(scope, required) {
+			if token == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="chifra"`)
+				RespondWithError(w, http.StatusUnauthorized, errors.New("an API key is required"))
+			} else {
+				RespondWithError(w, http.StatusForbidden, fmt.Errorf("%s may not make this request, which requires the %s scope", keyName, required))
+			}
+			return
+		}
+
+		if limiter != nil && !limiter.Allow() {
+			w.Header().Set("Retry-After", "1")
+			RespondWithError(w, http.StatusTooManyRequests, errors.New(http.StatusText(http.StatusTooManyRequests)))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// lookup returns the key matching the token or nil. Every key is compared (in constant time) so
+// that the time taken does not reveal which keys exist.
+func (a *Authenticator) lookup(token string) *apiKey {
+	digest := sha256.Sum256([]byte(token))
+	var found *apiKey
+	for _, key := range a.keys {
+		if subtle.ConstantTimeCompare(digest[:], key.digest[:]) == 1 {
+			found = key
+		}
+	}
+	return found
+}
+
+// anonymousLimiter returns the rate limiter of the request's client (by IP address), if any
+func (a *Authenticator) anonymousLimiter(r *http.Request) *rate.Limiter {
+	if a.anonRps <= 0 {
+		return nil
+	}
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		client = r.RemoteAddr
+	}
+
+	a.anonMutex.Lock()
+	defer a.anonMutex.Unlock()
+	if elem, ok := a.anonLimits[client]; ok {
+		a.anonOrder.MoveToFront(elem)
+		return elem.Value.(*anonymousClient).limiter
+	}
+	if a.anonOrder.Len() >= maxAnonymousClients {
+		oldest := a.anonOrder.Back()
+		a.anonOrder.Remove(oldest)
+		delete(a.anonLimits, oldest.Value.(*anonymousClient).addr)
+	}
+	limiter := newRateLimiter(a.anonRps, 0)
+	a.anonLimits[client] = a.anonOrder.PushFront(&anonymousClient{addr: client, limiter: limiter})
+	return limiter
+}
+
+// requestKey returns the API key sent with the request in an Authorization (Bearer) header, an
+// X-API-Key header, or (for clients such as browsers opening a websocket, which cannot send
+// headers) an apiKey query parameter. The query parameter is removed from the request (even if a
+// header carries the key) so that it is neither logged nor mistaken for one of the route's options.
+func requestKey(r *http.Request) string {
+	var query string
+	if values := r.URL.Query(); values.Has("apiKey") {
+		query = values.Get("apiKey")
+		values.Del("apiKey")
+		r.URL.RawQuery = values.Encode()
+		r.RequestURI = r.URL.RequestURI()
+	}
+
+	if auth := r.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return query
+}
+
+// adminRoutes are the routes that change the server's data whatever their options
+var adminRoutes = map[string]bool{
+	"/init":   true,
+	"/scrape": true,
+}
+
+// adminOptions are the options that change the server's data, by route
+var adminOptions = map[string][]string{
+	"/chunks":   {"pin", "publish", "truncate", "rewrite", "unpin", "tag"},
+	"/export":   {"cursor"},
+	"/monitors": {"delete", "undelete", "remove", "clean"},
+	"/names":    {"create", "update", "delete", "undelete", "remove", "clean", "autoname"},
+	"/when":     {"update"},
+}
+
+// requiredScope returns the scope a key must have to make the request
+func requiredScope(r *http.Request) string {
+	if r.URL.Path == "/jobs" && r.Method == http.MethodPost {
+		// a job requires the scope of the request it runs
+		if _, req, err := newJobRequest(r.Context(), r, jobRoutes); err == nil {
+			return requiredScope(req)
+		}
+		return scopeRead
+	}
+	if strings.HasPrefix(r.URL.Path, "/jobs/") {
+		// job ids cannot be guessed, so whoever started a job (with any scope) may cancel it
+		return scopeRead
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return scopeAdmin
+	}
+	if adminRoutes[r.URL.Path] {
+		return scopeAdmin
+	}
+
+	values := r.URL.Query()
+	if values.Has("decache") {
+		return scopeAdmin
+	}
+	for _, option := range adminOptions[r.URL.Path] {
+		if values.Has(option) {
+			return scopeAdmin
+		}
+	}
+	return scopeRead
+}
+
+// allows returns true if the scope is sufficient for the required scope
+func allows(scope, required string) bool {
+	switch scope {
+	case scopeAdmin:
+		return true
+	case scopeRead:
+		return required == scopeRead
+	}
+	return false
+}
+
+// securityString describes the server's security settings for the startup report
+func securityString(settings configtypes.DaemonGroup) string {
+	parts := []string{}
+	if len(settings.CertFile) > 0 {
+		parts = append(parts, "tls")
+	}
+	if len(settings.Keys) > 0 {
+		parts = append(parts, fmt.Sprintf("%d api keys", len(settings.Keys)))
+	}
+	if len(parts) == 0 {
+		return "none (open to all)"
+	}
+	return strings.Join(parts, ", ")
+}
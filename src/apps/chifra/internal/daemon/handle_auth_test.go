@@ -0,0 +1,171 @@
+package daemonPkg
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+	"golang.org/x/time/rate"
+)
+
+func serveAuth(t *testing.T, a *Authenticator, method, target string, headers map[string]string) (int, *http.Request) {
+	t.Helper()
+	var seen *http.Request
+	handler := a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r
+	}))
+	r := httptest.NewRequest(method, target, nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Code, seen
+}
+
+func TestAuthenticator(t *testing.T) {
+	a, err := NewAuthenticator(configtypes.DaemonGroup{
+		Keys: []configtypes.DaemonKey{
+			{Name: "reader", Key: "read-key"},
+			{Name: "admin", Key: "admin-key", Scope: "admin"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method   string
+		target   string
+		headers  map[string]string
+		expected int
+	}{
+		{"GET", "/blocks?blocks=1", nil, http.StatusUnauthorized},
+		{"GET", "/blocks?blocks=1", map[string]string{"Authorization": "Bearer wrong"}, http.StatusUnauthorized},
+		{"GET", "/blocks?blocks=1", map[string]string{"Authorization": "Bearer read-key"}, http.StatusOK},
+		{"GET", "/blocks?blocks=1", map[string]string{"X-API-Key": "read-key"}, http.StatusOK},
+		{"GET", "/blocks?blocks=1&apiKey=read-key", nil, http.StatusOK},
+		{"GET", "/names?terms=x", map[string]string{"X-API-Key": "read-key"}, http.StatusOK},
+		{"GET", "/names?autoname=0x1", map[string]string{"X-API-Key": "read-key"}, http.StatusForbidden},
+		{"POST", "/names", map[string]string{"X-API-Key": "read-key"}, http.StatusForbidden},
+		{"POST", "/names", map[string]string{"X-API-Key": "admin-key"}, http.StatusOK},
+		{"DELETE", "/monitors?delete", map[string]string{"X-API-Key": "read-key"}, http.StatusForbidden},
+		{"GET", "/monitors?list", map[string]string{"X-API-Key": "read-key"}, http.StatusOK},
+		{"GET", "/chunks?mode=manifest&pin", map[string]string{"X-API-Key": "read-key"}, http.StatusForbidden},
+		{"GET", "/export?addrs=0x1&decache", map[string]string{"X-API-Key": "read-key"}, http.StatusForbidden},
+		{"GET", "/export?addrs=0x1&cursor=etl", map[string]string{"X-API-Key": "read-key"}, http.StatusForbidden},
+		{"GET", "/when?blocks=1", map[string]string{"X-API-Key": "read-key"}, http.StatusOK},
+		{"GET", "/when?timestamps&update", map[string]string{"X-API-Key": "read-key"}, http.StatusForbidden},
+		{"GET", "/scrape", map[string]string{"X-API-Key": "admin-key"}, http.StatusOK},
+		{"POST", "/jobs?route=/export&addrs=0x1", map[string]string{"X-API-Key": "read-key"}, http.StatusOK},
+		{"POST", "/jobs?route=/export&addrs=0x1&decache", map[string]string{"X-API-Key": "read-key"}, http.StatusForbidden},
+		{"POST", "/jobs?route=/scrape", map[string]string{"X-API-Key": "read-key"}, http.StatusForbidden},
+		{"DELETE", "/jobs/0123", map[string]string{"X-API-Key": "read-key"}, http.StatusOK},
+	}
+	for _, test := range tests {
+		if code, _ := serveAuth(t, a, test.method, test.target, test.headers); code != test.expected {
+			t.Errorf("%s %s %v: got %d, expected %d", test.method, test.target, test.headers, code, test.expected)
+		}
+	}
+
+	// The key is removed from the query so it is not logged or parsed as an option
+	if _, r := serveAuth(t, a, "GET", "/blocks?blocks=1&apiKey=read-key", nil); r == nil ||
+		r.URL.RawQuery != "blocks=1" || r.RequestURI != "/blocks?blocks=1" {
+		t.Errorf("the apiKey parameter was not removed: %v", r)
+	}
+	// ...even if a header carries the key
+	if _, r := serveAuth(t, a, "GET", "/blocks?blocks=1&apiKey=read-key", map[string]string{"X-API-Key": "read-key"}); r == nil ||
+		r.URL.RawQuery != "blocks=1" || len(validateRequest(r)) > 0 {
+		t.Errorf("the apiKey parameter was not removed: %v", r)
+	}
+}
+
+func TestAuthenticatorOpen(t *testing.T) {
+	a, err := NewAuthenticator(configtypes.DaemonGroup{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := serveAuth(t, a, "DELETE", "/monitors?remove", nil); code != http.StatusOK {
+		t.Errorf("a server without keys should be open, got %d", code)
+	}
+
+	a, _ = NewAuthenticator(configtypes.DaemonGroup{
+		Keys:      []configtypes.DaemonKey{{Key: "admin-key", Scope: "admin"}},
+		Anonymous: "read",
+	})
+	if code, _ := serveAuth(t, a, "GET", "/status", nil); code != http.StatusOK {
+		t.Errorf("anonymous reads should be allowed, got %d", code)
+	}
+	if code, _ := serveAuth(t, a, "POST", "/monitor/pause", nil); code != http.StatusUnauthorized {
+		t.Errorf("anonymous changes should not be allowed, got %d", code)
+	}
+}
+
+func TestAuthenticatorRateLimits(t *testing.T) {
+	a, err := NewAuthenticator(configtypes.DaemonGroup{
+		Keys:            []configtypes.DaemonKey{{Key: "slow-key", MaxRps: 0.001, Burst: 2}},
+		Anonymous:       "read",
+		AnonymousMaxRps: 0.001,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := map[string]string{"X-API-Key": "slow-key"}
+	for i, expected := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		if code, _ := serveAuth(t, a, "GET", "/status", key); code != expected {
+			t.Errorf("request %d: got %d, expected %d", i, code, expected)
+		}
+	}
+
+	// Each anonymous client has its own limit
+	for i, expected := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		if code, _ := serveAuth(t, a, "GET", "/status", nil); code != expected {
+			t.Errorf("anonymous request %d: got %d, expected %d", i, code, expected)
+		}
+	}
+}
+
+func TestAuthenticatorAnonymousClients(t *testing.T) {
+	a, err := NewAuthenticator(configtypes.DaemonGroup{AnonymousMaxRps: 0.001})
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter := func(addr string) *rate.Limiter {
+		r := httptest.NewRequest("GET", "/status", nil)
+		r.RemoteAddr = addr + ":1234"
+		return a.anonymousLimiter(r)
+	}
+
+	busy := limiter("10.0.0.1")
+	for i := 0; i < maxAnonymousClients+10; i++ {
+		limiter(fmt.Sprintf("10.1.%d.%d", i/256, i%256))
+		if i%1000 == 0 && limiter("10.0.0.1") != busy {
+			t.Fatalf("a client seen recently was forgotten after %d others", i)
+		}
+	}
+	if len(a.anonLimits) != maxAnonymousClients || a.anonOrder.Len() != maxAnonymousClients {
+		t.Errorf("expected %d clients, got %d", maxAnonymousClients, len(a.anonLimits))
+	}
+	if _, ok := a.anonLimits["10.1.0.0"]; ok {
+		t.Error("the client seen least recently should have been forgotten")
+	}
+}
+
+func TestAuthenticatorSettings(t *testing.T) {
+	invalid := []configtypes.DaemonGroup{
+		{Keys: []configtypes.DaemonKey{{Name: "empty"}}},
+		{Keys: []configtypes.DaemonKey{{Key: "k", Scope: "owner"}}},
+		{Keys: []configtypes.DaemonKey{{Key: "k"}, {Key: "k"}}},
+		{Keys: []configtypes.DaemonKey{{Key: "k", MaxRps: -1}}},
+		{Keys: []configtypes.DaemonKey{{Key: "k"}}, Anonymous: "admin"},
+		{AnonymousMaxRps: -1},
+	}
+	for _, settings := range invalid {
+		if _, err := NewAuthenticator(settings); err == nil {
+			t.Errorf("expected an error for %s", settings.String())
+		}
+	}
+}
@@ -0,0 +1,539 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package daemonPkg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/progress"
+)
+
+const (
+	JobRunning  = "running"
+	JobFinished = "finished"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+)
+
+// jobProgressInterval is the least time between two progress reports of a running job
+const jobProgressInterval = time.Second
+
+// jobRetention is how long a job (and its result) is kept once it ends
+const jobRetention = 7 * 24 * time.Hour
+
+// Job is a request to one of the server's routes run in the background. The route's output is
+// written to a file in the cache so that it may be fetched (as often as needed) once the job ends.
+type Job struct {
+	ID          string   `json:"id"`
+	Route       string   `json:"route"`
+	Query       string   `json:"query,omitempty"`
+	State       string   `json:"state"`
+	Progress    *Message `json:"progress,omitempty"`
+	Status      int      `json:"status,omitempty"`
+	ContentType string   `json:"contentType"`
+	NBytes      int64    `json:"nBytes"`
+	Started     string   `json:"started"`
+	Ended       string   `json:"ended,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// runningJob holds what is needed to report on and cancel a job while it runs
+type runningJob struct {
+	cancel context.CancelFunc
+	writer *jobWriter
+}
+
+// JobManager starts, tracks and cancels the server's jobs. Each job is kept in its own folder
+// (holding the job's description and its result) until it is deleted or, once it has ended, for
+// the retention period.
+type JobManager struct {
+	folder    string
+	retention time.Duration
+	routes    map[string]http.HandlerFunc
+	mutex     sync.Mutex
+	jobs      map[string]*Job
+	running   map[string]*runningJob
+}
+
+var jobManager *JobManager
+
+// jobRoutes are the routes that may be run as jobs (every GET route of the API)
+var jobRoutes map[string]http.HandlerFunc
+
+func init() {
+	jobRoutes = make(map[string]http.HandlerFunc)
+	for _, route := range routes {
+		if route.Method == http.MethodGet && strings.HasPrefix(route.Name, "Route") {
+			jobRoutes[route.Pattern] = route.HandlerFunc
+		}
+	}
+}
+
+// NewJobManager returns a manager keeping its jobs in folder and running the server's GET routes.
+// Jobs found there from an earlier run of the server are reloaded (unless they are past the
+// retention period). Those that were still running are marked as failed.
+func NewJobManager(folder string) (*JobManager, error) {
+	if err := file.EstablishFolder(folder); err != nil {
+		return nil, err
+	}
+
+	m := &JobManager{
+		folder:    folder,
+		retention: jobRetention,
+		routes:    jobRoutes,
+		jobs:      make(map[string]*Job),
+		running:   make(map[string]*runningJob),
+	}
+
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		contents, err := os.ReadFile(filepath.Join(folder, entry.Name(), "job.json"))
+		if err != nil {
+			continue
+		}
+		job := &Job{}
+		if err := json.Unmarshal(contents, job); err != nil || job.ID != entry.Name() {
+			continue
+		}
+		if job.State == JobRunning {
+			job.State = JobFailed
+			job.Error = "the server stopped before the job ended"
+			job.NBytes = file.FileSize(m.resultPath(job.ID))
+			_ = m.save(job)
+		}
+		m.jobs[job.ID] = job
+	}
+	m.purge()
+
+	return m, nil
+}
+
+// purge deletes the jobs that ended longer ago than the retention period. The caller holds the
+// mutex (or is the only user of the manager).
+func (m *JobManager) purge() {
+	for id, job := range m.jobs {
+		if job.State == JobRunning {
+			continue
+		}
+		// a job's description is last saved when it ends
+		info, err := os.Stat(filepath.Join(m.folder, id, "job.json"))
+		if err != nil || time.Since(info.ModTime()) > m.retention {
+			delete(m.jobs, id)
+			_ = os.RemoveAll(filepath.Join(m.folder, id))
+		}
+	}
+}
+
+// Start runs the route named in the request's route parameter in the background. The request's
+// other parameters, which are validated as they would be for the route itself, are passed to it.
+func (m *JobManager) Start(r *http.Request) (*Job, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	route, req, err := newJobRequest(ctx, r, m.routes)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if invalid := validateRequest(req); len(invalid) > 0 {
+		cancel()
+		return nil, &invalidParametersError{invalid: invalid}
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if err := file.EstablishFolder(filepath.Join(m.folder, id)); err != nil {
+		cancel()
+		return nil, err
+	}
+	result, err := os.Create(m.resultPath(id))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	job := &Job{
+		ID:          id,
+		Route:       route,
+		Query:       req.URL.RawQuery,
+		State:       JobRunning,
+		ContentType: contentTypeFor(req.URL.Query().Get("fmt")),
+		Started:     now(),
+	}
+	if err := m.save(job); err != nil {
+		cancel()
+		result.Close()
+		return nil, err
+	}
+
+	writer := newJobWriter(result)
+	// the route reports its progress and the errors it streams to the job's writer
+	req = req.WithContext(progress.WithReporter(ctx, writer))
+	m.mutex.Lock()
+	m.purge()
+	m.jobs[id] = job
+	m.running[id] = &runningJob{cancel: cancel, writer: writer}
+	ret := *job
+	m.mutex.Unlock()
+
+	done := m.report(id, writer.progress)
+	go func() {
+		defer func() { <-done }()
+		m.run(ctx, m.routes[route], req, writer)
+	}()
+
+	return &ret, nil
+}
+
+// run serves the job's request into its result file and reports how it ended
+func (m *JobManager) run(ctx context.Context, handler http.HandlerFunc, req *http.Request, writer *jobWriter) {
+	defer writer.closeProgress()
+	defer writer.result.Close()
+
+	writer.progress <- &progress.ProgressMsg{Event: progress.Start, Message: "started"}
+	err := serveJob(handler, req, writer)
+	switch {
+	case ctx.Err() != nil:
+		writer.progress <- &progress.ProgressMsg{Event: progress.Cancelled, Message: "canceled"}
+	case err != nil:
+		writer.progress <- &progress.ProgressMsg{Event: progress.Error, Message: "failed", Error: err}
+	case writer.status >= http.StatusBadRequest:
+		err := fmt.Errorf("the route responded with status %d (see the job's result)", writer.status)
+		writer.progress <- &progress.ProgressMsg{Event: progress.Error, Message: "failed", Error: err}
+	case writer.routeError() != nil:
+		err := fmt.Errorf("the route reported an error: %w (see the job's result)", writer.routeError())
+		writer.progress <- &progress.ProgressMsg{Event: progress.Error, Message: "failed", Error: err}
+	default:
+		writer.progress <- &progress.ProgressMsg{Event: progress.Finished, Message: "finished"}
+	}
+}
+
+// serveJob serves the request, turning a panic in the route into an error rather than stopping the server
+func serveJob(handler http.HandlerFunc, req *http.Request, writer *jobWriter) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("the route panicked: %v", r)
+		}
+	}()
+	handler(writer, req)
+	return nil
+}
+
+// report records the progress of the job as it arrives until the channel is closed, which closes
+// the returned channel
+func (m *JobManager) report(id string, progressChan chan *progress.ProgressMsg) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range progressChan {
+			m.mutex.Lock()
+			job := m.jobs[id]
+			running := m.running[id]
+			job.Progress = &Message{Action: ProgressMessage, ID: id, Content: msg.Message}
+			job.NBytes = running.writer.nBytes.Load()
+			ended := true
+			switch msg.Event {
+			case progress.Finished:
+				job.State = JobFinished
+			case progress.Error:
+				job.State = JobFailed
+				job.Error = msg.Error.Error()
+			case progress.Cancelled:
+				job.State = JobCanceled
+			default:
+				ended = false
+			}
+			if ended {
+				job.Status = running.writer.status
+				job.Ended = now()
+				delete(m.running, id)
+			}
+			_ = m.save(job)
+			m.mutex.Unlock()
+		}
+	}()
+	return done
+}
+
+// Get returns a copy of the job with the given id or false if there is no such job
+func (m *JobManager) Get(id string) (Job, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	ret := *job
+	if running, ok := m.running[id]; ok {
+		ret.NBytes = running.writer.nBytes.Load()
+	}
+	return ret, true
+}
+
+// Cancel cancels the job if it is running or, if it has ended, deletes it and its result
+func (m *JobManager) Cancel(id string) (Job, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	if running, ok := m.running[id]; ok {
+		running.cancel()
+		return *job, true
+	}
+	delete(m.jobs, id)
+	_ = os.RemoveAll(filepath.Join(m.folder, id))
+	return *job, true
+}
+
+// save writes the job's description to its folder
+func (m *JobManager) save(job *Job) error {
+	contents, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.folder, job.ID, "job.json"), contents, 0644)
+}
+
+func (m *JobManager) resultPath(id string) string {
+	return filepath.Join(m.folder, id, "result")
+}
+
+// newJobRequest returns the name of the route (one of routes) and the request to serve for a job
+// started by r
+func newJobRequest(ctx context.Context, r *http.Request, routes map[string]http.HandlerFunc) (string, *http.Request, error) {
+	values := r.URL.Query()
+	route := values.Get("route")
+	if !strings.HasPrefix(route, "/") {
+		route = "/" + route
+	}
+	if _, ok := routes[route]; !ok {
+		return "", nil, fmt.Errorf("the route parameter (%s) must name one of the server's routes such as /export", values.Get("route"))
+	}
+	values.Del("route")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, route+"?"+values.Encode(), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	req.RequestURI = req.URL.RequestURI()
+	req.RemoteAddr = r.RemoteAddr
+	if agent := r.Header.Get("User-Agent"); agent != "" {
+		req.Header.Set("User-Agent", agent)
+	}
+	return route, req, nil
+}
+
+// newJobID returns a random (and so unguessable) identifier for a job
+func newJobID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func now() string {
+	return base.FormattedDate(base.Timestamp(time.Now().Unix()))
+}
+
+// jobWriter is the http.ResponseWriter a job's route writes to. It writes the route's output to
+// the job's result file and reports the progress of the job from time to time: the route's own
+// progress if it reports any (see progress.Reporter), otherwise the number of bytes written.
+type jobWriter struct {
+	result       *os.File
+	header       http.Header
+	status       int
+	nBytes       atomic.Int64
+	progress     chan *progress.ProgressMsg
+	mutex        sync.Mutex
+	lastReport   time.Time
+	routeReports atomic.Bool
+	err          error
+	closed       bool
+}
+
+func newJobWriter(result *os.File) *jobWriter {
+	return &jobWriter{
+		result:   result,
+		header:   http.Header{},
+		status:   http.StatusOK,
+		progress: progress.MakeChan(),
+	}
+}
+
+func (w *jobWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *jobWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *jobWriter) Write(bytes []byte) (int, error) {
+	n, err := w.result.Write(bytes)
+	nBytes := w.nBytes.Add(int64(n))
+	if !w.routeReports.Load() {
+		w.update(fmt.Sprintf("%d bytes written", nBytes))
+	}
+	return n, err
+}
+
+// ReportProgress reports the progress of the job's route
+func (w *jobWriter) ReportProgress(msg string) {
+	w.routeReports.Store(true)
+	w.update(msg)
+}
+
+// ReportError records the first error the job's route streams in its output. Such a job fails
+// even though the route responds with a success status.
+func (w *jobWriter) ReportError(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *jobWriter) routeError() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.err
+}
+
+// closeProgress closes the progress channel once the job has ended. The route's goroutines may
+// outlive it (if the job was canceled), so later reports are ignored.
+func (w *jobWriter) closeProgress() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.closed = true
+	close(w.progress)
+}
+
+// update reports the job's progress unless it was reported less than jobProgressInterval ago
+func (w *jobWriter) update(msg string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed || time.Since(w.lastReport) < jobProgressInterval {
+		return
+	}
+	w.lastReport = time.Now()
+	select {
+	case w.progress <- &progress.ProgressMsg{Event: progress.Update, Message: msg}:
+	default: // a report is dropped rather than slow the job
+	}
+}
+
+// respondWithJob writes the job to the response in the same shape as the other routes' data
+func respondWithJob(w http.ResponseWriter, httpStatus int, job Job) {
+	type JobResponse struct {
+		Data []Job `json:"data"`
+	}
+	marshalled, _ := json.MarshalIndent(JobResponse{Data: []Job{job}}, "", "  ")
+	w.WriteHeader(httpStatus)
+	_, _ = w.Write(marshalled)
+}
+
+// HandleJobStart starts a job and responds with its description. The job's id is used to get
+// its progress and, once it ends, its result.
+func HandleJobStart(m *JobManager, w http.ResponseWriter, r *http.Request) {
+	if m == nil {
+		RespondWithError(w, http.StatusServiceUnavailable, errors.New("jobs are not available"))
+		return
+	}
+	job, err := m.Start(r)
+	if err != nil {
+		var invalid *invalidParametersError
+		if errors.As(err, &invalid) {
+			respondWithInvalid(w, invalid.invalid)
+		} else {
+			RespondWithError(w, http.StatusBadRequest, err)
+		}
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	respondWithJob(w, http.StatusAccepted, *job)
+}
+
+// HandleJobStatus responds with the job's description, including its progress
+func HandleJobStatus(m *JobManager, w http.ResponseWriter, id string) {
+	if job, ok := lookupJob(m, w, id); ok {
+		respondWithJob(w, http.StatusOK, job)
+	}
+}
+
+// HandleJobCancel cancels a running job or deletes a job that has ended
+func HandleJobCancel(m *JobManager, w http.ResponseWriter, id string) {
+	if _, ok := lookupJob(m, w, id); !ok {
+		return
+	}
+	job, _ := m.Cancel(id)
+	respondWithJob(w, http.StatusOK, job)
+}
+
+// HandleJobResult streams the output of a job that has ended. Range requests are honored so
+// that a client may resume an interrupted download.
+func HandleJobResult(m *JobManager, w http.ResponseWriter, r *http.Request, id string) {
+	job, ok := lookupJob(m, w, id)
+	if !ok {
+		return
+	}
+	if job.State == JobRunning {
+		RespondWithError(w, http.StatusConflict, fmt.Errorf("job %s has not ended", id))
+		return
+	}
+
+	result, err := os.Open(m.resultPath(id))
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer result.Close()
+
+	w.Header().Set("Content-Type", job.ContentType)
+	if job.Status >= http.StatusBadRequest {
+		w.WriteHeader(job.Status)
+		_, _ = io.Copy(w, result)
+		return
+	}
+	info, _ := result.Stat()
+	http.ServeContent(w, r, "", info.ModTime(), result)
+}
+
+func lookupJob(m *JobManager, w http.ResponseWriter, id string) (Job, bool) {
+	if m == nil {
+		RespondWithError(w, http.StatusServiceUnavailable, errors.New("jobs are not available"))
+		return Job{}, false
+	}
+	job, ok := m.Get(id)
+	if !ok {
+		RespondWithError(w, http.StatusNotFound, fmt.Errorf("job %s not found", id))
+	}
+	return job, ok
+}
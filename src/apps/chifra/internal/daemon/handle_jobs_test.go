@@ -0,0 +1,241 @@
+package daemonPkg
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/progress"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// withJobRoute adds a route that the manager may run as a job. The manager's routes are copied
+// so that the server's routes are left as they are.
+func withJobRoute(m *JobManager, route string, handler http.HandlerFunc) {
+	routes := make(map[string]http.HandlerFunc, len(m.routes)+1)
+	for pattern, h := range m.routes {
+		routes[pattern] = h
+	}
+	routes[route] = handler
+	m.routes = routes
+}
+
+func waitForJob(t *testing.T, m *JobManager, id string) Job {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if job, ok := m.Get(id); ok && job.State != JobRunning {
+			return job
+		}
+	}
+	t.Fatalf("timed out waiting for job %s", id)
+	return Job{}
+}
+
+func TestJobs(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "jobs")
+	m, err := NewJobManager(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withJobRoute(m, "/test", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": ["` + r.URL.Query().Get("value") + `"]}`))
+	})
+
+	w := httptest.NewRecorder()
+	HandleJobStart(m, w, httptest.NewRequest("POST", "/jobs?route=test&value=hello&fmt=csv", nil))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected the job to start, got %d: %s", w.Code, w.Body.String())
+	}
+	id := strings.TrimPrefix(w.Header().Get("Location"), "/jobs/")
+
+	job := waitForJob(t, m, id)
+	if job.State != JobFinished || job.Route != "/test" || job.Query != "fmt=csv&value=hello" ||
+		job.ContentType != "text/csv" || job.NBytes != 19 || job.Progress == nil || job.Progress.Action != ProgressMessage {
+		t.Errorf("unexpected job %+v", job)
+	}
+
+	w = httptest.NewRecorder()
+	HandleJobResult(m, w, httptest.NewRequest("GET", "/jobs/"+id+"/result", nil), id)
+	if w.Code != http.StatusOK || w.Body.String() != `{"data": ["hello"]}` || w.Header().Get("Content-Type") != "text/csv" {
+		t.Errorf("unexpected result %d %s", w.Code, w.Body.String())
+	}
+
+	// the result may be fetched in parts
+	r := httptest.NewRequest("GET", "/jobs/"+id+"/result", nil)
+	r.Header.Set("Range", "bytes=11-")
+	w = httptest.NewRecorder()
+	HandleJobResult(m, w, r, id)
+	if w.Code != http.StatusPartialContent || w.Body.String() != `hello"]}` {
+		t.Errorf("unexpected partial result %d %s", w.Code, w.Body.String())
+	}
+
+	// the job survives a restart of the server
+	if m, err = NewJobManager(folder); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, ok := m.Get(id); !ok || reloaded.State != JobFinished {
+		t.Errorf("expected the finished job to be reloaded, got %+v", reloaded)
+	}
+
+	// deleting a job that has ended removes it
+	HandleJobCancel(m, httptest.NewRecorder(), id)
+	if _, ok := m.Get(id); ok {
+		t.Error("expected the job to be deleted")
+	}
+	if _, err := os.Stat(filepath.Join(folder, id)); !os.IsNotExist(err) {
+		t.Error("expected the job's folder to be removed")
+	}
+
+	w = httptest.NewRecorder()
+	HandleJobStatus(m, w, id)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected a deleted job not to be found, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	HandleJobStart(m, w, httptest.NewRequest("POST", "/jobs?route=nowhere", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected an unknown route to be refused, got %d", w.Code)
+	}
+}
+
+func TestJobsCancel(t *testing.T) {
+	started := make(chan bool)
+	folder := filepath.Join(t.TempDir(), "jobs")
+	m, err := NewJobManager(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withJobRoute(m, "/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+
+	job, err := m.Start(httptest.NewRequest("POST", "/jobs?route=/slow", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	w := httptest.NewRecorder()
+	HandleJobResult(m, w, httptest.NewRequest("GET", "/jobs/"+job.ID+"/result", nil), job.ID)
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected no result from a running job, got %d", w.Code)
+	}
+
+	// a job left running when the server stops is failed when the server restarts
+	if reloaded, err := NewJobManager(folder); err != nil {
+		t.Fatal(err)
+	} else if j, _ := reloaded.Get(job.ID); j.State != JobFailed {
+		t.Errorf("expected an interrupted job to fail, got %+v", j)
+	}
+
+	HandleJobCancel(m, httptest.NewRecorder(), job.ID)
+	if ended := waitForJob(t, m, job.ID); ended.State != JobCanceled {
+		t.Errorf("expected the job to be canceled, got %+v", ended)
+	}
+}
+
+func TestJobsFailed(t *testing.T) {
+	m, err := NewJobManager(filepath.Join(t.TempDir(), "jobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	withJobRoute(m, "/broken", func(w http.ResponseWriter, r *http.Request) {
+		RespondWithError(w, http.StatusInternalServerError, os.ErrNotExist)
+	})
+	withJobRoute(m, "/panics", func(w http.ResponseWriter, r *http.Request) {
+		panic("oops")
+	})
+	withJobRoute(m, "/streams-error", func(w http.ResponseWriter, r *http.Request) {
+		// the route succeeds, but reports an error in its output
+		fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
+			modelChan <- &types.Receipt{BlockNumber: 100}
+			errorChan <- errors.New("could not fetch block 101")
+		}
+		_ = output.StreamMany(output.NewRenderContextFrom(r.Context()), fetchData, output.OutputOptions{Writer: w, Format: "csv"})
+	})
+
+	for _, route := range []string{"/broken", "/panics", "/streams-error"} {
+		job, err := m.Start(httptest.NewRequest("POST", "/jobs?route="+route, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ended := waitForJob(t, m, job.ID); ended.State != JobFailed || ended.Error == "" {
+			t.Errorf("expected %s to fail, got %+v", route, ended)
+		}
+	}
+}
+
+func TestJobsProgress(t *testing.T) {
+	reported, release := make(chan bool), make(chan bool)
+	m, err := NewJobManager(filepath.Join(t.TempDir(), "jobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	withJobRoute(m, "/reports", func(w http.ResponseWriter, r *http.Request) {
+		// a route's progress bars report to its writer (see GlobalOptions.ProgressReport)
+		w.(progress.Reporter).ReportProgress("0x1234 5/10 (50%)")
+		close(reported)
+		<-release
+		_, _ = w.Write([]byte("done"))
+	})
+
+	job, err := m.Start(httptest.NewRequest("POST", "/jobs?route=/reports", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-reported
+
+	found := false
+	for start := time.Now(); !found && time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		running, _ := m.Get(job.ID)
+		found = running.Progress != nil && running.Progress.Content == "0x1234 5/10 (50%)"
+	}
+	if !found {
+		t.Error("expected the route's progress to be reported")
+	}
+	close(release)
+	if ended := waitForJob(t, m, job.ID); ended.State != JobFinished {
+		t.Errorf("expected the job to finish, got %+v", ended)
+	}
+}
+
+func TestJobsRetention(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "jobs")
+	m, err := NewJobManager(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withJobRoute(m, "/quick", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("done"))
+	})
+
+	job, err := m.Start(httptest.NewRequest("POST", "/jobs?route=/quick", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = waitForJob(t, m, job.ID)
+
+	// a job that ended longer ago than the retention period is deleted when the next one starts
+	m.retention = 0
+	next, err := m.Start(httptest.NewRequest("POST", "/jobs?route=/quick", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Get(job.ID); ok {
+		t.Error("expected the old job to be deleted")
+	}
+	if _, err := os.Stat(filepath.Join(folder, job.ID)); !os.IsNotExist(err) {
+		t.Error("expected the old job's folder to be removed")
+	}
+	if _, ok := m.Get(next.ID); !ok {
+		t.Error("expected the new job to be kept")
+	}
+}
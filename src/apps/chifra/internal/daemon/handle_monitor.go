@@ -1,15 +1,59 @@
 package daemonPkg
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	monitorsPkg "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/monitors"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/notify"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
 
-// HandleMonitor starts and manages the monitor process
+// HandleMonitor runs the monitor watcher (as chifra monitors --watch would) until the daemon
+// stops. The monitors are freshened each time the scraper writes a chunk or updates the stage.
 func (opts *DaemonOptions) HandleMonitor(rCtx *output.RenderCtx) error {
-	// monitorOpts := monitorsPkg.GetMonitorsOptions([]string{}, &opts.Globals)
-	// monitorOpts.Watch:   true,
-	// monitorOpts.Sleep:   opts.Sleep,
-	// err, _ := monitorOpts.MonitorsInternal()
-	// return err
-	return nil
+	if !opts.Monitor {
+		return nil
+	}
+
+	// copy the options so as not to change the defaults used by the /monitors route
+	monitorOpts := *monitorsPkg.GetMonitorsOptions([]string{}, &opts.Globals)
+	monitorOpts.Watch = true
+	monitorOpts.Watchlist = opts.Watchlist
+	monitorOpts.Commands = opts.Commands
+	monitorOpts.Globals.Cache = true
+
+	watcher := monitorOpts.NewWatcher()
+	remove := notify.AddListener(func(msg notify.Message, _ *types.MetaData, _ any) {
+		if msg == notify.MessageChunkWritten || msg == notify.MessageStageUpdated {
+			watcher.Wake(string(msg))
+		}
+	})
+	defer remove()
+
+	return watcher.Run(rCtx.Ctx)
+}
+
+// HandleMonitorState pauses or resumes the monitor watcher and reports its status
+func HandleMonitorState(w http.ResponseWriter, pause bool) {
+	watcher := monitor.ActiveWatcher()
+	if watcher == nil {
+		RespondWithError(w, http.StatusNotFound, errors.New("the monitor watcher is not running (start the daemon with --monitor)"))
+		return
+	}
+
+	if pause {
+		watcher.Pause()
+	} else {
+		watcher.Resume()
+	}
+
+	type MonitorStateResponse struct {
+		Data []types.WatcherStatus `json:"data"`
+	}
+	marshalled, _ := json.MarshalIndent(MonitorStateResponse{Data: []types.WatcherStatus{watcher.Status()}}, "", "  ")
+	_, _ = w.Write(marshalled)
 }
@@ -0,0 +1,197 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package daemonPkg
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+)
+
+// openApiJson is the OpenAPI document describing the server's routes. It is generated by goMaker
+// from the same definitions as each route's options.
+//
+//go:embed openapi.json
+var openApiJson []byte
+
+// apiSchema is the part of a parameter's schema used to validate its values
+type apiSchema struct {
+	Type  string     `json:"type"`
+	Enum  []string   `json:"enum"`
+	Items *apiSchema `json:"items"`
+}
+
+type apiParameter struct {
+	Name   string    `json:"name"`
+	Schema apiSchema `json:"schema"`
+}
+
+// apiParameters holds the parameters of each command route described in the OpenAPI document,
+// by path. The document also describes the server's own endpoints (jobs, the websocket, and so
+// on), whose requests are not validated against it.
+var apiParameters = map[string]map[string]apiParameter{}
+
+// unlistedParameters are accepted by every route (see caps.HasKey) but are not described in the
+// OpenAPI document because they are not meant for clients: testRunner marks the requests of the
+// test harness
+var unlistedParameters = map[string]bool{
+	"testRunner": true,
+}
+
+func init() {
+	var doc struct {
+		Paths map[string]struct {
+			Get *struct {
+				Parameters []apiParameter `json:"parameters"`
+			} `json:"get"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(openApiJson, &doc); err != nil {
+		logger.Fatal("the embedded OpenAPI document is invalid:", err)
+	}
+	for _, route := range routes {
+		item, ok := doc.Paths[route.Pattern]
+		if !ok || item.Get == nil || route.Method != http.MethodGet || !strings.HasPrefix(route.Name, "Route") {
+			continue
+		}
+		apiParameters[route.Pattern] = make(map[string]apiParameter)
+		for _, param := range item.Get.Parameters {
+			apiParameters[route.Pattern][param.Name] = param
+		}
+	}
+}
+
+// HandleOpenApi responds with the OpenAPI document
+func HandleOpenApi(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openApiJson)
+}
+
+// InvalidParameter describes a query parameter rejected by ValidateHandler
+type InvalidParameter struct {
+	Parameter string `json:"parameter"`
+	Value     string `json:"value,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// ValidateHandler checks the query parameters of requests to the routes described in the OpenAPI
+// document, responding with the problems found (if any) rather than passing the request on
+func ValidateHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if invalid := validateRequest(r); len(invalid) > 0 {
+			respondWithInvalid(w, invalid)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validateRequest returns the request's query parameters that are not parameters of its route or
+// whose values do not match the parameter's schema
+func validateRequest(r *http.Request) []InvalidParameter {
+	params, ok := apiParameters[r.URL.Path]
+	if !ok {
+		return nil
+	}
+
+	invalid := []InvalidParameter{}
+	for name, values := range r.URL.Query() {
+		if unlistedParameters[name] {
+			continue
+		}
+		param, ok := params[name]
+		if !ok {
+			invalid = append(invalid, InvalidParameter{
+				Parameter: name,
+				Reason:    fmt.Sprintf("%s is not a parameter of the %s route", name, r.URL.Path),
+			})
+			continue
+		}
+		for _, value := range values {
+			if reason := param.Schema.check(value); reason != "" {
+				invalid = append(invalid, InvalidParameter{Parameter: name, Value: value, Reason: reason})
+			}
+		}
+	}
+
+	sort.Slice(invalid, func(i, j int) bool {
+		if invalid[i].Parameter != invalid[j].Parameter {
+			return invalid[i].Parameter < invalid[j].Parameter
+		}
+		return invalid[i].Value < invalid[j].Value
+	})
+	return invalid
+}
+
+// check returns the reason the value does not match the schema, if it does not. The values are
+// parsed as the routes parse them (for example, the items of an array are separated by spaces).
+func (s *apiSchema) check(value string) string {
+	switch s.Type {
+	case "boolean":
+		if value != "" && value != "true" && value != "false" {
+			return "the value must be true or false (or empty)"
+		}
+	case "integer":
+		if _, err := strconv.ParseUint(value, 0, 64); err != nil {
+			return "the value must be a non-negative integer"
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "the value must be a number"
+		}
+	case "array":
+		if s.Items != nil {
+			for _, item := range strings.Split(value, " ") {
+				if reason := s.Items.check(item); reason != "" {
+					return reason
+				}
+			}
+		}
+	}
+
+	if len(s.Enum) > 0 && value != "" {
+		for _, e := range s.Enum {
+			if value == e {
+				return ""
+			}
+		}
+		return "the value must be one of [ " + strings.Join(s.Enum, " | ") + " ]"
+	}
+	return ""
+}
+
+// invalidParametersError is returned when a request has invalid parameters
+type invalidParametersError struct {
+	invalid []InvalidParameter
+}
+
+func (e *invalidParametersError) Error() string {
+	return fmt.Sprintf("the request has %d invalid parameters", len(e.invalid))
+}
+
+// respondWithInvalid responds with a 400 error listing the invalid parameters
+func respondWithInvalid(w http.ResponseWriter, invalid []InvalidParameter) {
+	type InvalidResponse struct {
+		Errors  []string           `json:"errors"`
+		Invalid []InvalidParameter `json:"invalid"`
+	}
+	response := InvalidResponse{Invalid: invalid}
+	for _, param := range invalid {
+		if param.Value != "" {
+			response.Errors = append(response.Errors, fmt.Sprintf("%s (%s): %s", param.Parameter, param.Value, param.Reason))
+		} else {
+			response.Errors = append(response.Errors, fmt.Sprintf("%s: %s", param.Parameter, param.Reason))
+		}
+	}
+	marshalled, _ := json.MarshalIndent(response, "", "  ")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write(marshalled)
+}
@@ -0,0 +1,136 @@
+package daemonPkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenApi(t *testing.T) {
+	w := httptest.NewRecorder()
+	HandleOpenApi(w)
+
+	var doc struct {
+		OpenApi string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenApi == "" || len(doc.Paths) == 0 {
+		t.Fatal("the OpenAPI document describes no routes")
+	}
+
+	// every route described by the document is served and every route served is described
+	served := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		served[strings.ToLower(route.Method)+" "+route.Pattern] = true
+	}
+	described := make(map[string]bool, len(served))
+	for path, item := range doc.Paths {
+		for method := range item {
+			described[method+" "+path] = true
+			if !served[method+" "+path] {
+				t.Errorf("%s %s is described by the OpenAPI document but is not a route", method, path)
+			}
+		}
+	}
+	for _, route := range routes {
+		key := strings.ToLower(route.Method) + " " + route.Pattern
+		if !described[key] && !undescribedRoutes[key] {
+			t.Errorf("%s is a route but is not described by the OpenAPI document", key)
+		}
+	}
+
+	// every command route is validated against the document (and may be run as a job)
+	for path := range jobRoutes {
+		if _, ok := apiParameters[path]; !ok {
+			t.Errorf("%s is not validated", path)
+		}
+	}
+}
+
+// undescribedRoutes are the routes left out of the OpenAPI document: the index redirects to the
+// documentation and the names and monitors routes other than GET are those of the command line's
+// editing options
+var undescribedRoutes = map[string]bool{
+	"get /":            true,
+	"delete /monitors": true,
+	"post /names":      true,
+	"put /names":       true,
+	"delete /names":    true,
+}
+
+func TestValidateRequest(t *testing.T) {
+	tests := []struct {
+		target  string
+		invalid []string
+	}{
+		{"/export?addrs=0x1%200x2&accounting&maxRecords=10&fmt=csv", nil},
+		{"/export?addrs=0x1&costBasis=fifo&noZero=true&fields=hash", nil},
+		{"/export?addrs=0x1&intra=", nil},
+		{"/export?addrs=0x1&testRunner=true", nil},
+		{"/export?addrs=0x1&notAnOption", []string{"notAnOption"}},
+		{"/export?addrs=0x1&costBasis=lilo", []string{"costBasis"}},
+		{"/export?addrs=0x1&maxRecords=ten&firstBlock=-1", []string{"firstBlock", "maxRecords"}},
+		{"/export?addrs=0x1&cache=yes", []string{"cache"}},
+		{"/status?modes=index%20blocks", nil},
+		{"/status?modes=index%20nothing", []string{"modes"}},
+		{"/chunks?mode=manifest&fmt=xml", []string{"fmt"}},
+		{"/init?fields=hash", []string{"fields"}},
+		{"/websocket?anything", nil},
+	}
+
+	for _, test := range tests {
+		invalid := validateRequest(httptest.NewRequest("GET", test.target, nil))
+		names := []string{}
+		for _, param := range invalid {
+			names = append(names, param.Parameter)
+		}
+		if len(names) != len(test.invalid) {
+			t.Errorf("%s: got %v, expected %v", test.target, names, test.invalid)
+			continue
+		}
+		for i := range names {
+			if names[i] != test.invalid[i] {
+				t.Errorf("%s: got %v, expected %v", test.target, names, test.invalid)
+			}
+		}
+	}
+}
+
+func TestValidateHandler(t *testing.T) {
+	handler := ValidateHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/export?addrs=0x1&period=weekly", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected a bad request, got %d", w.Code)
+	}
+
+	var response struct {
+		Errors  []string           `json:"errors"`
+		Invalid []InvalidParameter `json:"invalid"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Errors) != 1 || len(response.Invalid) != 1 ||
+		response.Invalid[0].Parameter != "period" || response.Invalid[0].Value != "weekly" {
+		t.Errorf("unexpected response %s", w.Body.String())
+	}
+
+	// a job's parameters are checked against the route it runs
+	m, err := NewJobManager(filepath.Join(t.TempDir(), "jobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	HandleJobStart(m, w, httptest.NewRequest("POST", "/jobs?route=export&addrs=0x1&period=weekly", nil))
+	if w.Code != http.StatusBadRequest || len(m.jobs) != 0 {
+		t.Errorf("expected the job to be refused, got %d: %s", w.Code, w.Body.String())
+	}
+}
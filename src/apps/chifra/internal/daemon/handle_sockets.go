@@ -5,11 +5,17 @@
 package daemonPkg
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"sync"
 
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/notify"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/gorilla/websocket"
 )
 
@@ -23,6 +29,26 @@ const (
 	CommandOutputMessage MessageType = "output"
 	// ProgressMessage is a message carried on the stderr stream
 	ProgressMessage MessageType = "progress"
+	// SubscribeMessage is sent by a client to subscribe to the appearances of a set of addresses
+	SubscribeMessage MessageType = "subscribe"
+	// UnsubscribeMessage is sent by a client to end a subscription
+	UnsubscribeMessage MessageType = "unsubscribe"
+	// ResumeMessage is sent by a client to replay a subscription's appearances from a given block
+	ResumeMessage MessageType = "resume"
+	// SubscribedMessage confirms a subscription and carries its addresses
+	SubscribedMessage MessageType = "subscribed"
+	// UnsubscribedMessage confirms the end of a subscription
+	UnsubscribedMessage MessageType = "unsubscribed"
+	// AppearanceMessage carries an appearance of one of a subscription's addresses
+	AppearanceMessage MessageType = "appearance"
+	// TransactionMessage carries the articulated transaction of an appearance
+	TransactionMessage MessageType = "transaction"
+	// LogMessage carries an articulated log of an appearance
+	LogMessage MessageType = "log"
+	// StatementMessage carries a statement of an appearance
+	StatementMessage MessageType = "statement"
+	// ReorgMessage reports a chain reorganization detected by the scraper
+	ReorgMessage MessageType = "reorg"
 )
 
 var upgrader = websocket.Upgrader{}
@@ -32,13 +58,18 @@ type Message struct {
 	Action  MessageType `json:"action"`
 	ID      string      `json:"id"`
 	Content string      `json:"content"`
+	Payload any         `json:"payload,omitempty"`
 }
 
 // Connection is a structure representing a websocket connection
 type Connection struct {
-	connection *websocket.Conn
-	pool       *ConnectionPool
-	send       chan *Message
+	connection    *websocket.Conn
+	pool          *ConnectionPool
+	send          chan *Message
+	jobs          chan *subscriptionJob
+	done          chan struct{}
+	mutex         sync.Mutex
+	subscriptions map[string]*Subscription
 }
 
 // write the message to the connection
@@ -51,12 +82,11 @@ func (c *Connection) write() {
 	//nolint:staticcheck,gosimple
 	for {
 		select {
-		case message, ok := <-c.send:
-			if !ok {
-				c.Log("Connection closed")
-				_ = c.connection.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
+		case <-c.done:
+			c.Log("Connection closed")
+			_ = c.connection.WriteMessage(websocket.CloseMessage, []byte{})
+			return
+		case message := <-c.send:
 
 			err := c.connection.WriteJSON(message)
 			if err != nil {
@@ -67,6 +97,31 @@ func (c *Connection) write() {
 	}
 }
 
+// read the client's requests from the connection until it closes
+func (c *Connection) read() {
+	for {
+		var request Request
+		if err := c.connection.ReadJSON(&request); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				c.queue(&Message{Action: CommandErrorMessage, Content: err.Error()})
+				continue
+			}
+			c.pool.unregister <- c
+			return
+		}
+		c.handleRequest(&request)
+	}
+}
+
+// queue the message for writing unless the connection has been closed
+func (c *Connection) queue(message *Message) {
+	select {
+	case c.send <- message:
+	case <-c.done:
+	}
+}
+
 // RemoteAddr is the other end of the connection
 func (c *Connection) RemoteAddr() net.Addr {
 	return c.connection.RemoteAddr()
@@ -81,25 +136,31 @@ func (c *Connection) Log(s string, args ...interface{}) {
 
 // ConnectionPool is the collection of all connections
 type ConnectionPool struct {
-	connections map[*Connection]bool
-	broadcast   chan *Message
-	register    chan *Connection
-	unregister  chan *Connection
+	connections   map[*Connection]bool
+	broadcast     chan *Message
+	register      chan *Connection
+	unregister    chan *Connection
+	notifications chan *subscriptionJob
+	missed        *base.Blknum
+	missedMutex   sync.Mutex
+	chain         string
+	conn          *rpc.Connection
 }
 
 // closeAndDelete cleans up a connection
 func closeAndDelete(pool *ConnectionPool, connection *Connection) {
 	delete(pool.connections, connection)
-	close(connection.send)
+	close(connection.done)
 }
 
 // newConnectionPool returns a new connection structure
 func newConnectionPool() *ConnectionPool {
 	return &ConnectionPool{
-		connections: make(map[*Connection]bool),
-		broadcast:   make(chan *Message),
-		register:    make(chan *Connection),
-		unregister:  make(chan *Connection),
+		connections:   make(map[*Connection]bool),
+		broadcast:     make(chan *Message),
+		register:      make(chan *Connection),
+		unregister:    make(chan *Connection),
+		notifications: make(chan *subscriptionJob, 256),
 	}
 }
 
@@ -121,7 +182,21 @@ func (pool *ConnectionPool) run() {
 		// handle a signal to broadcast a message
 		case message := <-pool.broadcast:
 			for connection := range pool.connections {
-				connection.send <- message
+				connection.queue(message)
+			}
+		// handle a notification from the scraper for the subscribers
+		case job := <-pool.notifications:
+			if bn, ok := pool.takeMissed(); ok {
+				msg := &Message{Action: CommandErrorMessage, Content: fmt.Sprintf("notifications were dropped, resume from block %d", bn)}
+				for connection := range pool.connections {
+					select {
+					case connection.send <- msg:
+					default:
+					}
+				}
+			}
+			for connection := range pool.connections {
+				connection.schedule(job)
 			}
 		}
 	}
@@ -139,15 +214,29 @@ func HandleWebsockets(pool *ConnectionPool, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	connection := &Connection{connection: c, send: make(chan *Message), pool: pool}
+	connection := &Connection{
+		connection:    c,
+		send:          make(chan *Message, 256),
+		jobs:          make(chan *subscriptionJob, maxPendingJobs),
+		done:          make(chan struct{}),
+		pool:          pool,
+		subscriptions: make(map[string]*Subscription),
+	}
 	pool.register <- connection
 
 	go connection.write()
+	go connection.read()
+	go connection.work()
 }
 
 var connectionPool = newConnectionPool()
 
-// RunWebsocketPool runs the websocket pool
-func RunWebsocketPool() {
+// RunWebsocketPool runs the websocket pool. Subscribers are sent the appearances reported by a
+// scraper running in this process and, if they ask for them, the appearances' details from the
+// RPC provider.
+func RunWebsocketPool(chain string, conn *rpc.Connection) {
+	connectionPool.chain = chain
+	connectionPool.conn = conn
+	notify.AddListener(connectionPool.listen)
 	go connectionPool.run()
 }
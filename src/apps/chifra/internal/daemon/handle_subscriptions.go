@@ -0,0 +1,491 @@
+package daemonPkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/articulate"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/ledger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/names"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/notify"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// maxPendingJobs is the number of notifications a connection may fall behind before the
+// appearances are dropped and the client is asked to resume
+const maxPendingJobs = 4096
+
+// maxSentAppearances is the number of appearances a subscription remembers having sent. Older
+// appearances are forgotten first.
+const maxSentAppearances = 65536
+
+// Request is a message sent by a websocket client. Subscribe requests carry the addresses (or a
+// names tag) to watch, the details to send with each appearance (transaction, logs or
+// statements), and optionally a block from which to replay appearances already in the index.
+// Unsubscribe and resume requests name an earlier subscription by its ID.
+type Request struct {
+	Action    MessageType  `json:"action"`
+	ID        string       `json:"id"`
+	Addresses []string     `json:"addresses,omitempty"`
+	Tag       string       `json:"tag,omitempty"`
+	Details   string       `json:"details,omitempty"`
+	FromBlock *base.Blknum `json:"fromBlock,omitempty"`
+}
+
+// Subscription is a client's interest in the appearances of a set of addresses
+type Subscription struct {
+	ID        string
+	Addresses map[base.Address]bool
+	Details   string
+	// sent holds the appearances already sent to the client (and sentOrder the order in which
+	// they were sent), so an appearance delivered by a replay is not sent again when the scraper
+	// reports it (and vice versa). The scraper reports appearances out of order, so each one is
+	// remembered rather than the latest of each address.
+	sent      map[sentAppearance]bool
+	sentOrder []sentAppearance
+}
+
+// sentAppearance identifies an appearance sent to a client
+type sentAppearance struct {
+	address base.Address
+	bn      base.Blknum
+	txid    base.Txnum
+}
+
+func sentAppearanceOf(app *types.Appearance) sentAppearance {
+	return sentAppearance{app.Address, base.Blknum(app.BlockNumber), base.Txnum(app.TransactionIndex)}
+}
+
+// subscriptionJob is a unit of work for a connection's worker: either appearances reported by
+// the scraper, a reorg, or the replay of a subscription from a block
+type subscriptionJob struct {
+	appearances []types.Appearance
+	reorg       *notify.NotificationPayloadReorg
+	replay      *Subscription
+	fromBlock   base.Blknum
+}
+
+// validDetails are the details a subscription may ask for with each appearance
+var validDetails = map[string]bool{
+	"":            true,
+	"transaction": true,
+	"logs":        true,
+	"statements":  true,
+}
+
+// handleRequest handles a request from the client
+func (c *Connection) handleRequest(request *Request) {
+	fail := func(err error) {
+		c.queue(&Message{Action: CommandErrorMessage, ID: request.ID, Content: err.Error()})
+	}
+
+	if request.ID == "" {
+		fail(errors.New("the request must have an id"))
+		return
+	}
+
+	switch request.Action {
+	case SubscribeMessage:
+		sub, err := c.pool.newSubscription(request)
+		if err != nil {
+			fail(err)
+			return
+		}
+		c.mutex.Lock()
+		if _, ok := c.subscriptions[sub.ID]; ok {
+			c.mutex.Unlock()
+			fail(fmt.Errorf("subscription %s already exists", sub.ID))
+			return
+		}
+		c.subscriptions[sub.ID] = sub
+		c.mutex.Unlock()
+
+		addrs := make([]string, 0, len(sub.Addresses))
+		for addr := range sub.Addresses {
+			addrs = append(addrs, addr.Hex())
+		}
+		c.queue(&Message{Action: SubscribedMessage, ID: sub.ID, Payload: addrs})
+		if request.FromBlock != nil {
+			c.schedule(&subscriptionJob{replay: sub, fromBlock: *request.FromBlock})
+		}
+
+	case UnsubscribeMessage:
+		c.mutex.Lock()
+		_, ok := c.subscriptions[request.ID]
+		delete(c.subscriptions, request.ID)
+		c.mutex.Unlock()
+		if !ok {
+			fail(fmt.Errorf("subscription %s not found", request.ID))
+			return
+		}
+		c.queue(&Message{Action: UnsubscribedMessage, ID: request.ID})
+
+	case ResumeMessage:
+		c.mutex.Lock()
+		sub, ok := c.subscriptions[request.ID]
+		c.mutex.Unlock()
+		if !ok {
+			fail(fmt.Errorf("subscription %s not found", request.ID))
+			return
+		} else if request.FromBlock == nil {
+			fail(errors.New("resume requires a fromBlock"))
+			return
+		}
+		c.schedule(&subscriptionJob{replay: sub, fromBlock: *request.FromBlock})
+
+	default:
+		fail(fmt.Errorf("unknown action %q", request.Action))
+	}
+}
+
+// newSubscription validates a subscribe request and resolves its addresses
+func (pool *ConnectionPool) newSubscription(request *Request) (*Subscription, error) {
+	details := strings.ToLower(request.Details)
+	if !validDetails[details] {
+		return nil, fmt.Errorf("details must be one of transaction, logs, or statements")
+	}
+
+	sub := &Subscription{
+		ID:        request.ID,
+		Addresses: make(map[base.Address]bool),
+		Details:   details,
+		sent:      make(map[sentAppearance]bool),
+	}
+	for _, addr := range request.Addresses {
+		if !base.IsValidAddress(addr) {
+			return nil, fmt.Errorf("invalid address %s", addr)
+		}
+		sub.Addresses[base.HexToAddress(addr)] = true
+	}
+	if request.Tag != "" {
+		tagged, err := names.LoadTaggedAddresses(pool.chain, request.Tag)
+		if err != nil {
+			return nil, err
+		}
+		for addr := range tagged {
+			sub.Addresses[addr] = true
+		}
+	}
+	if len(sub.Addresses) == 0 {
+		return nil, errors.New("a subscription requires at least one address or a tag")
+	}
+	return sub, nil
+}
+
+// schedule queues the job for the connection's worker. If the client has fallen too far behind,
+// the appearances are dropped and the client is told from which block to resume.
+func (c *Connection) schedule(job *subscriptionJob) {
+	select {
+	case <-c.done:
+	case c.jobs <- job:
+	default:
+		if len(job.appearances) > 0 {
+			msg := fmt.Sprintf("too many pending appearances, resume from block %d", job.appearances[0].BlockNumber)
+			select {
+			case c.send <- &Message{Action: CommandErrorMessage, Content: msg}:
+			default:
+			}
+		}
+	}
+}
+
+// work processes the connection's jobs in order until the connection closes
+func (c *Connection) work() {
+	var abiCache *articulate.AbiCache
+	for {
+		var job *subscriptionJob
+		select {
+		case <-c.done:
+			return
+		case job = <-c.jobs:
+		}
+		if abiCache == nil && c.pool.conn != nil {
+			abiCache = articulate.NewAbiCache(c.pool.conn, true)
+		}
+		switch {
+		case job.reorg != nil:
+			c.handleReorg(job.reorg)
+		case job.replay != nil:
+			if err := c.replay(abiCache, job.replay, job.fromBlock); err != nil {
+				c.queue(&Message{Action: CommandErrorMessage, ID: job.replay.ID, Content: err.Error()})
+			}
+		default:
+			for _, sub := range c.activeSubscriptions() {
+				for _, app := range job.appearances {
+					c.deliver(abiCache, sub, app)
+				}
+			}
+		}
+	}
+}
+
+// activeSubscriptions returns the connection's current subscriptions
+func (c *Connection) activeSubscriptions() []*Subscription {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	subs := make([]*Subscription, 0, len(c.subscriptions))
+	for _, sub := range c.subscriptions {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// isActive returns true if the subscription has not been ended by the client
+func (c *Connection) isActive(sub *Subscription) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.subscriptions[sub.ID] == sub
+}
+
+// matches returns true if the appearance is of one of the subscription's addresses and has not
+// already been sent to the client
+func (sub *Subscription) matches(app *types.Appearance) bool {
+	return sub.Addresses[app.Address] && !sub.sent[sentAppearanceOf(app)]
+}
+
+// markSent remembers that the appearance was sent, forgetting the oldest appearance if the
+// subscription remembers too many
+func (sub *Subscription) markSent(app *types.Appearance) {
+	key := sentAppearanceOf(app)
+	sub.sent[key] = true
+	sub.sentOrder = append(sub.sentOrder, key)
+	if len(sub.sentOrder) > maxSentAppearances {
+		delete(sub.sent, sub.sentOrder[0])
+		sub.sentOrder = sub.sentOrder[1:]
+	}
+}
+
+// forgetFrom forgets the appearances sent at or after the block so that they are sent again
+func (sub *Subscription) forgetFrom(bn base.Blknum) {
+	kept := sub.sentOrder[:0]
+	for _, key := range sub.sentOrder {
+		if key.bn >= bn {
+			delete(sub.sent, key)
+		} else {
+			kept = append(kept, key)
+		}
+	}
+	sub.sentOrder = kept
+}
+
+// deliver sends the appearance, and the details the subscription asked for, to the client
+func (c *Connection) deliver(abiCache *articulate.AbiCache, sub *Subscription, app types.Appearance) {
+	if !sub.matches(&app) {
+		return
+	}
+	sub.markSent(&app)
+
+	chain := c.pool.chain
+	c.queue(&Message{Action: AppearanceMessage, ID: sub.ID, Payload: app.Model(chain, "json", false, nil).Data})
+	if sub.Details == "" || c.pool.conn == nil {
+		return
+	}
+
+	conn := c.pool.conn
+	tx, err := conn.GetTransactionByAppearance(&app, false)
+	if err != nil {
+		c.queue(&Message{Action: CommandErrorMessage, ID: sub.ID, Content: err.Error()})
+		return
+	}
+
+	extra := map[string]any{"articulate": true}
+	switch sub.Details {
+	case "transaction":
+		_ = abiCache.ArticulateTransaction(tx)
+		c.queue(&Message{Action: TransactionMessage, ID: sub.ID, Payload: tx.Model(chain, "json", false, extra).Data})
+
+	case "logs":
+		if tx.Receipt == nil {
+			return
+		}
+		filt := filter.NewEmptyFilter()
+		for _, log := range tx.Receipt.Logs {
+			if filt.ApplyLogFilter(&log, []base.Address{app.Address}) {
+				_ = abiCache.ArticulateLog(&log)
+				c.queue(&Message{Action: LogMessage, ID: sub.ID, Payload: log.Model(chain, "json", false, extra).Data})
+			}
+		}
+
+	case "statements":
+		bn := base.Blknum(app.BlockNumber)
+		l := ledger.NewLedger(conn, app.Address, 0, base.NOPOSN, false, false, false, false, false, nil)
+		_ = l.SetContexts(chain, []types.Appearance{app})
+		statements, err := l.GetStatements(conn, filter.NewEmptyFilter(), tx)
+		if err != nil {
+			c.queue(&Message{Action: CommandErrorMessage, ID: sub.ID, Content: fmt.Sprintf("statements at block %d: %v", bn, err)})
+			return
+		}
+		for _, statement := range statements {
+			c.queue(&Message{Action: StatementMessage, ID: sub.ID, Payload: statement.Model(chain, "json", false, nil).Data})
+		}
+	}
+}
+
+// replay sends the subscription's appearances already in the index at or after the block
+func (c *Connection) replay(abiCache *articulate.AbiCache, sub *Subscription, fromBlock base.Blknum) error {
+	addrs := make([]string, 0, len(sub.Addresses))
+	for addr := range sub.Addresses {
+		addrs = append(addrs, addr.Hex())
+	}
+	// everything from the block on is sent again, even if it was sent before
+	sub.forgetFrom(fromBlock)
+
+	var monitorArray []monitor.Monitor
+	updater := monitor.NewUpdater(c.pool.chain, false, false /* skipFreshen */, addrs)
+	if _, err := updater.FreshenMonitors(&monitorArray); err != nil {
+		return err
+	}
+
+	for _, mon := range monitorArray {
+		filt := filter.NewFilter(
+			false,
+			false,
+			[]string{},
+			base.BlockRange{First: fromBlock, Last: base.NOPOSN},
+			base.RecordRange{First: 0, Last: base.NOPOS},
+		)
+		apps, _, err := mon.ReadAndFilterAppearances(filt, false /* withCount */)
+		if err != nil {
+			return err
+		}
+		for _, app := range apps {
+			if !c.isActive(sub) {
+				return nil
+			}
+			c.deliver(abiCache, sub, app)
+		}
+	}
+	return nil
+}
+
+// handleReorg tells the client of the reorg and forgets the appearances sent from the reorged
+// block on, so that they are sent again once the scraper re-indexes them
+func (c *Connection) handleReorg(reorg *notify.NotificationPayloadReorg) {
+	bn := base.MustParseBlknum(reorg.BlockNumber)
+	for _, sub := range c.activeSubscriptions() {
+		sub.forgetFrom(bn)
+	}
+	c.queue(&Message{Action: ReorgMessage, Payload: reorg})
+}
+
+// listen receives the scraper's notifications and passes them to the connections. It is called
+// by notify.Publish (on the scraper's goroutine), so it never blocks: if the pool has fallen too
+// far behind, the notification is dropped and the clients are told from which block to resume.
+func (pool *ConnectionPool) listen(msg notify.Message, _ *types.MetaData, payload any) {
+	job := newSubscriptionJob(msg, payload)
+	if job == nil {
+		return
+	}
+	select {
+	case pool.notifications <- job:
+	default:
+		pool.miss(job.firstBlock())
+	}
+}
+
+// miss remembers the earliest block of the notifications dropped by listen
+func (pool *ConnectionPool) miss(bn base.Blknum) {
+	pool.missedMutex.Lock()
+	defer pool.missedMutex.Unlock()
+	if pool.missed == nil || bn < *pool.missed {
+		pool.missed = &bn
+	}
+}
+
+// takeMissed returns (and forgets) the earliest block of the notifications dropped by listen
+func (pool *ConnectionPool) takeMissed() (base.Blknum, bool) {
+	pool.missedMutex.Lock()
+	defer pool.missedMutex.Unlock()
+	if pool.missed == nil {
+		return 0, false
+	}
+	bn := *pool.missed
+	pool.missed = nil
+	return bn, true
+}
+
+// firstBlock returns the earliest block the job reports
+func (job *subscriptionJob) firstBlock() base.Blknum {
+	if job.reorg != nil {
+		return base.MustParseBlknum(job.reorg.BlockNumber)
+	}
+	bn := base.NOPOSN
+	for _, app := range job.appearances {
+		bn = min(bn, base.Blknum(app.BlockNumber))
+	}
+	return bn
+}
+
+// newSubscriptionJob converts a notification into a job for the connections (or nil if the
+// notification is of no interest to subscribers)
+func newSubscriptionJob(msg notify.Message, payload any) *subscriptionJob {
+	switch p := payload.(type) {
+	case []notify.NotificationPayloadAppearance:
+		if msg != notify.MessageAppearance || len(p) == 0 {
+			return nil
+		}
+		apps := make([]types.Appearance, 0, len(p))
+		for _, item := range p {
+			apps = append(apps, types.Appearance{
+				Address:          base.HexToAddress(item.Address),
+				BlockNumber:      uint32(base.MustParseBlknum(item.BlockNumber)),
+				TransactionIndex: item.TransactionIndex,
+			})
+		}
+		return &subscriptionJob{appearances: apps}
+
+	case notify.NotificationPayloadReorg:
+		if msg != notify.MessageReorg {
+			return nil
+		}
+		return &subscriptionJob{reorg: &p}
+	}
+	return nil
+}
+
+// HandleNotify receives the notifications of a scraper running in another process (started with
+// chifra scrape --notify and the notify url set to this endpoint) and passes them to the listeners
+// in this process, such as websocket subscribers and the monitor watcher
+func HandleNotify(w http.ResponseWriter, r *http.Request) {
+	var notification struct {
+		Msg     notify.Message  `json:"msg"`
+		Meta    *types.MetaData `json:"meta"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&notification); err != nil {
+		RespondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var err error
+	switch notification.Msg {
+	case notify.MessageAppearance:
+		err = publishNotification[[]notify.NotificationPayloadAppearance](notification.Msg, notification.Meta, notification.Payload)
+	case notify.MessageReorg:
+		err = publishNotification[notify.NotificationPayloadReorg](notification.Msg, notification.Meta, notification.Payload)
+	case notify.MessageChunkWritten:
+		err = publishNotification[[]notify.NotificationPayloadChunkWritten](notification.Msg, notification.Meta, notification.Payload)
+	case notify.MessageStageUpdated:
+		err = publishNotification[string](notification.Msg, notification.Meta, notification.Payload)
+	}
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func publishNotification[T notify.NotificationPayload](msg notify.Message, meta *types.MetaData, raw json.RawMessage) error {
+	var payload T
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return err
+	}
+	notify.Publish(notify.Notification[T]{Msg: msg, Meta: meta, Payload: payload})
+	return nil
+}
@@ -0,0 +1,167 @@
+package daemonPkg
+
+import (
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/notify"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+const subscribedAddr = "0xf503017d7baf7fbc0fff7492b751025c6a78179b"
+
+func newTestConnection() *Connection {
+	return &Connection{
+		pool:          &ConnectionPool{chain: "mainnet"},
+		send:          make(chan *Message, 64),
+		jobs:          make(chan *subscriptionJob, 64),
+		done:          make(chan struct{}),
+		subscriptions: make(map[string]*Subscription),
+	}
+}
+
+func nextMessage(t *testing.T, c *Connection) *Message {
+	t.Helper()
+	select {
+	case msg := <-c.send:
+		return msg
+	default:
+		t.Fatal("expected a message")
+		return nil
+	}
+}
+
+func TestSubscriptionRequests(t *testing.T) {
+	c := newTestConnection()
+
+	bad := []Request{
+		{Action: SubscribeMessage},
+		{Action: SubscribeMessage, ID: "a"},
+		{Action: SubscribeMessage, ID: "a", Addresses: []string{"0x1234"}},
+		{Action: SubscribeMessage, ID: "a", Addresses: []string{subscribedAddr}, Details: "blocks"},
+		{Action: UnsubscribeMessage, ID: "missing"},
+		{Action: ResumeMessage, ID: "missing"},
+		{Action: "dance", ID: "a"},
+	}
+	for _, request := range bad {
+		c.handleRequest(&request)
+		if msg := nextMessage(t, c); msg.Action != CommandErrorMessage {
+			t.Errorf("%+v: expected an error, got %+v", request, msg)
+		}
+	}
+
+	fromBlock := base.Blknum(100)
+	c.handleRequest(&Request{Action: SubscribeMessage, ID: "a", Addresses: []string{subscribedAddr}, Details: "Logs", FromBlock: &fromBlock})
+	if msg := nextMessage(t, c); msg.Action != SubscribedMessage || msg.ID != "a" {
+		t.Errorf("expected a subscription, got %+v", msg)
+	}
+	if sub := c.subscriptions["a"]; sub == nil || sub.Details != "logs" || !sub.Addresses[base.HexToAddress(subscribedAddr)] {
+		t.Errorf("unexpected subscription %+v", sub)
+	}
+	if job := <-c.jobs; job.replay != c.subscriptions["a"] || job.fromBlock != 100 {
+		t.Errorf("expected a replay from block 100, got %+v", job)
+	}
+
+	c.handleRequest(&Request{Action: SubscribeMessage, ID: "a", Addresses: []string{subscribedAddr}})
+	if msg := nextMessage(t, c); msg.Action != CommandErrorMessage {
+		t.Errorf("expected a duplicate subscription to fail, got %+v", msg)
+	}
+
+	c.handleRequest(&Request{Action: ResumeMessage, ID: "a"})
+	if msg := nextMessage(t, c); msg.Action != CommandErrorMessage {
+		t.Errorf("expected resume without a block to fail, got %+v", msg)
+	}
+
+	c.handleRequest(&Request{Action: UnsubscribeMessage, ID: "a"})
+	if msg := nextMessage(t, c); msg.Action != UnsubscribedMessage || len(c.subscriptions) != 0 {
+		t.Errorf("expected the subscription to end, got %+v", msg)
+	}
+}
+
+func TestSubscriptionDelivery(t *testing.T) {
+	c := newTestConnection()
+	c.handleRequest(&Request{Action: SubscribeMessage, ID: "a", Addresses: []string{subscribedAddr}})
+	_ = nextMessage(t, c)
+	sub := c.subscriptions["a"]
+
+	addr := base.HexToAddress(subscribedAddr)
+	other := base.HexToAddress("0x1234")
+	apps := []types.Appearance{
+		{Address: addr, BlockNumber: 100, TransactionIndex: 2},
+		{Address: other, BlockNumber: 100, TransactionIndex: 3},
+		{Address: addr, BlockNumber: 101, TransactionIndex: 0},
+		{Address: addr, BlockNumber: 100, TransactionIndex: 2}, // already sent
+		{Address: addr, BlockNumber: 100, TransactionIndex: 1}, // reported late
+	}
+	for _, app := range apps {
+		c.deliver(nil, sub, app)
+	}
+	if len(c.send) != 3 {
+		t.Fatalf("expected three appearances, got %d messages", len(c.send))
+	}
+	if msg := nextMessage(t, c); msg.Action != AppearanceMessage || msg.ID != "a" {
+		t.Errorf("expected an appearance, got %+v", msg)
+	}
+	_ = nextMessage(t, c)
+	if msg := nextMessage(t, c); msg.Payload.(map[string]any)["transactionIndex"] != uint32(1) {
+		t.Errorf("expected the late appearance, got %+v", msg.Payload)
+	}
+
+	c.handleReorg(&notify.NotificationPayloadReorg{BlockNumber: "101"})
+	if msg := nextMessage(t, c); msg.Action != ReorgMessage {
+		t.Errorf("expected a reorg, got %+v", msg)
+	}
+	c.deliver(nil, sub, types.Appearance{Address: addr, BlockNumber: 101, TransactionIndex: 0})
+	if msg := nextMessage(t, c); msg.Action != AppearanceMessage {
+		t.Errorf("expected the reorged appearance to be sent again, got %+v", msg)
+	}
+}
+
+func TestSubscriptionJobs(t *testing.T) {
+	job := newSubscriptionJob(notify.MessageAppearance, []notify.NotificationPayloadAppearance{
+		{Address: subscribedAddr, BlockNumber: "100", TransactionIndex: 2},
+	})
+	if job == nil || len(job.appearances) != 1 || job.appearances[0].BlockNumber != 100 ||
+		job.appearances[0].Address != base.HexToAddress(subscribedAddr) {
+		t.Errorf("unexpected job %+v", job)
+	}
+
+	job = newSubscriptionJob(notify.MessageReorg, notify.NotificationPayloadReorg{BlockNumber: "100"})
+	if job == nil || job.reorg == nil || job.reorg.BlockNumber != "100" {
+		t.Errorf("unexpected job %+v", job)
+	}
+
+	if job = newSubscriptionJob(notify.MessageChunkWritten, "000-100"); job != nil {
+		t.Errorf("expected no job, got %+v", job)
+	}
+
+	c := newTestConnection()
+	c.jobs = make(chan *subscriptionJob, 1)
+	c.schedule(&subscriptionJob{})
+	c.schedule(&subscriptionJob{appearances: []types.Appearance{{BlockNumber: 200}}})
+	if msg := nextMessage(t, c); msg.Action != CommandErrorMessage {
+		t.Errorf("expected the client to be asked to resume, got %+v", msg)
+	}
+}
+
+func TestSubscriptionPoolOverflow(t *testing.T) {
+	pool := newConnectionPool()
+	pool.notifications = make(chan *subscriptionJob, 1)
+	appearances := func(bn string) []notify.NotificationPayloadAppearance {
+		return []notify.NotificationPayloadAppearance{{Address: subscribedAddr, BlockNumber: bn}}
+	}
+
+	// the pool is not running, so the second and third notifications are dropped without blocking
+	pool.listen(notify.MessageAppearance, nil, appearances("100"))
+	pool.listen(notify.MessageAppearance, nil, appearances("102"))
+	pool.listen(notify.MessageReorg, nil, notify.NotificationPayloadReorg{BlockNumber: "101"})
+	if len(pool.notifications) != 1 {
+		t.Fatalf("expected one pending notification, got %d", len(pool.notifications))
+	}
+	if bn, ok := pool.takeMissed(); !ok || bn != 101 {
+		t.Errorf("expected to resume from block 101, got %d (%t)", bn, ok)
+	}
+	if _, ok := pool.takeMissed(); ok {
+		t.Error("expected the missed block to be forgotten")
+	}
+}
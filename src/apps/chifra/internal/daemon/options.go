@@ -26,16 +26,18 @@ import (
 
 // DaemonOptions provides all command options for the chifra daemon command.
 type DaemonOptions struct {
-	Url     string                `json:"url,omitempty"`     // Specify the API server's url and optionally its port
-	Silent  bool                  `json:"silent,omitempty"`  // Disable logging (for use in SDK for example)
-	Port    string                `json:"port,omitempty"`    // Deprecated, use --url instead
-	Grpc    bool                  `json:"grpc,omitempty"`    // Deprecated, there is no replacement
-	Api     string                `json:"api,omitempty"`     // Deprecated, there is no replacement
-	Scrape  string                `json:"scrape,omitempty"`  // Deprecated, use chifra scrape instead
-	Monitor bool                  `json:"monitor,omitempty"` // Deprecated, use chifra monitors --watch instead
-	Globals globals.GlobalOptions `json:"globals,omitempty"` // The global options
-	Conn    *rpc.Connection       `json:"conn,omitempty"`    // The connection to the RPC server
-	BadFlag error                 `json:"badFlag,omitempty"` // An error flag if needed
+	Url       string                `json:"url,omitempty"`       // Specify the API server's url and optionally its port
+	Silent    bool                  `json:"silent,omitempty"`    // Disable logging (for use in SDK for example)
+	Port      string                `json:"port,omitempty"`      // Deprecated, use --url instead
+	Grpc      bool                  `json:"grpc,omitempty"`      // Deprecated, there is no replacement
+	Api       string                `json:"api,omitempty"`       // Deprecated, there is no replacement
+	Scrape    string                `json:"scrape,omitempty"`    // Deprecated, use chifra scrape instead
+	Monitor   bool                  `json:"monitor,omitempty"`   // Run the monitor watcher (chifra monitors --watch) alongside the API server
+	Watchlist string                `json:"watchlist,omitempty"` // Available with --monitor option only, a file containing the addresses to watch (all existing monitors by default)
+	Commands  string                `json:"commands,omitempty"`  // Available with --monitor option only, the file containing the list of commands to apply to each watched address
+	Globals   globals.GlobalOptions `json:"globals,omitempty"`   // The global options
+	Conn      *rpc.Connection       `json:"conn,omitempty"`      // The connection to the RPC server
+	BadFlag   error                 `json:"badFlag,omitempty"`   // An error flag if needed
 	// EXISTING_CODE
 	// EXISTING_CODE
 }
@@ -50,6 +52,9 @@ var defaultDaemonOptions = DaemonOptions{
 func (opts *DaemonOptions) testLog() {
 	logger.TestLog(len(opts.Url) > 0 && opts.Url != "localhost:8080", "Url: ", opts.Url)
 	logger.TestLog(opts.Silent, "Silent: ", opts.Silent)
+	logger.TestLog(opts.Monitor, "Monitor: ", opts.Monitor)
+	logger.TestLog(len(opts.Watchlist) > 0, "Watchlist: ", opts.Watchlist)
+	logger.TestLog(len(opts.Commands) > 0, "Commands: ", opts.Commands)
 	opts.Conn.TestLog(opts.getCaches())
 	opts.Globals.TestLog()
 }
@@ -92,6 +97,10 @@ func DaemonFinishParseInternal(w io.Writer, values url.Values) *DaemonOptions {
 			opts.Scrape = value[0]
 		case "monitor":
 			opts.Monitor = true
+		case "watchlist":
+			opts.Watchlist = value[0]
+		case "commands":
+			opts.Commands = value[0]
 		default:
 			if !copy.Globals.Caps.HasKey(key) {
 				err := validate.Usage("Invalid key ({0}) in {1} route.", key, "daemon")
@@ -116,12 +125,6 @@ func DaemonFinishParseInternal(w io.Writer, values url.Values) *DaemonOptions {
 		opts.Scrape = ""
 	}
 
-	// Deprecated...
-	if opts.Monitor {
-		logger.Warn("The --monitor flag is deprecated. Please use chifra monitors --watch instead.")
-		opts.Monitor = false
-	}
-
 	// EXISTING_CODE
 	// EXISTING_CODE
 
@@ -160,12 +163,6 @@ func daemonFinishParse(args []string) *DaemonOptions {
 		opts.Scrape = ""
 	}
 
-	// Deprecated...
-	if opts.Monitor {
-		logger.Warn("The --monitor flag is deprecated. Please use chifra monitors --watch instead.")
-		opts.Monitor = false
-	}
-
 	// EXISTING_CODE
 	// EXISTING_CODE
 	if len(opts.Globals.Format) == 0 || opts.Globals.Format == "none" {
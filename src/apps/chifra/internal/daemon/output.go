@@ -12,6 +12,7 @@ package daemonPkg
 import (
 	"fmt"
 	"net/http"
+	"path/filepath"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/globals"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/colors"
@@ -38,7 +39,7 @@ func RunDaemon(cmd *cobra.Command, args []string) error {
 // ServeDaemon handles the daemon command for the API. Returns an error.
 func ServeDaemon(w http.ResponseWriter, r *http.Request) error {
 	opts := daemonFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	if true { // defeats linter
 		logger.Fatal("should not happen ==> Daemon is an invalid route for server")
@@ -69,6 +70,7 @@ func (opts *DaemonOptions) DaemonInternal(rCtx *output.RenderCtx) error {
 	logger.InfoTable("Chain Config Path: ", config.MustGetPathToChainConfig(chain))
 	logger.InfoTable("Cache Path:        ", config.PathToCache(chain))
 	logger.InfoTable("Index Path:        ", config.PathToIndex(chain))
+	logger.InfoTable("Security:          ", securityString(config.GetDaemon()))
 
 	meta, err := opts.Conn.GetMetaData(false)
 	if err != nil {
@@ -88,16 +90,28 @@ func (opts *DaemonOptions) DaemonInternal(rCtx *output.RenderCtx) error {
 		_ = opts.HandleScraper(rCtx)
 	}()
 	go func() {
-		_ = opts.HandleMonitor(rCtx)
+		if err := opts.HandleMonitor(rCtx); err != nil {
+			logger.Error("monitor watcher stopped:", err)
+		}
 	}()
 
 	// do not remove, this fixes a lint warning that happens in the boilerplate because of the Fatal just below
 	timer.Report(msg)
 
 	// Start listening to the web sockets
-	RunWebsocketPool()
+	RunWebsocketPool(chain, opts.Conn)
+	// Reload the jobs of earlier runs and accept new ones
+	if jobManager, err = NewJobManager(filepath.Join(config.PathToCache(chain), "jobs")); err != nil {
+		logger.Error("jobs are not available:", err)
+	}
 	// Start listening for requests
-	logger.Fatal(http.ListenAndServe(opts.Url, NewRouter(opts.Silent)))
+	settings := config.GetDaemon()
+	auth, _ := NewAuthenticator(settings) // checked in validate
+	router := NewRouter(opts.Silent, auth)
+	if len(settings.CertFile) > 0 {
+		logger.Fatal(http.ListenAndServeTLS(opts.Url, settings.CertFile, settings.KeyFile, router))
+	}
+	logger.Fatal(http.ListenAndServe(opts.Url, router))
 
 	// EXISTING_CODE
 
@@ -13,6 +13,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/gorilla/mux"
 	// EXISTING_CODE
 
 	abisPkg "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/abis"
@@ -151,9 +152,33 @@ var routes = []Route{
 	{"Index", "GET", "/", func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "https://trueblocks.io/docs/", http.StatusMovedPermanently)
 	}},
+	{"OpenApi", "GET", "/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		HandleOpenApi(w)
+	}},
 	{"Websockets", "GET", "/websocket", func(w http.ResponseWriter, r *http.Request) {
 		HandleWebsockets(connectionPool, w, r)
 	}},
+	{"Notify", "POST", "/notify", func(w http.ResponseWriter, r *http.Request) {
+		HandleNotify(w, r)
+	}},
+	{"PauseMonitor", "POST", "/monitor/pause", func(w http.ResponseWriter, r *http.Request) {
+		HandleMonitorState(w, true /* pause */)
+	}},
+	{"ResumeMonitor", "POST", "/monitor/resume", func(w http.ResponseWriter, r *http.Request) {
+		HandleMonitorState(w, false /* pause */)
+	}},
+	{"StartJob", "POST", "/jobs", func(w http.ResponseWriter, r *http.Request) {
+		HandleJobStart(jobManager, w, r)
+	}},
+	{"JobStatus", "GET", "/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		HandleJobStatus(jobManager, w, mux.Vars(r)["id"])
+	}},
+	{"JobResult", "GET", "/jobs/{id}/result", func(w http.ResponseWriter, r *http.Request) {
+		HandleJobResult(jobManager, w, r, mux.Vars(r)["id"])
+	}},
+	{"CancelJob", "DELETE", "/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		HandleJobCancel(jobManager, w, mux.Vars(r)["id"])
+	}},
 	{"DeleteMonitors", "DELETE", "/monitors", func(w http.ResponseWriter, r *http.Request) {
 		if err := monitorsPkg.ServeMonitors(w, r); err != nil {
 			RespondWithError(w, http.StatusInternalServerError, err)
@@ -28,13 +28,15 @@ func RespondWithError(w http.ResponseWriter, httpStatus int, err error) {
 }
 
 // NewRouter Creates a new router given the routes array
-func NewRouter(silent bool) *mux.Router {
+func NewRouter(silent bool, auth *Authenticator) *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
 	router.Use(CorsHandler)
 	router.
 		Methods("OPTIONS").
 		Handler(OptionsHandler)
+	router.Use(auth.Handler)
 	router.Use(ContentTypeHandler)
+	router.Use(ValidateHandler)
 
 	for _, route := range routes {
 		var handler http.Handler
@@ -52,7 +54,7 @@ func NewRouter(silent bool) *mux.Router {
 
 func addCorsHeaders(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept")
+	w.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization, X-API-Key")
 	w.Header().Set("Access-Control-Allow-Methods", "PUT, POST, GET, DELETE, OPTIONS")
 }
 
@@ -71,23 +73,23 @@ func CorsHandler(next http.Handler) http.Handler {
 // ContentTypeHandler sets correct Content-Type header on response
 func ContentTypeHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestedFormat := r.URL.Query().Get("fmt")
-
-		var contentType string
-		switch requestedFormat {
-		case "txt":
-			contentType = "text/plain"
-		case "csv":
-			contentType = "text/csv"
-		default:
-			contentType = "application/json"
-		}
-
-		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Type", contentTypeFor(r.URL.Query().Get("fmt")))
 		next.ServeHTTP(w, r)
 	})
 }
 
+// contentTypeFor returns the content type of output in the requested format
+func contentTypeFor(requestedFormat string) string {
+	switch requestedFormat {
+	case "txt":
+		return "text/plain"
+	case "csv":
+		return "text/csv"
+	default:
+		return "application/json"
+	}
+}
+
 var nProcessed int
 
 // Logger sends information to the server's console
@@ -5,7 +5,11 @@
 package daemonPkg
 
 import (
+	"path/filepath"
+
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
 )
 
@@ -26,6 +30,19 @@ func (opts *DaemonOptions) validateDaemon() error {
 		return validate.Usage("The {0} option is not available{1}.", "daemon", " in api mode")
 	}
 
+	settings := config.GetDaemon()
+	if (len(settings.CertFile) > 0) != (len(settings.KeyFile) > 0) {
+		return validate.Usage("The daemon's {0} and {1} settings must be given together.", "certFile", "keyFile")
+	}
+	for _, fn := range []string{settings.CertFile, settings.KeyFile} {
+		if len(fn) > 0 && !file.FileExists(fn) {
+			return validate.Usage("The daemon's TLS file {0} was not found.", fn)
+		}
+	}
+	if _, err := NewAuthenticator(settings); err != nil {
+		return validate.Usage("The daemon's settings are invalid: {0}.", err.Error())
+	}
+
 	if opts.Grpc {
 		return validate.Usage("The {0} option is deprecated. There is no replacement.", "--grpc")
 	}
@@ -39,7 +56,20 @@ func (opts *DaemonOptions) validateDaemon() error {
 	}
 
 	if opts.Monitor {
-		return validate.Usage("The {0} option is currenlty not available. Use {1} instead.", "--monitor", "chifra monitors --watch")
+		for _, fn := range []string{opts.Watchlist, opts.Commands} {
+			if len(fn) > 0 && fn != "existing" {
+				if path, err := filepath.Abs(fn); err != nil || !file.FileExists(path) {
+					return validate.Usage("The {0} option requires {1} to exist.", "--monitor", fn)
+				}
+			}
+		}
+
+		if err := index.IsInitialized(chain, config.ExpectedVersion()); err != nil {
+			return err
+		}
+
+	} else if len(opts.Watchlist) > 0 || len(opts.Commands) > 0 {
+		return validate.Usage("The {0} and {1} options are only available with {2}.", "--watchlist", "--commands", "--monitor")
 	}
 
 	return opts.Globals.Validate()
@@ -33,7 +33,7 @@ func (opts *ExportOptions) HandleAccounting(rCtx *output.RenderCtx, monitorArray
 	)
 
 	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
-		visitAppearance := func(app *types.Appearance) error {
+		visitAppearance := func(mon *monitor.Monitor, app *types.Appearance) error {
 			if tx, err := opts.Conn.GetTransactionByAppearance(app, false); err != nil {
 				errorChan <- err
 				return nil
@@ -55,6 +55,7 @@ func (opts *ExportOptions) HandleAccounting(rCtx *output.RenderCtx, monitorArray
 					}
 
 					modelChan <- tx
+					mon.Cursor.Advance(tx.BlockNumber, tx.TransactionIndex)
 				}
 				return nil
 			}
@@ -78,17 +79,19 @@ func (opts *ExportOptions) HandleAccounting(rCtx *output.RenderCtx, monitorArray
 					opts.Reversed,
 					&opts.Asset,
 				)
+				ledgers.FiatCurrency = opts.Currency
+				ledgers.Nfts = opts.Nfts
 				_ = ledgers.SetContexts(chain, apps)
 
 				for _, app := range apps {
-					if err := visitAppearance(&app); err != nil {
+					if err := visitAppearance(&mon, &app); err != nil {
 						errorChan <- err
 						return
 					}
 				}
 
 			} else {
-				errorChan <- fmt.Errorf("no appearances found for %s", mon.Address.Hex())
+				opts.reportNothingNew(errorChan, fmt.Errorf("no appearances found for %s", mon.Address.Hex()))
 				continue
 			}
 		}
@@ -99,5 +102,5 @@ func (opts *ExportOptions) HandleAccounting(rCtx *output.RenderCtx, monitorArray
 		"export":     true,
 	}
 
-	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOptsWithExtra(extraOpts))
+	return opts.streamMany(rCtx, fetchData, opts.Globals.OutputOptsWithExtra(extraOpts), monitorArray)
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/expr"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
@@ -24,6 +25,13 @@ func (opts *ExportOptions) HandleAppearances(rCtx *output.RenderCtx, monitorArra
 		base.BlockRange{First: opts.FirstBlock, Last: opts.LastBlock},
 		base.RecordRange{First: opts.FirstRecord, Last: opts.GetMax()},
 	)
+	// Appearances are counted as they are read, before their records are built, so the filter
+	// only skips those whose records could never pass --where. The stream tests the records.
+	where, err := expr.ParseWhere(opts.Globals.Where)
+	if err != nil {
+		return err
+	}
+	filter.SetWhere(where, chain, opts.Globals.Format, opts.Globals.Verbose, nil)
 
 	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
 		currentBn := uint32(0)
@@ -33,6 +41,9 @@ func (opts *ExportOptions) HandleAppearances(rCtx *output.RenderCtx, monitorArra
 				return
 			} else if !opts.NoZero || cnt > 0 {
 				for _, app := range apps {
+					if rCtx.WasCanceled() {
+						return
+					}
 					if opts.Globals.Verbose {
 						if app.BlockNumber == 0 || app.BlockNumber != currentBn {
 							app.Timestamp, _ = tslib.FromBnToTs(chain, base.Blknum(app.BlockNumber))
@@ -40,9 +51,10 @@ func (opts *ExportOptions) HandleAppearances(rCtx *output.RenderCtx, monitorArra
 						currentBn = app.BlockNumber
 					}
 					modelChan <- &app
+					mon.Cursor.Advance(base.Blknum(app.BlockNumber), base.Txnum(app.TransactionIndex))
 				}
 			} else {
-				errorChan <- fmt.Errorf("no appearances found for %s", mon.Address.Hex())
+				opts.reportNothingNew(errorChan, fmt.Errorf("no appearances found for %s", mon.Address.Hex()))
 				continue
 			}
 		}
@@ -52,7 +64,7 @@ func (opts *ExportOptions) HandleAppearances(rCtx *output.RenderCtx, monitorArra
 		"export": true,
 	}
 
-	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOptsWithExtra(extraOpts))
+	return opts.streamMany(rCtx, fetchData, opts.Globals.OutputOptsWithExtra(extraOpts), monitorArray)
 }
 
 func (opts *ExportOptions) IsMax(cnt uint64) bool {
@@ -64,7 +76,7 @@ func (opts *ExportOptions) IsMax(cnt uint64) bool {
 }
 
 func (opts *ExportOptions) GetMax() uint64 {
-	if opts.MaxRecords == 250 && !opts.Globals.IsApiMode() {
+	if opts.MaxRecords == 250 && (!opts.Globals.IsApiMode() || len(opts.Cursor) > 0) {
 		return base.NOPOS
 	}
 	return opts.MaxRecords
@@ -42,7 +42,7 @@ func (opts *ExportOptions) HandleBalances(rCtx *output.RenderCtx, monitorArray [
 				rCtx.Cancel()
 
 			} else if cnt == 0 {
-				errorChan <- fmt.Errorf("no blocks found for the query")
+				opts.reportNothingNew(errorChan, fmt.Errorf("no blocks found for the query"))
 				continue
 
 			} else {
@@ -55,6 +55,7 @@ func (opts *ExportOptions) HandleBalances(rCtx *output.RenderCtx, monitorArray [
 					bar := logger.NewBar(logger.BarOptions{
 						Prefix:  mon.Address.Hex(),
 						Enabled: showProgress,
+						Report:  opts.Globals.ProgressReport(),
 						Total:   int64(cnt),
 					})
 
@@ -121,9 +122,10 @@ func (opts *ExportOptions) HandleBalances(rCtx *output.RenderCtx, monitorArray [
 								currentBn = item.BlockNumber
 								if idx == 0 || item.PriorBalance.Cmp(&item.Balance) != 0 || opts.Globals.Verbose {
 									var passes bool
-									passes, finished = filter.ApplyCountFilter()
+									passes, finished = filter.ApplyModelFilter(item)
 									if passes {
 										modelChan <- item
+										mon.Cursor.Advance(item.BlockNumber, item.TransactionIndex)
 									}
 								}
 								prevBalance = &item.Balance
@@ -151,5 +153,9 @@ func (opts *ExportOptions) HandleBalances(rCtx *output.RenderCtx, monitorArray [
 		"parts":  []string{"blockNumber", "date", "holder", "balance", "diff", "balanceDec"},
 	}
 
-	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOptsWithExtra(extraOpts))
+	outputOpts, err := opts.Globals.OutputOptsWithWhere(filter, extraOpts)
+	if err != nil {
+		return err
+	}
+	return opts.streamMany(rCtx, fetchData, outputOpts, monitorArray)
 }
@@ -0,0 +1,59 @@
+package exportPkg
+
+import (
+	"fmt"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// ReadCursors attaches the --cursor of each monitor so that only appearances after it are exported
+func (opts *ExportOptions) ReadCursors(monitorArray []monitor.Monitor) error {
+	for i := range monitorArray {
+		cursor, err := monitorArray[i].ReadCursor(opts.Cursor)
+		if err != nil {
+			return err
+		}
+		monitorArray[i].Cursor = cursor
+	}
+	return nil
+}
+
+// SaveCursors advances the --cursor of each monitor past the appearances whose records were
+// exported. The cursors are saved only if the export completed: it was not canceled and no
+// errors were reported (an appearance whose records could not be fetched would otherwise be
+// skipped for good).
+func (opts *ExportOptions) SaveCursors(rCtx *output.RenderCtx, monitorArray []monitor.Monitor) error {
+	if len(opts.Cursor) == 0 || rCtx.WasCanceled() || rCtx.HadErrors() {
+		return nil
+	}
+	for _, mon := range monitorArray {
+		if mon.Cursor == nil {
+			continue
+		}
+		if err := mon.Cursor.Save(base.Blknum(mon.LastScanned)); err != nil {
+			return fmt.Errorf("could not save cursor %s for %s: %w", opts.Cursor, mon.Address.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// reportNothingNew reports that a monitor has no appearances to export. With --cursor, a monitor
+// with nothing new since the previous run is expected and not an error, which would otherwise keep
+// the other monitors' cursors from being saved.
+func (opts *ExportOptions) reportNothingNew(errorChan chan error, err error) {
+	if len(opts.Cursor) == 0 {
+		errorChan <- err
+	}
+}
+
+// streamMany streams the records produced by fetchData and then saves the --cursor (if any) of
+// each monitor
+func (opts *ExportOptions) streamMany(rCtx *output.RenderCtx, fetchData func(modelChan chan types.Modeler, errorChan chan error), outputOpts output.OutputOptions, monitorArray []monitor.Monitor) error {
+	if err := output.StreamMany(rCtx, fetchData, outputOpts); err != nil {
+		return err
+	}
+	return opts.SaveCursors(rCtx, monitorArray)
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package exportPkg
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/ledger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// HandleEntity collects the statements of every member of the entity given with --entity (loaded
+// when the options were validated) and reports them consolidated into one statement per transaction
+// and asset. The record range (--first_record and --max_records) applies to the consolidated
+// statements, not to each member.
+func (opts *ExportOptions) HandleEntity(rCtx *output.RenderCtx, monitorArray []monitor.Monitor) error {
+	readFilter := filter.NewFilter(
+		false,
+		opts.Reverted,
+		opts.Fourbytes,
+		base.BlockRange{First: opts.FirstBlock, Last: opts.LastBlock},
+		base.RecordRange{First: 0, Last: base.NOPOS},
+	)
+	filter := filter.NewFilter(
+		opts.Reversed,
+		opts.Reverted,
+		opts.Fourbytes,
+		base.BlockRange{First: opts.FirstBlock, Last: opts.LastBlock},
+		base.RecordRange{First: opts.FirstRecord, Last: opts.GetMax()},
+	)
+
+	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
+		all := make([]types.Statement, 0)
+		total := 0
+		for _, mon := range monitorArray {
+			sOpts := statementOptions{filter: readFilter}
+			cnt, err := opts.forEachStatements(rCtx, &mon, sOpts, errorChan, func(_ *ledger.Ledger, statements []types.Statement) bool {
+				all = append(all, statements...)
+				return true
+			})
+			if err != nil {
+				errorChan <- err
+				return
+			}
+			total += cnt
+			if rCtx.WasCanceled() {
+				return
+			}
+		}
+
+		if total == 0 {
+			errorChan <- fmt.Errorf("no blocks found for the query")
+			return
+		}
+
+		// Statements within a transaction keep their log order when consolidated
+		sort.SliceStable(all, func(i, j int) bool {
+			if all[i].BlockNumber == all[j].BlockNumber {
+				if all[i].TransactionIndex == all[j].TransactionIndex {
+					return all[i].LogIndex < all[j].LogIndex
+				}
+				return all[i].TransactionIndex < all[j].TransactionIndex
+			}
+			return all[i].BlockNumber < all[j].BlockNumber
+		})
+
+		items := opts.entity.Consolidate(all, opts.Intra)
+		if opts.Reversed {
+			for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+				items[i], items[j] = items[j], items[i]
+			}
+		}
+
+		for i := range items {
+			if opts.NoZero && !items[i].IsMaterial() {
+				continue
+			}
+			passes, finished := filter.ApplyModelFilter(&items[i])
+			if passes {
+				modelChan <- &items[i]
+			}
+			if finished {
+				break
+			}
+		}
+	}
+
+	extraOpts := map[string]any{
+		"articulate": opts.Articulate,
+		"export":     true,
+	}
+
+	outputOpts, err := opts.Globals.OutputOptsWithWhere(filter, extraOpts)
+	if err != nil {
+		return err
+	}
+	return output.StreamMany(rCtx, fetchData, outputOpts)
+}
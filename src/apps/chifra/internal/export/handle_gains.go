@@ -0,0 +1,104 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package exportPkg
+
+import (
+	"fmt"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/costbasis"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/ledger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// HandleGains feeds each monitor's statements (in chronological order) through the cost basis
+// engine and reports either each disposal or, if --period is present, a summary per asset per period.
+// A disposal's cost basis depends on every earlier acquisition, so the engine sees the monitor's
+// whole history up to --last_block. The block and record ranges choose only what is reported.
+func (opts *ExportOptions) HandleGains(rCtx *output.RenderCtx, monitorArray []monitor.Monitor) error {
+	readFilter := filter.NewFilter(
+		false,
+		false,
+		[]string{},
+		base.BlockRange{First: 0, Last: opts.LastBlock},
+		base.RecordRange{First: 0, Last: base.NOPOS},
+	)
+	filter := filter.NewFilter(
+		false,
+		false,
+		[]string{},
+		base.BlockRange{First: opts.FirstBlock, Last: opts.LastBlock},
+		base.RecordRange{First: opts.FirstRecord, Last: opts.GetMax()},
+	)
+	sOpts := statementOptions{
+		filter: readFilter,
+	}
+
+	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
+		for _, mon := range monitorArray {
+			engine, err := costbasis.NewEngine(opts.CostBasis, opts.Period)
+			if err != nil {
+				errorChan <- err
+				return
+			}
+
+			finished := false
+			emit := func(disposals []types.Disposal, summaries []types.GainSummary) {
+				if len(opts.Period) > 0 {
+					for i := 0; i < len(summaries) && !finished; i++ {
+						if summaries[i].BlockNumber < opts.FirstBlock {
+							continue
+						}
+						var passes bool
+						if passes, finished = filter.ApplyModelFilter(&summaries[i]); passes {
+							modelChan <- &summaries[i]
+						}
+					}
+				} else {
+					for i := 0; i < len(disposals) && !finished; i++ {
+						if disposals[i].BlockNumber < opts.FirstBlock {
+							continue
+						}
+						var passes bool
+						if passes, finished = filter.ApplyModelFilter(&disposals[i]); passes {
+							modelChan <- &disposals[i]
+						}
+					}
+				}
+			}
+
+			visit := func(_ *ledger.Ledger, items []types.Statement) bool {
+				for i := 0; i < len(items) && !finished; i++ {
+					emit(engine.Process(&items[i]))
+				}
+				return !finished
+			}
+
+			if cnt, err := opts.forEachStatements(rCtx, &mon, sOpts, errorChan, visit); err != nil {
+				errorChan <- err
+				return
+
+			} else if cnt == 0 {
+				errorChan <- fmt.Errorf("no blocks found for the query")
+				continue
+			}
+
+			emit(nil, engine.Flush())
+		}
+	}
+
+	extraOpts := map[string]any{
+		"export": true,
+	}
+
+	outputOpts, err := opts.Globals.OutputOptsWithWhere(filter, extraOpts)
+	if err != nil {
+		return err
+	}
+	return output.StreamMany(rCtx, fetchData, outputOpts)
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package exportPkg
+
+import (
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/journal"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/names"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// HandleJournal collects the statements produced by HandleStatements (or HandleEntity) and writes them as a
+// double-entry journal in the syntax chosen with --journal. Unlike the other handlers, the
+// output is written only after all statements are collected because the journal opens with
+// its account, commodity, and price declarations.
+func (opts *ExportOptions) HandleJournal(rCtx *output.RenderCtx, monitorArray []monitor.Monitor) error {
+	chain := opts.Globals.Chain
+
+	owned := make([]base.Address, 0, len(monitorArray))
+	for _, mon := range monitorArray {
+		owned = append(owned, mon.Address)
+	}
+
+	parts := types.Custom | types.Prefund | types.Regular
+	if opts.Globals.TestMode {
+		parts |= types.Testing
+	}
+	namesMap, err := names.LoadNamesMap(chain, parts, nil)
+	if err != nil {
+		return err
+	}
+
+	jour, err := journal.NewJournal(opts.Journal, config.GetChain(chain).Symbol, config.GetJournal(), namesMap, owned)
+	if err != nil {
+		return err
+	}
+
+	// The handler's error (if it fails before streaming) is set before the model channel is closed
+	var handlerErr error
+	sCtx := output.NewStreamingContext()
+	go func() {
+		if len(opts.Entity) > 0 {
+			handlerErr = opts.HandleEntity(sCtx, monitorArray)
+		} else {
+			handlerErr = opts.HandleStatements(sCtx, monitorArray)
+		}
+		close(sCtx.ModelChan)
+	}()
+
+	// A journal missing some statements would not balance, so the first error stops the statements
+	// and nothing is written. If the caller cancels, we stop the statements too. Either way, we keep
+	// draining the channels until the handler returns.
+	canceled := rCtx.Ctx.Done()
+	var firstErr error
+	for done := false; !done; {
+		select {
+		case model, ok := <-sCtx.ModelChan:
+			if !ok {
+				done = true
+			} else if statement, ok := model.(*types.Statement); ok && firstErr == nil {
+				jour.Add(statement)
+			}
+		case err := <-sCtx.ErrorChan:
+			if firstErr == nil {
+				firstErr = err
+				sCtx.Cancel()
+			}
+		case <-canceled:
+			sCtx.Cancel()
+			canceled = nil
+		}
+	}
+
+	if handlerErr != nil {
+		return handlerErr
+	} else if firstErr != nil {
+		return firstErr
+	}
+
+	if rCtx.WasCanceled() {
+		return nil
+	}
+
+	return jour.Write(opts.Globals.Writer)
+}
@@ -43,7 +43,7 @@ func (opts *ExportOptions) HandleLogs(rCtx *output.RenderCtx, monitorArray []mon
 				rCtx.Cancel()
 
 			} else if cnt == 0 {
-				errorChan <- fmt.Errorf("no blocks found for the query")
+				opts.reportNothingNew(errorChan, fmt.Errorf("no blocks found for the query"))
 				continue
 
 			} else {
@@ -56,6 +56,7 @@ func (opts *ExportOptions) HandleLogs(rCtx *output.RenderCtx, monitorArray []mon
 					bar := logger.NewBar(logger.BarOptions{
 						Prefix:  mon.Address.Hex(),
 						Enabled: showProgress,
+						Report:  opts.Globals.ProgressReport(),
 						Total:   int64(cnt),
 					})
 
@@ -131,9 +132,10 @@ func (opts *ExportOptions) HandleLogs(rCtx *output.RenderCtx, monitorArray []mon
 
 						for _, item := range items {
 							var passes bool
-							passes, finished = filter.ApplyCountFilter()
+							passes, finished = filter.ApplyModelFilter(item)
 							if passes {
 								modelChan <- item
+								mon.Cursor.Advance(item.BlockNumber, item.TransactionIndex)
 							}
 							if finished {
 								break
@@ -151,5 +153,9 @@ func (opts *ExportOptions) HandleLogs(rCtx *output.RenderCtx, monitorArray []mon
 		"export":     true,
 	}
 
-	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOptsWithExtra(extraOpts))
+	outputOpts, err := opts.Globals.OutputOptsWithWhere(filter, extraOpts)
+	if err != nil {
+		return err
+	}
+	return opts.streamMany(rCtx, fetchData, outputOpts, monitorArray)
 }
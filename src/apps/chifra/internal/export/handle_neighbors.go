@@ -36,7 +36,7 @@ func (opts *ExportOptions) HandleNeighbors(rCtx *output.RenderCtx, monitorArray
 				rCtx.Cancel()
 
 			} else if cnt == 0 {
-				errorChan <- fmt.Errorf("no blocks found for the query")
+				opts.reportNothingNew(errorChan, fmt.Errorf("no blocks found for the query"))
 				continue
 
 			} else {
@@ -49,6 +49,7 @@ func (opts *ExportOptions) HandleNeighbors(rCtx *output.RenderCtx, monitorArray
 					bar := logger.NewBar(logger.BarOptions{
 						Prefix:  mon.Address.Hex(),
 						Enabled: showProgress,
+						Report:  opts.Globals.ProgressReport(),
 						Total:   int64(cnt),
 					})
 
@@ -114,9 +115,10 @@ func (opts *ExportOptions) HandleNeighbors(rCtx *output.RenderCtx, monitorArray
 
 						for _, item := range items {
 							var passes bool
-							passes, finished = filter.ApplyCountFilter()
+							passes, finished = filter.ApplyModelFilter(&item)
 							if passes {
 								modelChan <- &item
+								mon.Cursor.Advance(base.Blknum(item.BlockNumber), base.Txnum(item.TransactionIndex))
 							}
 							if finished {
 								break
@@ -133,7 +135,11 @@ func (opts *ExportOptions) HandleNeighbors(rCtx *output.RenderCtx, monitorArray
 		"uniq": true,
 	}
 
-	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOptsWithExtra(extraOpts))
+	outputOpts, err := opts.Globals.OutputOptsWithWhere(filter, extraOpts)
+	if err != nil {
+		return err
+	}
+	return opts.streamMany(rCtx, fetchData, outputOpts, monitorArray)
 }
 
 /*
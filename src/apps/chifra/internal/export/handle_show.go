@@ -36,7 +36,7 @@ func (opts *ExportOptions) HandleShow(rCtx *output.RenderCtx, monitorArray []mon
 				rCtx.Cancel()
 
 			} else if cnt == 0 {
-				errorChan <- fmt.Errorf("no blocks found for the query")
+				opts.reportNothingNew(errorChan, fmt.Errorf("no blocks found for the query"))
 				continue
 
 			} else {
@@ -49,6 +49,7 @@ func (opts *ExportOptions) HandleShow(rCtx *output.RenderCtx, monitorArray []mon
 					bar := logger.NewBar(logger.BarOptions{
 						Prefix:  mon.Address.Hex(),
 						Enabled: showProgress,
+						Report:  opts.Globals.ProgressReport(),
 						Total:   int64(cnt),
 					})
 
@@ -117,9 +118,10 @@ func (opts *ExportOptions) HandleShow(rCtx *output.RenderCtx, monitorArray []mon
 								continue
 							}
 							var passes bool
-							passes, finished = filter.ApplyCountFilter()
+							passes, finished = filter.ApplyModelFilter(item)
 							if passes {
 								modelChan <- item
+								mon.Cursor.Advance(item.BlockNumber, item.TransactionIndex)
 							}
 							if finished {
 								break
@@ -137,5 +139,9 @@ func (opts *ExportOptions) HandleShow(rCtx *output.RenderCtx, monitorArray []mon
 		"export":     true,
 	}
 
-	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOptsWithExtra(extraOpts))
+	outputOpts, err := opts.Globals.OutputOptsWithWhere(filter, extraOpts)
+	if err != nil {
+		return err
+	}
+	return opts.streamMany(rCtx, fetchData, outputOpts, monitorArray)
 }
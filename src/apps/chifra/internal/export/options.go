@@ -19,6 +19,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/globals"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/caps"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/ledger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
@@ -50,9 +51,19 @@ type ExportOptions struct {
 	Topic       []string              `json:"topic,omitempty"`       // For the --logs option only, filter logs to show only those with this topic(s)
 	Reverted    bool                  `json:"reverted,omitempty"`    // Export only transactions that were reverted
 	Asset       []string              `json:"asset,omitempty"`       // For the accounting options only, export statements only for this asset
+	Nfts        bool                  `json:"nfts,omitempty"`        // For the accounting options only, export statements only for ERC-721 and ERC-1155 tokens
+	Diagnose    bool                  `json:"diagnose,omitempty"`    // For the accounting options only, explain each token statement that does not reconcile by bisecting with balanceOf to find where the balance drifted (implies --statements)
 	Flow        string                `json:"flow,omitempty"`        // For the accounting options only, export statements with incoming, outgoing, or zero value
+	Entity      string                `json:"entity,omitempty"`      // For the accounting options only, consolidate statements across the addresses of an entity given as a file of addresses or a tag in the names database (implies --statements)
+	Intra       string                `json:"intra,omitempty"`       // For the --entity option only, eliminate transfers between the entity's addresses or keep them and flag the statement
+	Currency    string                `json:"currency,omitempty"`    // For the accounting options only, also report prices in this fiat currency (for example EUR or CHF) using the local FX rate table
+	Gains       bool                  `json:"gains,omitempty"`       // For the accounting options only, export the realized gain or loss on each disposal of an asset
+	CostBasis   string                `json:"costBasis,omitempty"`   // For the --gains option only, the method used to match disposals with previously acquired lots
+	Period      string                `json:"period,omitempty"`      // For the accounting options only, summarize realized and unrealized gains per asset for each period (implies --gains)
+	Journal     string                `json:"journal,omitempty"`     // For the accounting options only, render statements as a double-entry journal in the given syntax (implies --statements)
 	Factory     bool                  `json:"factory,omitempty"`     // For --traces only, report addresses created by (or self-destructed by) the given address(es)
 	Unripe      bool                  `json:"unripe,omitempty"`      // Export transactions labeled unripe (i.e. less than 28 blocks old)
+	Cursor      string                `json:"cursor,omitempty"`      // Remember the last record exported for each address under this name and export only newer records on the next run with the same name
 	Reversed    bool                  `json:"reversed,omitempty"`    // Produce results in reverse chronological order
 	NoZero      bool                  `json:"noZero,omitempty"`      // For the --count option only, suppress the display of zero appearance accounts
 	FirstBlock  base.Blknum           `json:"firstBlock,omitempty"`  // First block to process (inclusive)
@@ -61,11 +72,14 @@ type ExportOptions struct {
 	Conn        *rpc.Connection       `json:"conn,omitempty"`        // The connection to the RPC server
 	BadFlag     error                 `json:"badFlag,omitempty"`     // An error flag if needed
 	// EXISTING_CODE
+	entity *ledger.Entity
 	// EXISTING_CODE
 }
 
 var defaultExportOptions = ExportOptions{
 	MaxRecords: 250,
+	Intra:      "eliminate",
+	CostBasis:  "fifo",
 	LastBlock:  base.NOPOSN,
 }
 
@@ -93,9 +107,19 @@ func (opts *ExportOptions) testLog() {
 	logger.TestLog(len(opts.Topic) > 0, "Topic: ", opts.Topic)
 	logger.TestLog(opts.Reverted, "Reverted: ", opts.Reverted)
 	logger.TestLog(len(opts.Asset) > 0, "Asset: ", opts.Asset)
+	logger.TestLog(opts.Nfts, "Nfts: ", opts.Nfts)
+	logger.TestLog(opts.Diagnose, "Diagnose: ", opts.Diagnose)
 	logger.TestLog(len(opts.Flow) > 0, "Flow: ", opts.Flow)
+	logger.TestLog(len(opts.Entity) > 0, "Entity: ", opts.Entity)
+	logger.TestLog(len(opts.Intra) > 0 && opts.Intra != "eliminate", "Intra: ", opts.Intra)
+	logger.TestLog(len(opts.Currency) > 0, "Currency: ", opts.Currency)
+	logger.TestLog(opts.Gains, "Gains: ", opts.Gains)
+	logger.TestLog(len(opts.CostBasis) > 0 && opts.CostBasis != "fifo", "CostBasis: ", opts.CostBasis)
+	logger.TestLog(len(opts.Period) > 0, "Period: ", opts.Period)
+	logger.TestLog(len(opts.Journal) > 0, "Journal: ", opts.Journal)
 	logger.TestLog(opts.Factory, "Factory: ", opts.Factory)
 	logger.TestLog(opts.Unripe, "Unripe: ", opts.Unripe)
+	logger.TestLog(len(opts.Cursor) > 0, "Cursor: ", opts.Cursor)
 	logger.TestLog(opts.Reversed, "Reversed: ", opts.Reversed)
 	logger.TestLog(opts.NoZero, "NoZero: ", opts.NoZero)
 	logger.TestLog(opts.FirstBlock != 0, "FirstBlock: ", opts.FirstBlock)
@@ -124,6 +148,8 @@ func ExportFinishParseInternal(w io.Writer, values url.Values) *ExportOptions {
 	copy.Globals.Caps = getCaps()
 	opts := &copy
 	opts.MaxRecords = 250
+	opts.Intra = "eliminate"
+	opts.CostBasis = "fifo"
 	opts.LastBlock = base.NOPOSN
 	for key, value := range values {
 		switch key {
@@ -189,12 +215,32 @@ func ExportFinishParseInternal(w io.Writer, values url.Values) *ExportOptions {
 				s := strings.Split(val, " ") // may contain space separated items
 				opts.Asset = append(opts.Asset, s...)
 			}
+		case "nfts":
+			opts.Nfts = true
+		case "diagnose":
+			opts.Diagnose = true
 		case "flow":
 			opts.Flow = value[0]
+		case "entity":
+			opts.Entity = value[0]
+		case "intra":
+			opts.Intra = value[0]
+		case "currency":
+			opts.Currency = value[0]
+		case "gains":
+			opts.Gains = true
+		case "costBasis":
+			opts.CostBasis = value[0]
+		case "period":
+			opts.Period = value[0]
+		case "journal":
+			opts.Journal = value[0]
 		case "factory":
 			opts.Factory = true
 		case "unripe":
 			opts.Unripe = true
+		case "cursor":
+			opts.Cursor = value[0]
 		case "reversed":
 			opts.Reversed = true
 		case "noZero":
@@ -304,6 +350,8 @@ func ResetOptions(testMode bool) {
 	opts.Globals.Writer = w
 	opts.Globals.Caps = getCaps()
 	opts.MaxRecords = 250
+	opts.Intra = "eliminate"
+	opts.CostBasis = "fifo"
 	opts.LastBlock = base.NOPOSN
 	defaultExportOptions = opts
 }
@@ -0,0 +1,35 @@
+package exportPkg
+
+import (
+	"bytes"
+	"net/url"
+	"testing"
+)
+
+func TestExportFinishParseApiGlobals(t *testing.T) {
+	// neither loading the configuration nor parsing the options (for an unconfigured chain) needs a node
+	t.Setenv("TB_NO_PROVIDER_CHECK", "true")
+
+	tests := []struct {
+		values  url.Values
+		wantErr bool
+	}{
+		{url.Values{"addrs": {"0x1"}, "fields": {"hash,value/1e18"}}, false},
+		{url.Values{"addrs": {"0x1"}, "where": {"value > 1e18"}}, false},
+		{url.Values{"addrs": {"0x1"}, "notAnOption": {""}}, true},
+	}
+
+	for _, test := range tests {
+		test.values.Set("chain", "unconfigured")
+		opts := ExportFinishParseInternal(&bytes.Buffer{}, test.values)
+		if (opts.BadFlag != nil) != test.wantErr {
+			t.Errorf("%v: unexpected error %v", test.values, opts.BadFlag)
+		}
+		if fields := test.values.Get("fields"); opts.Globals.Fields != fields {
+			t.Errorf("%v: expected fields %q, got %q", test.values, fields, opts.Globals.Fields)
+		}
+		if where := test.values.Get("where"); opts.Globals.Where != where {
+			t.Errorf("%v: expected where %q, got %q", test.values, where, opts.Globals.Where)
+		}
+	}
+}
@@ -27,6 +27,10 @@ func RunExport(cmd *cobra.Command, args []string) error {
 	opts := exportFinishParse(args)
 	rCtx := output.NewRenderContext()
 	// EXISTING_CODE
+	if len(opts.Journal) > 0 {
+		// journals are plain text regardless of --fmt
+		opts.Globals.Format = "txt"
+	}
 	// EXISTING_CODE
 	outputHelpers.SetWriterForCommand("export", &opts.Globals)
 	return opts.ExportInternal(rCtx)
@@ -35,8 +39,12 @@ func RunExport(cmd *cobra.Command, args []string) error {
 // ServeExport handles the export command for the API. Returns an error.
 func ServeExport(w http.ResponseWriter, r *http.Request) error {
 	opts := exportFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
+	if len(opts.Journal) > 0 {
+		// journals are plain text regardless of --fmt
+		opts.Globals.Format = "txt"
+	}
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("export", w, &opts.Globals)
 	err := opts.ExportInternal(rCtx)
@@ -58,6 +66,12 @@ func (opts *ExportOptions) ExportInternal(rCtx *output.RenderCtx) error {
 	if canceled, err := opts.FreshenMonitorsForExport(rCtx, &monitorArray); err != nil || canceled {
 		return err
 	}
+	if len(opts.Cursor) > 0 {
+		// The handlers save the cursors once the records are streamed
+		if err = opts.ReadCursors(monitorArray); err != nil {
+			return err
+		}
+	}
 	// EXISTING_CODE
 	if opts.Globals.Decache {
 		err = opts.HandleDecache(rCtx, monitorArray)
@@ -77,6 +91,12 @@ func (opts *ExportOptions) ExportInternal(rCtx *output.RenderCtx) error {
 		err = opts.HandleBalances(rCtx, monitorArray)
 	} else if opts.Neighbors {
 		err = opts.HandleNeighbors(rCtx, monitorArray)
+	} else if opts.Gains {
+		err = opts.HandleGains(rCtx, monitorArray)
+	} else if len(opts.Journal) > 0 {
+		err = opts.HandleJournal(rCtx, monitorArray)
+	} else if len(opts.Entity) > 0 {
+		err = opts.HandleEntity(rCtx, monitorArray)
 	} else if opts.Statements {
 		err = opts.HandleStatements(rCtx, monitorArray)
 	} else if opts.Accounting {
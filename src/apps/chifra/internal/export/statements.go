@@ -0,0 +1,154 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package exportPkg
+
+import (
+	"context"
+	"sort"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/ledger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
+)
+
+// statementOptions holds what differs between the handlers that build a monitor's statements
+type statementOptions struct {
+	// filter chooses the appearances (and transactions) the statements are built from
+	filter *filter.AppearanceFilter
+	// noZero skips statements that do not change a balance
+	noZero bool
+	// reversed sorts each page's statements latest first (the order of the pages follows the filter)
+	reversed bool
+}
+
+// forEachStatements reads the monitor's appearances that pass the filter and, one page of
+// appearances at a time, builds the statements of their transactions. Each page's statements,
+// sorted by block, transaction, and log, are passed to visit along with the ledger that built
+// them. The iteration stops when visit returns false or the caller cancels. An error building a
+// transaction's statements is sent to errorChan; any other error ends the iteration and is
+// returned. The returned count is the number of appearances that passed the filter.
+func (opts *ExportOptions) forEachStatements(rCtx *output.RenderCtx, mon *monitor.Monitor, sOpts statementOptions, errorChan chan error, visit func(*ledger.Ledger, []types.Statement) bool) (int, error) {
+	apps, cnt, err := mon.ReadAndFilterAppearances(sOpts.filter, false /* withCount */)
+	if err != nil || cnt == 0 {
+		return cnt, err
+	}
+
+	sliceOfMaps, _, err := types.AsSliceOfMaps[types.Transaction](apps, sOpts.filter.Reversed)
+	if err != nil {
+		return cnt, err
+	}
+
+	bar := logger.NewBar(logger.BarOptions{
+		Prefix:  mon.Address.Hex(),
+		Enabled: opts.Globals.ShowProgress(),
+		Report:  opts.Globals.ProgressReport(),
+		Total:   int64(cnt),
+	})
+	defer bar.Finish(true /* newLine */)
+
+	for _, thisMap := range sliceOfMaps {
+		if rCtx.WasCanceled() {
+			return cnt, nil
+		}
+
+		for app := range thisMap {
+			thisMap[app] = new(types.Transaction)
+		}
+
+		iterFunc := func(app types.Appearance, value *types.Transaction) error {
+			if tx, err := opts.Conn.GetTransactionByAppearance(&app, false); err != nil {
+				return err
+			} else {
+				passes, _ := sOpts.filter.ApplyTxFilters(tx)
+				if passes {
+					*value = *tx
+				}
+				if bar != nil {
+					bar.Tick()
+				}
+				return nil
+			}
+		}
+
+		// Set up and interate over the map calling iterFunc for each appearance
+		iterCtx, iterCancel := context.WithCancel(context.Background())
+		errChan := make(chan error)
+		go utils.IterateOverMap(iterCtx, errChan, thisMap, iterFunc)
+		stepErr := <-errChan
+		iterCancel()
+		if stepErr != nil {
+			return cnt, stepErr
+		}
+
+		txArray := make([]*types.Transaction, 0, len(thisMap))
+		for _, tx := range thisMap {
+			txArray = append(txArray, tx)
+		}
+
+		sort.Slice(txArray, func(i, j int) bool {
+			if txArray[i].BlockNumber == txArray[j].BlockNumber {
+				return txArray[i].TransactionIndex < txArray[j].TransactionIndex
+			}
+			return txArray[i].BlockNumber < txArray[j].BlockNumber
+		})
+
+		pageApps := make([]types.Appearance, 0, len(thisMap))
+		for _, tx := range txArray {
+			pageApps = append(pageApps, types.Appearance{
+				BlockNumber:      uint32(tx.BlockNumber),
+				TransactionIndex: uint32(tx.TransactionIndex),
+			})
+		}
+
+		ledgers := ledger.NewLedger(
+			opts.Conn,
+			mon.Address,
+			opts.FirstBlock,
+			opts.LastBlock,
+			opts.Globals.Ether,
+			opts.Globals.TestMode,
+			sOpts.noZero,
+			opts.Traces,
+			sOpts.reversed,
+			&opts.Asset,
+		)
+		ledgers.FiatCurrency = opts.Currency
+		ledgers.Nfts = opts.Nfts
+		_ = ledgers.SetContexts(opts.Globals.Chain, pageApps)
+
+		items := make([]types.Statement, 0, len(thisMap))
+		for _, tx := range txArray {
+			if statements, err := ledgers.GetStatements(opts.Conn, sOpts.filter, tx); err != nil {
+				errorChan <- err
+
+			} else if len(statements) > 0 {
+				items = append(items, statements...)
+			}
+		}
+
+		sort.Slice(items, func(i, j int) bool {
+			if sOpts.reversed {
+				i, j = j, i
+			}
+			if items[i].BlockNumber == items[j].BlockNumber {
+				if items[i].TransactionIndex == items[j].TransactionIndex {
+					return items[i].LogIndex < items[j].LogIndex
+				}
+				return items[i].TransactionIndex < items[j].TransactionIndex
+			}
+			return items[i].BlockNumber < items[j].BlockNumber
+		})
+
+		if !visit(ledgers, items) {
+			break
+		}
+	}
+
+	return cnt, nil
+}
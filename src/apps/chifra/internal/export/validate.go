@@ -12,7 +12,10 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/ledger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/pricing"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
 )
 
@@ -61,6 +64,54 @@ func (opts *ExportOptions) validateExport() error {
 		}
 	}
 
+	if len(opts.Cursor) > 0 {
+		if !monitor.IsValidCursorName(opts.Cursor) {
+			return validate.Usage("The {0} option ({1}) may contain only letters, digits, dashes, and underscores.", "--cursor", opts.Cursor)
+		}
+		if opts.Count || opts.Globals.Decache {
+			return validate.Usage("The {0} option is not available{1}.", "--cursor", " with --count or --decache")
+		}
+		// Gains need every earlier acquisition, entities consolidate several addresses, and a
+		// journal is written whole, so none may resume from the cursor of a single address
+		if opts.Gains || len(opts.Entity) > 0 || len(opts.Journal) > 0 {
+			return validate.Usage("The {0} option is not available{1}.", "--cursor", " with --gains, --entity, or --journal")
+		}
+		if opts.FirstRecord != 0 || opts.MaxRecords != 250 {
+			return validate.Usage("The {0} option is not available{1}.", "--cursor", " with --first_record or --max_records")
+		}
+	}
+
+	if len(opts.Entity) > 0 {
+		if !opts.Accounting {
+			return validate.Usage("The {0} option is only available with the {1} option.", "--entity", "--accounting")
+		}
+
+		if err := validate.ValidateEnum("--intra", opts.Intra, "[eliminate|flag]"); err != nil {
+			return err
+		}
+
+		entity, err := ledger.LoadEntity(chain, opts.Entity)
+		if err != nil {
+			return validate.Usage("The {0} option ({1}) is invalid: {2}", "--entity", opts.Entity, err.Error())
+		}
+		opts.entity = entity
+
+		// The members are exported whether or not they are given, but other addresses would be
+		// silently dropped from the consolidated statements
+		have := make(map[string]bool, len(opts.Addrs))
+		for _, addr := range opts.Addrs {
+			if base.IsValidAddress(addr) && !entity.IsMember(base.HexToAddress(addr)) {
+				return validate.Usage("The address {0} is not a member of the entity {1}.", addr, entity.Name)
+			}
+			have[strings.ToLower(addr)] = true
+		}
+		for _, member := range entity.Members {
+			if !have[member.Hex()] {
+				opts.Addrs = append(opts.Addrs, member.Hex())
+			}
+		}
+	}
+
 	if err := validate.ValidateAtLeastOneAddr(opts.Addrs); err != nil {
 		for _, a := range opts.Addrs {
 			if !base.IsValidAddress(a) {
@@ -152,7 +203,7 @@ func (opts *ExportOptions) validateExport() error {
 	}
 
 	if opts.Accounting {
-		if len(opts.Addrs) != 1 {
+		if len(opts.Addrs) != 1 && len(opts.Entity) == 0 {
 			return validate.Usage("The {0} option is allows with only a single address.", "--accounting")
 		}
 
@@ -164,6 +215,28 @@ func (opts *ExportOptions) validateExport() error {
 			logger.Warn("The --accounting option reports a spotPrice of one for all assets on non-mainnet chains.")
 		}
 
+		if opts.Diagnose {
+			opts.Statements = true
+		}
+
+		if len(opts.Entity) > 0 {
+			if opts.Diagnose || opts.Gains || len(opts.Period) > 0 {
+				return validate.Usage("The {0} option is not available{1}.", "--entity", " with the --diagnose or --gains options")
+			}
+			opts.Statements = true
+		}
+
+		if len(opts.Journal) > 0 {
+			if err := validate.ValidateEnum("--journal", opts.Journal, "[beancount|hledger|ledger]"); err != nil {
+				return err
+			}
+
+			if opts.Diagnose {
+				return validate.Usage("The {0} option is not available{1}.", "--journal", " with the --diagnose option")
+			}
+			opts.Statements = true
+		}
+
 		if len(opts.Flow) > 0 {
 			if err := validate.ValidateEnum("--flow", opts.Flow, "[in|out|zero]"); err != nil {
 				return err
@@ -174,6 +247,37 @@ func (opts *ExportOptions) validateExport() error {
 			}
 		}
 
+		if len(opts.Period) > 0 {
+			if err := validate.ValidateEnum("--period", opts.Period, "[daily|monthly|quarterly|yearly]"); err != nil {
+				return err
+			}
+			opts.Gains = true
+		}
+
+		if opts.Gains {
+			if err := validate.ValidateEnum("--cost_basis", opts.CostBasis, "[fifo|lifo|hifo|average]"); err != nil {
+				return err
+			}
+
+			if opts.Statements {
+				return validate.Usage("The {0} option is not available{1}.", "--gains", " with the --statements option")
+			}
+
+			if opts.Reversed {
+				return validate.Usage("The {0} option is not available{1}.", "--gains", " with the --reversed option")
+			}
+		}
+
+		if len(opts.Currency) > 0 {
+			opts.Currency = strings.ToUpper(opts.Currency)
+			if len(opts.Currency) != 3 {
+				return validate.Usage("The {0} option requires a three-letter currency code such as {1}.", "--currency", "EUR")
+			}
+			if err := pricing.HasFxCurrency(opts.Currency); err != nil {
+				return err
+			}
+		}
+
 	} else {
 		if opts.Statements {
 			return validate.Usage("The {0} option is only available with the {1} option.", "--statements", "--accounting")
@@ -182,12 +286,32 @@ func (opts *ExportOptions) validateExport() error {
 		if opts.Globals.Format == "ofx" {
 			return validate.Usage("The {0} option is only available with the {1} option.", "--fmt ofx", "--accounting")
 		}
+
+		if len(opts.Currency) > 0 {
+			return validate.Usage("The {0} option is only available with the {1} option.", "--currency", "--accounting")
+		}
+
+		if opts.Gains || len(opts.Period) > 0 {
+			return validate.Usage("The {0} option is only available with the {1} option.", "--gains", "--accounting")
+		}
+
+		if opts.Diagnose {
+			return validate.Usage("The {0} option is only available with the {1} option.", "--diagnose", "--accounting")
+		}
+
+		if len(opts.Journal) > 0 {
+			return validate.Usage("The {0} option is only available with the {1} option.", "--journal", "--accounting")
+		}
 	}
 
 	if len(opts.Asset) > 0 && !opts.Statements {
 		return validate.Usage("The {0} option is only available with the {1} option.", "--asset", "--statements")
 	}
 
+	if opts.Nfts && !opts.Statements && !opts.Gains {
+		return validate.Usage("The {0} option is only available with the {1} option.", "--nfts", "--statements")
+	}
+
 	if !validate.HasArticulationKey(opts.Articulate) {
 		return validate.Usage("The {0} option requires an Etherscan API key.", "--articulate")
 	}
@@ -16,6 +16,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/progress"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/walk"
@@ -34,6 +35,7 @@ type GlobalOptions struct {
 	Decache bool            `json:"decache,omitempty"`
 	Caps    caps.Capability `json:"-"`
 	output.OutputOptions
+	reporter progress.Reporter
 }
 
 func (opts *GlobalOptions) TestLog() {
@@ -56,6 +58,8 @@ func (opts *GlobalOptions) TestLog() {
 	logger.TestLog(opts.Decache, "Decache: ", opts.Decache)
 	logger.TestLog(cc != caps.Default, "Caps: ", opts.Caps.Show())
 	logger.TestLog(len(opts.Format) > 0, "Format: ", opts.Format)
+	logger.TestLog(len(opts.Fields) > 0, "Fields: ", opts.Fields)
+	logger.TestLog(len(opts.Where) > 0, "Where: ", opts.Where)
 	// logger.TestLog(opts.TestMode, "TestMode: ", opts.TestMode)
 }
 
@@ -91,7 +95,9 @@ func InitGlobals(whoAmI string, cmd *cobra.Command, opts *GlobalOptions, c caps.
 	}
 
 	if opts.Caps.Has(caps.Fmt) {
-		cmd.Flags().StringVarP(&opts.Format, "fmt", "x", "", "export format, one of [none|json*|txt|csv]")
+		cmd.Flags().StringVarP(&opts.Format, "fmt", "x", "", "export format, one of [none|json*|txt|csv|parquet|sqlite]")
+		cmd.Flags().StringVarP(&opts.Fields, "fields", "", "", "output only these comma-separated fields (or dotted paths or arithmetic expressions) in this order")
+		cmd.Flags().StringVarP(&opts.Where, "where", "", "", "output only the records matching this expression (for example, 'value > 1e18 && to in @exchanges')")
 	}
 
 	if opts.Caps.Has(caps.Verbose) {
@@ -145,6 +151,8 @@ func InitGlobals(whoAmI string, cmd *cobra.Command, opts *GlobalOptions, c caps.
 
 func (opts *GlobalOptions) FinishParseApi(w io.Writer, values url.Values, caches map[walk.CacheType]bool) *rpc.Connection {
 	opts.Writer = w
+	// a server job's writer takes the progress of the route it runs
+	opts.reporter, _ = w.(progress.Reporter)
 
 	for key, value := range values {
 		switch key {
@@ -160,6 +168,8 @@ func (opts *GlobalOptions) FinishParseApi(w io.Writer, values url.Values, caches
 			opts.Ether = true
 		case "file":
 			opts.File = value[0]
+		case "fields":
+			opts.Fields = value[0]
 		case "fmt":
 			opts.Format = value[0]
 		case "nocolor":
@@ -176,6 +186,8 @@ func (opts *GlobalOptions) FinishParseApi(w io.Writer, values url.Values, caches
 			opts.Version = true
 		case "wei":
 			opts.Wei = true
+		case "where":
+			opts.Where = value[0]
 		case "testRunner":
 			opts.TestMode = true
 			colors.ColorsOff()
@@ -188,7 +200,7 @@ func (opts *GlobalOptions) FinishParseApi(w io.Writer, values url.Values, caches
 			parts := strings.Split(opts.OutputFn, ".")
 			if len(parts) > 0 {
 				last := parts[len(parts)-1]
-				if last == "txt" || last == "csv" || last == "json" {
+				if last == "txt" || last == "csv" || last == "json" || last == "parquet" {
 					opts.Format = last
 				}
 			}
@@ -213,16 +225,31 @@ func (opts *GlobalOptions) FinishParseApi(w io.Writer, values url.Values, caches
 	}
 }
 
-func (opts *GlobalOptions) FinishParse(args []string, caches map[walk.CacheType]bool) *rpc.Connection {
+// SetFormatFromOutput sets the format from --output if --fmt is not present. An --output of the
+// form sqlite://path/to/db.sqlite (or a file with a .sqlite extension) selects the sqlite format.
+// Otherwise the format is the --output file's extension if it is a known format.
+func (opts *GlobalOptions) SetFormatFromOutput() {
+	if strings.HasPrefix(opts.OutputFn, sqliteScheme) {
+		opts.OutputFn = strings.TrimPrefix(opts.OutputFn, sqliteScheme)
+		opts.Format = "sqlite"
+		return
+	}
+
 	if (len(opts.Format) == 0 || opts.Format == "none") && len(opts.OutputFn) > 0 {
 		parts := strings.Split(opts.OutputFn, ".")
 		if len(parts) > 0 {
 			last := parts[len(parts)-1]
-			if last == "txt" || last == "csv" || last == "json" {
+			if last == "txt" || last == "csv" || last == "json" || last == "parquet" || last == "sqlite" {
 				opts.Format = last
 			}
 		}
 	}
+}
+
+const sqliteScheme = "sqlite://"
+
+func (opts *GlobalOptions) FinishParse(args []string, caches map[walk.CacheType]bool) *rpc.Connection {
+	opts.SetFormatFromOutput()
 
 	if len(opts.Chain) == 0 {
 		opts.Chain = config.GetSettings().DefaultChain
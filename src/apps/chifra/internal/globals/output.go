@@ -5,6 +5,8 @@
 package globals
 
 import (
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/expr"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/names"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
@@ -38,6 +40,8 @@ func (opts *GlobalOptions) OutputOpts() output.OutputOptions {
 		Append:     opts.Append,
 		JsonIndent: "  ",
 		Extra:      extraOpts,
+		Fields:     opts.Fields,
+		Where:      opts.Where,
 	}
 }
 
@@ -67,9 +71,25 @@ func (opts *GlobalOptions) OutputOptsWithExtra(extraOpts map[string]any) output.
 		Append:     opts.Append,
 		JsonIndent: "  ",
 		Extra:      extraOpts,
+		Fields:     opts.Fields,
+		Where:      opts.Where,
 	}
 }
 
+// OutputOptsWithWhere returns the options of OutputOptsWithExtra for commands that count their
+// records with the filter's ApplyModelFilter. The filter tests --where before a record is counted
+// toward --first_record and --max_records, so the returned options do not test it again.
+func (opts *GlobalOptions) OutputOptsWithWhere(filter *filter.AppearanceFilter, extraOpts map[string]any) (output.OutputOptions, error) {
+	outputOpts := opts.OutputOptsWithExtra(extraOpts)
+	where, err := expr.LoadWhere(opts.Where, opts.Chain)
+	if err != nil {
+		return outputOpts, err
+	}
+	filter.SetWhere(where, outputOpts.Chain, outputOpts.Format, outputOpts.Verbose, outputOpts.Extra)
+	outputOpts.Where = ""
+	return outputOpts, nil
+}
+
 func (opts *GlobalOptions) ShowProgress() bool {
 	if opts.TestMode || utils.IsFuzzing() {
 		return false
@@ -80,3 +100,12 @@ func (opts *GlobalOptions) ShowProgress() bool {
 func (opts *GlobalOptions) ShowProgressNotTesting() bool {
 	return !opts.TestMode && !utils.IsFuzzing()
 }
+
+// ProgressReport returns the function to which progress bars report if the command is run as a
+// server job (nil otherwise)
+func (opts *GlobalOptions) ProgressReport() func(msg string) {
+	if opts.reporter == nil {
+		return nil
+	}
+	return opts.reporter.ReportProgress
+}
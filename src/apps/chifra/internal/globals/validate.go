@@ -5,8 +5,10 @@
 package globals
 
 import (
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/expr"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
 )
 
@@ -38,11 +40,29 @@ func (opts *GlobalOptions) Validate() error {
 	// 	}
 	// }
 
-	err := validate.ValidateEnum("--fmt", opts.Format, "[json|txt|csv]")
+	err := validate.ValidateEnum("--fmt", opts.Format, "[json|txt|csv|parquet|sqlite]")
 	if err != nil {
 		return err
 	}
 
+	if (opts.Format == "parquet" || opts.Format == "sqlite") && len(opts.OutputFn) == 0 {
+		return validate.Usage("The {0} option requires {1}.", "--fmt "+opts.Format, "--output")
+	}
+
+	if opts.Format == "parquet" {
+		if opts.Append {
+			return validate.Usage("The {0} option is not available{1}.", "--append", " with --fmt parquet")
+		}
+	}
+
+	if _, err := output.ParseFields(opts.Fields); err != nil {
+		return validate.Usage("The {0} option is invalid: {1}", "--fields", err.Error())
+	}
+
+	if _, err := expr.ParseWhere(opts.Where); err != nil {
+		return validate.Usage("The {0} option is invalid: {1}", "--where", err.Error())
+	}
+
 	// TODO: This hack is here to make test cases pass. It can be removed at some point
 	if opts.Format == "json" && len(opts.OutputFn) > 0 && opts.TestMode {
 		logger.Info("{ \"outputFilename\": \"--output_filename--\" }")
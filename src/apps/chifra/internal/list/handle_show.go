@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/expr"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
@@ -28,6 +29,13 @@ func (opts *ListOptions) HandleShow(rCtx *output.RenderCtx, monitorArray []monit
 		base.BlockRange{First: opts.FirstBlock, Last: opts.LastBlock},
 		base.RecordRange{First: opts.FirstRecord, Last: opts.GetMax()},
 	)
+	// Appearances are counted as they are read, before their records are built, so the filter
+	// only skips those whose records could never pass --where. The stream tests the records.
+	where, err := expr.ParseWhere(opts.Globals.Where)
+	if err != nil {
+		return err
+	}
+	filter.SetWhere(where, chain, opts.Globals.Format, opts.Globals.Verbose, nil)
 
 	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
 		currentBn := uint32(0)
@@ -50,6 +58,9 @@ func (opts *ListOptions) HandleShow(rCtx *output.RenderCtx, monitorArray []monit
 				continue // on error
 			} else if !opts.NoZero || cnt > 0 {
 				for _, app := range apps {
+					if rCtx.WasCanceled() {
+						return
+					}
 					if err := visitAppearance(&app); err != nil {
 						errorChan <- err
 						return
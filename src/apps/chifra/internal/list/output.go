@@ -35,7 +35,7 @@ func RunList(cmd *cobra.Command, args []string) error {
 // ServeList handles the list command for the API. Returns an error.
 func ServeList(w http.ResponseWriter, r *http.Request) error {
 	opts := listFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("list", w, &opts.Globals)
@@ -34,7 +34,7 @@ func RunLogs(cmd *cobra.Command, args []string) error {
 // ServeLogs handles the logs command for the API. Returns an error.
 func ServeLogs(w http.ResponseWriter, r *http.Request) error {
 	opts := logsFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("logs", w, &opts.Globals)
@@ -38,6 +38,13 @@ func (opts *MonitorsOptions) HandleWatch(rCtx *output.RenderCtx) error {
 	return nil
 }
 
+// NewWatcher returns a watcher that freshens the monitors in the watchlist (and runs the commands
+// for any with new appearances) each time it is woken or, failing that, every --sleep seconds
+func (opts *MonitorsOptions) NewWatcher() *monitor.Watcher {
+	interval := time.Duration(opts.Sleep*1000) * time.Millisecond
+	return monitor.NewWatcher(interval, opts.getMonitorList, opts.Refresh)
+}
+
 // RunMonitorScraper runs continually, never stopping and freshens any existing monitors
 func (opts *MonitorsOptions) RunMonitorScraper(wg *sync.WaitGroup, s *Scraper) {
 	defer wg.Done()
@@ -323,7 +330,11 @@ func (opts *MonitorsOptions) getMonitorList() []monitor.Monitor {
 	var monitors []monitor.Monitor
 
 	monitorChan := make(chan monitor.Monitor)
-	go monitor.ListWatchedMonitors(opts.Globals.Chain, opts.Watchlist, monitorChan)
+	if len(opts.Watchlist) == 0 || opts.Watchlist == "existing" {
+		go monitor.ListExistingMonitors(opts.Globals.Chain, monitorChan)
+	} else {
+		go monitor.ListWatchedMonitors(opts.Globals.Chain, opts.Watchlist, monitorChan)
+	}
 
 	for result := range monitorChan {
 		switch result.Address {
@@ -36,7 +36,7 @@ func RunMonitors(cmd *cobra.Command, args []string) error {
 // ServeMonitors handles the monitors command for the API. Returns an error.
 func ServeMonitors(w http.ResponseWriter, r *http.Request) error {
 	opts := monitorsFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// TODO: can we move this to Validate?
 	var err1 error
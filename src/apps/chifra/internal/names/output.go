@@ -38,7 +38,7 @@ func RunNames(cmd *cobra.Command, args []string) error {
 // ServeNames handles the names command for the API. Returns an error.
 func ServeNames(w http.ResponseWriter, r *http.Request) error {
 	opts := namesFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	var err1 error
 	if err1 = opts.LoadCrudDataIfNeeded(r); err1 != nil {
@@ -34,7 +34,7 @@ func RunReceipts(cmd *cobra.Command, args []string) error {
 // ServeReceipts handles the receipts command for the API. Returns an error.
 func ServeReceipts(w http.ResponseWriter, r *http.Request) error {
 	opts := receiptsFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("receipts", w, &opts.Globals)
@@ -96,6 +96,9 @@ func (opts *ScrapeOptions) HandleScrape(rCtx *output.RenderCtx) error {
 		return err
 	}
 
+	// Remembers the hashes of the blocks we've scraped so we can detect reorgs
+	hashes := newReorgTracker()
+
 	runCount := uint64(0)
 	// Loop until the user hits Cntl+C, until runCount runs out, or until
 	// the server tells us to stop.
@@ -117,6 +120,27 @@ func (opts *ScrapeOptions) HandleScrape(rCtx *output.RenderCtx) error {
 			goto PAUSE
 		}
 
+		// If the chain has re-orged underneath blocks we've already written (but not yet
+		// finalized), roll back to the fork and re-fetch the meta data before continuing.
+		hashes.prune(bm.meta.Finalized)
+		if reorg, err := hashes.detect(opts.Conn); err != nil {
+			logger.Error(colors.BrightRed+"error checking for reorgs:", err, colors.Off)
+			goto PAUSE
+		} else if reorg != nil {
+			bm.opts = opts
+			bm.hashes = hashes
+			if err = bm.HandleReorg(reorg); err != nil {
+				logger.Error(colors.BrightRed+err.Error(), colors.Off)
+				if _, critical := err.(*criticalError); critical {
+					break
+				}
+				goto PAUSE
+			}
+			if bm.meta, err = opts.Conn.GetMetaData(testMode); err != nil {
+				goto PAUSE
+			}
+		}
+
 		// This only happens if the chain and the index scraper are both started at the
 		// same time (rarely). This protects against the case where the chain has no ripe blocks.
 		// Report no error and sleep for a while.
@@ -148,6 +172,7 @@ func (opts *ScrapeOptions) HandleScrape(rCtx *output.RenderCtx) error {
 			meta:         bm.meta,
 			nChannels:    int(opts.Settings.ChannelCount),
 			isHeadless:   isHeadless,
+			hashes:       hashes,
 		}
 
 		// Order dependant, be careful!
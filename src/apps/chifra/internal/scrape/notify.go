@@ -37,20 +37,26 @@ func Notify[T notify.NotificationPayload](notification notify.Notification[T]) e
 	if endpoint == "" {
 		return nil
 	}
-	return notifyEndpoint(endpoint, notification)
+	return notifyEndpoint(endpoint, config.GetSettings().Notify.ApiKey, notification)
 }
 
-func notifyEndpoint(endpoint string, notification any) error {
+func notifyEndpoint(endpoint, apiKey string, notification any) error {
 	encoded, err := json.Marshal(notification)
 	if err != nil {
 		return fmt.Errorf("marshalling message: %w", err)
 	}
 
-	resp, err := http.Post(
-		endpoint,
-		"application/json",
-		bytes.NewReader(encoded),
-	)
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(encoded))
+	if err != nil {
+		return fmt.Errorf("sending notification: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		// for a listener such as chifra daemon that requires an API key
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 
 	if err != nil {
 		if errors.Is(err, syscall.ECONNREFUSED) {
@@ -41,7 +41,7 @@ func TestNotify(t *testing.T) {
 		},
 	}
 
-	if err := notifyEndpoint(ts.URL, newAppNotification); err != nil {
+	if err := notifyEndpoint(ts.URL, "", newAppNotification); err != nil {
 		t.Fatal(err)
 	}
 
@@ -35,7 +35,7 @@ func RunScrape(cmd *cobra.Command, args []string) error {
 // ServeScrape handles the scrape command for the API. Returns an error.
 func ServeScrape(w http.ResponseWriter, r *http.Request) error {
 	opts := scrapeFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("scrape", w, &opts.Globals)
@@ -14,6 +14,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/notify"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/uniq"
@@ -86,16 +87,40 @@ func (bm *BlazeManager) HandleBlaze(ctx context.Context, blocks []base.Blknum) (
 func (bm *BlazeManager) ProcessBlocks(blockChannel chan base.Blknum, blockWg *sync.WaitGroup, appearanceChannel chan scrapedData) (err error) {
 	defer blockWg.Done()
 	for bn := range blockChannel {
+		// Without the header, we'd record a zero hash for the block which the reorg tracker would
+		// later mistake for a reorg, so we skip the block (it will be retried on the next pass).
+		// If the block may hold EIP-7702 authorization lists, we need its transactions, so we
+		// fetch the whole block once and use it for both.
+		var hash base.Hash
+		var ts base.Timestamp
+		var authorizations []types.Transaction
+		if bm.opts.Conn.MayHaveAuthorizations(bn) {
+			block, err := bm.opts.Conn.GetBlockBodyByNumber(bn)
+			if err != nil {
+				bm.errors = append(bm.errors, scrapeError{block: bn, err: err})
+				continue
+			}
+			hash, ts, authorizations = block.Hash, block.Timestamp, rpc.GetAuthorizations(&block)
+		} else {
+			header, err := bm.opts.Conn.GetBlockHeaderByNumber(bn)
+			if err != nil {
+				bm.errors = append(bm.errors, scrapeError{block: bn, err: err})
+				continue
+			}
+			hash, ts = header.Hash, header.Timestamp
+		}
+
 		sd := scrapedData{
-			bn: bn,
+			bn:             bn,
+			hash:           hash,
+			authorizations: authorizations,
 			ts: tslib.TimestampRecord{
 				Bn: uint32(bn),
-				Ts: uint32(bm.opts.Conn.GetBlockTimestamp(bn)),
+				Ts: uint32(ts),
 			},
 		}
 
 		// TODO: BOGUS - we should send in an errorChannel and send the error down that channel and continue here
-		var err error
 		if sd.traces, err = bm.opts.Conn.GetTracesByBlockNumber(bn); err != nil {
 			bm.errors = append(bm.errors, scrapeError{block: bn, err: err})
 		} else if sd.receipts, _, err = bm.opts.Conn.GetReceiptsByNumber(bn, base.Timestamp(sd.ts.Ts)); err != nil {
@@ -126,10 +151,15 @@ func (bm *BlazeManager) ProcessAppearances(appearanceChannel chan scrapedData, a
 		} else if err = uniq.UniqFromWithdrawals(bm.chain, sData.withdrawals, sData.bn, addrMap); err != nil {
 			bm.errors = append(bm.errors, scrapeError{block: sData.bn, err: err})
 
+		} else if err = uniq.UniqFromAuthorizations(bm.chain, sData.authorizations, addrMap); err != nil {
+			bm.errors = append(bm.errors, scrapeError{block: sData.bn, err: err})
+
 		} else {
 			_ = uniq.AddMiner(bm.chain, sData.miner, sData.bn, addrMap)
 			if err = bm.WriteAppearances(sData.bn, addrMap); err != nil {
 				bm.errors = append(bm.errors, scrapeError{block: sData.bn, err: err})
+			} else {
+				bm.hashes.record(sData.bn, sData.hash)
 			}
 		}
 		tsChannel <- sData.ts
@@ -199,16 +229,20 @@ func (bm *BlazeManager) WriteAppearances(bn base.Blknum, addrMap uniq.AddressBoo
 		}
 	}
 
-	if bm.opts.Notify && bn <= bm.ripeBlock {
-		err = Notify(notify.Notification[[]notify.NotificationPayloadAppearance]{
+	if bn <= bm.ripeBlock {
+		notification := notify.Notification[[]notify.NotificationPayloadAppearance]{
 			Msg:     notify.MessageAppearance,
 			Meta:    bm.meta,
 			Payload: notificationPayload,
-		})
-		if err != nil {
-			// We need this warning, otherwise errors don't show up for 2,000 blocks
-			logger.Error("error sending notification", err)
-			return err
+		}
+		// Listeners in this process (such as the daemon's websocket subscribers) hear of every ripe block
+		notify.Publish(notification)
+		if bm.opts.Notify {
+			if err = Notify(notification); err != nil {
+				// We need this warning, otherwise errors don't show up for 2,000 blocks
+				logger.Error("error sending notification", err)
+				return err
+			}
 		}
 	}
 
@@ -261,10 +295,12 @@ func (bm *BlazeManager) syncedReporting(bn base.Blknum, force bool) {
 // scrapedData combines the extracted block data, trace data, and log data into a
 // structure that is passed through to the AddressChannel for further processing.
 type scrapedData struct {
-	bn          base.Blknum
-	ts          tslib.TimestampRecord
-	traces      []types.Trace
-	receipts    []types.Receipt
-	withdrawals []types.Withdrawal
-	miner       base.Address
+	bn             base.Blknum
+	hash           base.Hash
+	ts             tslib.TimestampRecord
+	traces         []types.Trace
+	receipts       []types.Receipt
+	withdrawals    []types.Withdrawal
+	authorizations []types.Transaction
+	miner          base.Address
 }
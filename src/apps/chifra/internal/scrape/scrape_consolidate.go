@@ -154,12 +154,14 @@ func (bm *BlazeManager) Consolidate(ctx context.Context, blocks []base.Blknum) e
 	nAppsNow := int(file.FileSize(stageFn) / asciiAppearanceSize)
 	bm.report(len(blocks), int(bm.PerChunk()), nChunks, nAppsNow, nAppsFound, nAddrsFound)
 
+	notification := notify.Notification[string]{
+		Msg:     notify.MessageStageUpdated,
+		Meta:    bm.meta,
+		Payload: newRange.String(),
+	}
+	notify.Publish(notification)
 	if bm.opts.Notify {
-		if err := Notify(notify.Notification[string]{
-			Msg:     notify.MessageStageUpdated,
-			Meta:    bm.meta,
-			Payload: newRange.String(),
-		}); err != nil {
+		if err := Notify(notification); err != nil {
 			return err
 		}
 	}
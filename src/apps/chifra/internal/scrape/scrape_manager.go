@@ -27,6 +27,7 @@ type BlazeManager struct {
 	nChannels    int
 	errors       []scrapeError
 	isHeadless   bool
+	hashes       *reorgTracker
 }
 
 type scrapeError struct {
@@ -0,0 +1,259 @@
+package scrapePkg
+
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/notify"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
+)
+
+// blockHasher is the part of the RPC connection the reorg detector needs. It's an interface
+// so that the tests may replace the node with a stand-in.
+type blockHasher interface {
+	GetBlockHashByNumber(bn base.Blknum) (base.Hash, error)
+}
+
+// reorgTracker remembers the hash of every non-finalized block the scraper visited. The
+// tracker lives for the life of the forever loop, so each pass may check that the chain
+// did not change underneath blocks written (to unripe or staging) by earlier passes.
+type reorgTracker struct {
+	hashes map[base.Blknum]base.Hash
+	mutex  sync.Mutex
+}
+
+// reorgReport describes a detected reorg. Block is the first block whose hash changed.
+type reorgReport struct {
+	block   base.Blknum
+	oldHash base.Hash
+	newHash base.Hash
+}
+
+func newReorgTracker() *reorgTracker {
+	return &reorgTracker{
+		hashes: make(map[base.Blknum]base.Hash),
+	}
+}
+
+// record stores the hash of a block as it was seen while scraping.
+func (rt *reorgTracker) record(bn base.Blknum, hash base.Hash) {
+	if rt == nil {
+		return
+	}
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.hashes[bn] = hash
+}
+
+// forget removes every hash at or after the given block (used after a rollback).
+func (rt *reorgTracker) forget(from base.Blknum) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	for bn := range rt.hashes {
+		if bn >= from {
+			delete(rt.hashes, bn)
+		}
+	}
+}
+
+// prune removes hashes for blocks that have been consolidated into finalized chunks. We
+// no longer roll those back, so there's no reason to keep checking them.
+func (rt *reorgTracker) prune(finalized base.Blknum) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	for bn := range rt.hashes {
+		if bn <= finalized {
+			delete(rt.hashes, bn)
+		}
+	}
+}
+
+// detect asks the node for the current hash of the recorded blocks starting at the highest one
+// and walking backwards. The first block that still matches ends the search (the chain below it
+// is intact because each block commits to its parent). If nothing changed, detect returns nil.
+func (rt *reorgTracker) detect(conn blockHasher) (*reorgReport, error) {
+	rt.mutex.Lock()
+	blocks := make([]base.Blknum, 0, len(rt.hashes))
+	for bn := range rt.hashes {
+		blocks = append(blocks, bn)
+	}
+	rt.mutex.Unlock()
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i] > blocks[j]
+	})
+
+	var report *reorgReport
+	for _, bn := range blocks {
+		current, err := conn.GetBlockHashByNumber(bn)
+		if err != nil {
+			return nil, err
+		}
+		rt.mutex.Lock()
+		recorded := rt.hashes[bn]
+		rt.mutex.Unlock()
+		if current == recorded {
+			break
+		}
+		report = &reorgReport{
+			block:   bn,
+			oldHash: recorded,
+			newHash: current,
+		}
+	}
+
+	return report, nil
+}
+
+// HandleReorg rolls back everything the scraper wrote at or after the first re-orged block: the
+// ripe and unripe files, the stage, the timestamps database and any monitors that were freshened
+// past that point. Listeners are told about the reorg if --notify is on.
+func (bm *BlazeManager) HandleReorg(reorg *reorgReport) error {
+	chain := bm.chain
+	logger.Warn(fmt.Sprintf("reorg detected at block %d (%s ==> %s), rolling back", reorg.block, reorg.oldHash.Hex(), reorg.newHash.Hex()))
+
+	if reorg.block <= bm.meta.Finalized {
+		return NewCriticalError(fmt.Errorf("reorg at block %d reaches into the finalized index (%d)", reorg.block, bm.meta.Finalized))
+	}
+
+	if err := cleanEphemeralIndexFolders(chain); err != nil {
+		return err
+	}
+
+	tmpPath := filepath.Join(config.PathToCache(chain), "tmp")
+	if err := rollbackStage(tmpPath, bm.StageFolder(), reorg.block); err != nil {
+		return err
+	}
+
+	if err := tslib.Truncate(chain, reorg.block); err != nil {
+		return err
+	}
+
+	nMonitors, err := rollbackMonitors(chain, reorg.block)
+	if err != nil {
+		return err
+	}
+
+	bm.hashes.forget(reorg.block)
+	logger.Info(fmt.Sprintf("rolled back to block %d (%d monitors truncated)", reorg.block-1, nMonitors))
+
+	notification := *notify.NewReorgNotification(bm.meta, notify.NotificationPayloadReorg{
+		BlockNumber: fmt.Sprint(reorg.block),
+		OldHash:     reorg.oldHash.Hex(),
+		NewHash:     reorg.newHash.Hex(),
+	})
+	notify.Publish(notification)
+	if bm.opts.Notify {
+		return Notify(notification)
+	}
+
+	return nil
+}
+
+// rollbackStage removes appearances at or after block bn from the stage file (if any) and
+// renames the file to reflect its new range. If nothing remains, the stage file is removed.
+func rollbackStage(tmpPath, stageFolder string, bn base.Blknum) error {
+	stageFn, _ := file.LatestFileInFolder(stageFolder) // it may not exist...
+	if !file.FileExists(stageFn) {
+		return nil
+	}
+
+	rng, err := base.RangeFromFilenameE(stageFn)
+	if err != nil {
+		return err
+	} else if rng.Last < bn {
+		return nil
+	}
+
+	backup, err := file.MakeBackup(tmpPath, stageFn)
+	if err != nil {
+		return errors.New("Could not create backup file: " + err.Error())
+	}
+	defer func() {
+		backup.Restore()
+	}()
+
+	lines := file.AsciiFileToLines(stageFn)
+	keep := make([]string, 0, len(lines))
+	for _, line := range lines {
+		parts := strings.Split(line, "\t")
+		if len(parts) == 3 && base.MustParseBlknum(strings.TrimLeft(parts[1], "0")) < bn {
+			keep = append(keep, line)
+		}
+	}
+
+	if err := os.Remove(stageFn); err != nil {
+		return err
+	}
+
+	if len(keep) > 0 {
+		newRange := base.FileRange{First: rng.First, Last: bn - 1}
+		newFn := filepath.Join(stageFolder, fmt.Sprintf("%s.txt", newRange))
+		if err := file.LinesToAsciiFile(newFn, keep); err != nil {
+			os.Remove(newFn)
+			return err
+		}
+	}
+
+	backup.Clear()
+	return nil
+}
+
+// rollbackMonitors removes appearances at or after block bn from any monitor that was freshened
+// past that block and resets its lastScanned so the next freshen picks up the new chain.
+func rollbackMonitors(chain string, bn base.Blknum) (int, error) {
+	nMonitors := 0
+	truncateMonitor := func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, monitor.Ext) || strings.Contains(path, "staging") {
+			return nil
+		}
+
+		addr, _ := base.AddressFromPath(path, monitor.Ext)
+		if addr.IsZero() {
+			return nil
+		}
+
+		mon, _ := monitor.NewMonitor(chain, addr, false /* create */)
+		defer mon.Close()
+		if err := mon.ReadMonitorHeader(); err != nil {
+			return err
+		}
+		if base.Blknum(mon.LastScanned) <= bn {
+			return nil
+		}
+
+		if _, err := mon.TruncateTo(chain, uint32(bn-1)); err != nil {
+			return err
+		}
+		// lastScanned is the next block to visit, so we point it at the first re-orged block
+		if err := mon.WriteMonHeader(mon.Deleted, uint32(bn), true /* force */); err != nil {
+			return err
+		}
+		nMonitors++
+		return nil
+	}
+
+	monitorPath := filepath.Join(config.PathToCache(chain), "monitors")
+	if !file.FolderExists(monitorPath) {
+		return 0, nil
+	}
+	return nMonitors, filepath.Walk(monitorPath, truncateMonitor)
+}
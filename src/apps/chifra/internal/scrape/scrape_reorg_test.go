@@ -0,0 +1,347 @@
+package scrapePkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/notify"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// standInRpc is a stand-in for the node that serves block hashes from a map. It may be called
+// directly or, as a JSON-RPC endpoint, through an RPC connection.
+type standInRpc struct {
+	hashes   map[base.Blknum]base.Hash
+	failures map[base.Blknum]int
+	mutex    sync.Mutex
+}
+
+func (s *standInRpc) GetBlockHashByNumber(bn base.Blknum) (base.Hash, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.hashes[bn], nil
+}
+
+// swapTip replaces the hashes of every block at or after bn simulating a reorg
+func (s *standInRpc) swapTip(bn base.Blknum) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for b := range s.hashes {
+		if b >= bn {
+			s.hashes[b] = base.HexToHash(fmt.Sprintf("0x%064x", 0xdead0000+uint64(b)))
+		}
+	}
+}
+
+// ServeHTTP answers eth_getBlockByNumber with the block's hash and reports empty blocks to the
+// other queries the scraper makes. The first failures[bn] requests for a block's header fail, as
+// do the requests for blocks the stand-in does not know about.
+func (s *standInRpc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		ID     int    `json:"id"`
+		Method string `json:"method"`
+		Params []any  `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Params) == 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]any{"jsonrpc": "2.0", "id": request.ID}
+	bn := base.MustParseBlknum(fmt.Sprint(request.Params[0]))
+	s.mutex.Lock()
+	hash, ok := s.hashes[bn]
+	if ok && request.Method == "eth_getBlockByNumber" && s.failures[bn] > 0 {
+		s.failures[bn]--
+		ok = false
+	}
+	s.mutex.Unlock()
+
+	if !ok {
+		response["error"] = map[string]any{"code": -32000, "message": "header not found"}
+	} else if request.Method == "eth_getBlockByNumber" {
+		response["result"] = map[string]any{
+			"number":     fmt.Sprintf("0x%x", bn),
+			"hash":       hash.Hex(),
+			"parentHash": fmt.Sprintf("0x%064x", uint64(bn-1)),
+			"timestamp":  fmt.Sprintf("0x%x", 1700000000+12*bn),
+		}
+	} else {
+		response["result"] = []any{}
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// node is the stand-in behind the RPC connection of the tests that need one. The configuration
+// (which names the node's url) is read only once, so the node is shared by those tests.
+var node = newStandInRpc(100, 120)
+
+// TestMain points the configuration, and therefore the index, cache and RPC, at a temporary
+// folder and the stand-in node
+func TestMain(m *testing.M) {
+	server := httptest.NewServer(node)
+	folder, err := os.MkdirTemp("", "scrape")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(folder, "trueBlocks.toml"), []byte(fmt.Sprintf(testConfig, server.URL)), 0644)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	os.Setenv("XDG_CONFIG_HOME", folder)
+	os.Setenv("TB_NO_PROVIDER_CHECK", "true")
+
+	code := m.Run()
+	server.Close()
+	os.RemoveAll(folder)
+	os.Exit(code)
+}
+
+const testConfig = `[version]
+  current = "v2.0.0-release"
+
+[settings]
+  defaultChain = "mainnet"
+
+[chains]
+  [chains.mainnet]
+    chain = "mainnet"
+    chainId = "1"
+    rpcProvider = "%s"
+    symbol = "ETH"
+`
+
+func newStandInRpc(first, last base.Blknum) *standInRpc {
+	s := &standInRpc{hashes: make(map[base.Blknum]base.Hash), failures: make(map[base.Blknum]int)}
+	for bn := first; bn <= last; bn++ {
+		s.hashes[bn] = base.HexToHash(fmt.Sprintf("0x%064x", uint64(bn)))
+	}
+	return s
+}
+
+func TestReorgDetect(t *testing.T) {
+	rpc := newStandInRpc(100, 120)
+	tracker := newReorgTracker()
+	for bn := base.Blknum(100); bn <= 120; bn++ {
+		hash, _ := rpc.GetBlockHashByNumber(bn)
+		tracker.record(bn, hash)
+	}
+
+	if reorg, err := tracker.detect(rpc); err != nil {
+		t.Fatal(err)
+	} else if reorg != nil {
+		t.Fatal("unexpected reorg at block", reorg.block)
+	}
+
+	rpc.swapTip(115)
+	reorg, err := tracker.detect(rpc)
+	if err != nil {
+		t.Fatal(err)
+	} else if reorg == nil {
+		t.Fatal("expected a reorg, found none")
+	} else if reorg.block != 115 {
+		t.Fatal("wrong fork block", reorg.block)
+	}
+
+	expectedNew, _ := rpc.GetBlockHashByNumber(115)
+	if reorg.newHash != expectedNew || reorg.oldHash == reorg.newHash {
+		t.Fatal("wrong hashes", reorg.oldHash.Hex(), reorg.newHash.Hex())
+	}
+
+	tracker.forget(reorg.block)
+	if reorg, _ := tracker.detect(rpc); reorg != nil {
+		t.Fatal("reorg should have been forgotten", reorg.block)
+	}
+
+	tracker.prune(110)
+	if len(tracker.hashes) != 4 {
+		t.Fatal("wrong number of hashes after prune", len(tracker.hashes))
+	}
+}
+
+func TestRollbackStage(t *testing.T) {
+	tmpPath := t.TempDir()
+	stageFolder := filepath.Join(t.TempDir(), "staging")
+	if err := os.MkdirAll(stageFolder, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := []string{
+		"0x0000000000000000000000000000000000000001\t000000100\t00001",
+		"0x0000000000000000000000000000000000000001\t000000108\t00003",
+		"0x0000000000000000000000000000000000000002\t000000104\t00000",
+		"0x0000000000000000000000000000000000000002\t000000110\t00002",
+	}
+	stageFn := filepath.Join(stageFolder, "000000100-000000110.txt")
+	if err := file.LinesToAsciiFile(stageFn, lines); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rollbackStage(tmpPath, stageFolder, 108); err != nil {
+		t.Fatal(err)
+	}
+
+	if file.FileExists(stageFn) {
+		t.Fatal("old stage file should have been removed")
+	}
+	newFn := filepath.Join(stageFolder, "000000100-000000107.txt")
+	got := file.AsciiFileToLines(newFn)
+	if len(got) != 2 || got[0] != lines[0] || got[1] != lines[2] {
+		t.Fatal("wrong stage contents", got)
+	}
+
+	if err := rollbackStage(tmpPath, stageFolder, 100); err != nil {
+		t.Fatal(err)
+	}
+	if file.NFilesInFolder(stageFolder) != 0 {
+		t.Fatal("stage should be empty")
+	}
+}
+
+func TestHandleReorg(t *testing.T) {
+	chain := "mainnet"
+	conn := rpc.TempConnection(chain)
+	bm := BlazeManager{
+		chain:  chain,
+		opts:   &ScrapeOptions{Conn: conn},
+		meta:   &types.MetaData{Chain: chain, Finalized: 90},
+		hashes: newReorgTracker(),
+	}
+
+	// The scraper has written blocks 100 to 120 (the stage, an unripe block, the timestamps
+	// and a monitor freshened to the tip) and recorded their hashes as it went...
+	stageFn := filepath.Join(bm.StageFolder(), "000000100-000000116.txt")
+	unripeFn := filepath.Join(config.PathToIndex(chain), "unripe", "000000119.txt")
+	for _, fn := range []string{stageFn, unripeFn} {
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := []string{
+		"0x0000000000000000000000000000000000000001\t000000100\t00001",
+		"0x0000000000000000000000000000000000000001\t000000116\t00003",
+		"0x0000000000000000000000000000000000000002\t000000108\t00000",
+	}
+	if err := file.LinesToAsciiFile(stageFn, lines); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.LinesToAsciiFile(unripeFn, []string{"0x0000000000000000000000000000000000000001\t000000119\t00000"}); err != nil {
+		t.Fatal(err)
+	}
+
+	timestamps := []tslib.TimestampRecord{}
+	for bn := uint32(0); bn <= 120; bn++ {
+		timestamps = append(timestamps, tslib.TimestampRecord{Bn: bn, Ts: 1700000000 + 12*bn})
+	}
+	if err := tslib.Append(chain, timestamps); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := base.HexToAddress("0x0000000000000000000000000000000000000001")
+	mon, _ := monitor.NewMonitor(chain, addr, true /* create */)
+	apps := []types.AppRecord{{BlockNumber: 100, TransactionIndex: 1}, {BlockNumber: 116, TransactionIndex: 3}, {BlockNumber: 119}}
+	if _, err := mon.WriteAppearances(apps, false /* append */); err != nil {
+		t.Fatal(err)
+	}
+	if err := mon.WriteMonHeader(false, 121, true /* force */); err != nil {
+		t.Fatal(err)
+	}
+	mon.Close()
+
+	for bn := base.Blknum(100); bn <= 120; bn++ {
+		hash, err := conn.GetBlockHashByNumber(bn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bm.hashes.record(bn, hash)
+	}
+
+	// ...when the node's chain tip changes underneath it
+	var notified []notify.Message
+	remove := notify.AddListener(func(msg notify.Message, meta *types.MetaData, payload any) {
+		notified = append(notified, msg)
+	})
+	defer remove()
+
+	node.swapTip(115)
+	reorg, err := bm.hashes.detect(conn)
+	if err != nil {
+		t.Fatal(err)
+	} else if reorg == nil || reorg.block != 115 {
+		t.Fatal("expected a reorg at block 115, got", reorg)
+	}
+	if err := bm.HandleReorg(reorg); err != nil {
+		t.Fatal(err)
+	}
+
+	if file.FileExists(unripeFn) || file.FileExists(stageFn) {
+		t.Error("expected the unripe and stage files to be rolled back")
+	}
+	if got := file.AsciiFileToLines(filepath.Join(bm.StageFolder(), "000000100-000000114.txt")); len(got) != 2 {
+		t.Error("wrong stage contents", got)
+	}
+	if n, err := tslib.NTimestamps(chain); err != nil || n != 115 {
+		t.Error("expected the timestamps to be truncated to block 114, got", n, err)
+	}
+
+	mon, _ = monitor.NewMonitor(chain, addr, false /* create */)
+	if err := mon.ReadMonitorHeader(); err != nil {
+		t.Fatal(err)
+	}
+	if mon.LastScanned != 115 || mon.Count() != 1 {
+		t.Errorf("expected the monitor to be truncated, got lastScanned %d with %d appearances", mon.LastScanned, mon.Count())
+	}
+	mon.Close()
+
+	if len(notified) != 1 || notified[0] != notify.MessageReorg {
+		t.Error("expected a reorg notification, got", notified)
+	}
+	if reorg, _ := bm.hashes.detect(conn); reorg != nil {
+		t.Error("the rolled back blocks should have been forgotten", reorg.block)
+	}
+}
+
+func TestProcessBlocksHeaderError(t *testing.T) {
+	bm := BlazeManager{
+		chain:  "mainnet",
+		opts:   &ScrapeOptions{Conn: rpc.TempConnection("mainnet")},
+		hashes: newReorgTracker(),
+	}
+
+	// the node fails to deliver block 500's header, but answers the scraper's other queries
+	node.mutex.Lock()
+	node.hashes[500] = base.HexToHash("0x500")
+	node.failures[500] = 1
+	node.mutex.Unlock()
+
+	blockChannel := make(chan base.Blknum, 1)
+	appearanceChannel := make(chan scrapedData, 1)
+	blockChannel <- 500
+	close(blockChannel)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	_ = bm.ProcessBlocks(blockChannel, &wg, appearanceChannel)
+
+	if len(bm.errors) != 1 || bm.errors[0].block != 500 {
+		t.Fatal("expected an error for block 500, got", bm.errors)
+	}
+	if len(appearanceChannel) != 0 {
+		t.Fatal("a block without a header should not be processed")
+	}
+	if len(bm.hashes.hashes) != 0 {
+		t.Fatal("the hash of a block without a header should not be recorded")
+	}
+}
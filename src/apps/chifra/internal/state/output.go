@@ -34,7 +34,7 @@ func RunState(cmd *cobra.Command, args []string) error {
 // ServeState handles the state command for the API. Returns an error.
 func ServeState(w http.ResponseWriter, r *http.Request) error {
 	opts := stateFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("state", w, &opts.Globals)
@@ -10,6 +10,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/colors"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
@@ -100,6 +101,11 @@ func (opts *StatusOptions) GetStatus(diagnose bool) (*types.Status, error) {
 		// Ripe:      meta.Latest - meta.Ripe,
 	}
 
+	if watcher := monitor.ActiveWatcher(); watcher != nil {
+		status := watcher.Status()
+		s.Watcher = &status
+	}
+
 	if testMode {
 		s.ClientVersion = "Client version"
 		s.Version = "GHC-TrueBlocks//vers-beta--git-hash---git-ts-"
@@ -34,7 +34,7 @@ func RunTokens(cmd *cobra.Command, args []string) error {
 // ServeTokens handles the tokens command for the API. Returns an error.
 func ServeTokens(w http.ResponseWriter, r *http.Request) error {
 	opts := tokensFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("tokens", w, &opts.Globals)
@@ -34,7 +34,7 @@ func RunTraces(cmd *cobra.Command, args []string) error {
 // ServeTraces handles the traces command for the API. Returns an error.
 func ServeTraces(w http.ResponseWriter, r *http.Request) error {
 	opts := tracesFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("traces", w, &opts.Globals)
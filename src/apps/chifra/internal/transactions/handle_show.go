@@ -42,6 +42,7 @@ func (opts *TransactionsOptions) HandleShow(rCtx *output.RenderCtx) (err error)
 			showProgress := opts.Globals.ShowProgress()
 			bar := logger.NewBar(logger.BarOptions{
 				Enabled: showProgress,
+				Report:  opts.Globals.ProgressReport(),
 				Total:   int64(cnt),
 			})
 
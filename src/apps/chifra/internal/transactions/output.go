@@ -34,7 +34,7 @@ func RunTransactions(cmd *cobra.Command, args []string) error {
 // ServeTransactions handles the transactions command for the API. Returns an error.
 func ServeTransactions(w http.ResponseWriter, r *http.Request) error {
 	opts := transactionsFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("transactions", w, &opts.Globals)
@@ -34,6 +34,7 @@ func (opts *WhenOptions) HandleShow(rCtx *output.RenderCtx) error {
 			showProgress := opts.Globals.ShowProgress()
 			bar := logger.NewBar(logger.BarOptions{
 				Enabled: showProgress,
+				Report:  opts.Globals.ProgressReport(),
 				Total:   int64(len(blockNums)),
 			})
 
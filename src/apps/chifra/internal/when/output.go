@@ -34,7 +34,7 @@ func RunWhen(cmd *cobra.Command, args []string) error {
 // ServeWhen handles the when command for the API. Returns an error.
 func ServeWhen(w http.ResponseWriter, r *http.Request) error {
 	opts := whenFinishParseApi(w, r)
-	rCtx := output.NewRenderContext()
+	rCtx := output.NewRenderContextFrom(r.Context())
 	// EXISTING_CODE
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("when", w, &opts.Globals)
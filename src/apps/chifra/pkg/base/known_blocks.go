@@ -6,6 +6,8 @@ var (
 	London         = "london"
 	Merge          = "merge"
 	Shanghai       = "shanghai"
+	Cancun         = "cancun"
+	Prague         = "prague"
 	FirstTrace     = "first_trace"
 )
 
@@ -16,10 +18,14 @@ var knownBlocks = map[string]map[string]Blknum{
 		London:         12965000,
 		Merge:          15537393,
 		Shanghai:       17034870,
+		Cancun:         19426587,
+		Prague:         22431084,
 	},
 	"sepolia": {
 		Merge:    1450409,
 		Shanghai: 2990908,
+		Cancun:   5187023,
+		Prague:   7836331,
 	},
 	"optimism": {
 		FirstTrace: 105235063,
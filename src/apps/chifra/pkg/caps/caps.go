@@ -103,6 +103,10 @@ func (c Capability) HasKey(key string) bool {
 		return c.Has(Caching)
 	}
 
+	if key == "fields" || key == "where" {
+		return c.Has(Fmt)
+	}
+
 	for _, cap := range allCaps {
 		if key == cap.Text() {
 			return c.Has(cap)
@@ -121,10 +121,16 @@ func GetRootConfig() *configtypes.Config {
 		ch.IpfsGateway = strings.Replace(ch.IpfsGateway, "[{CHAIN}]", "ipfs", -1)
 		ch.LocalExplorer = clean(ch.LocalExplorer)
 		ch.RemoteExplorer = clean(ch.RemoteExplorer)
+		if len(ch.RpcProvider) == 0 && len(ch.RpcProviders) > 0 {
+			ch.RpcProvider = ch.RpcProviders[0].Url
+		}
 		ch.RpcProvider = strings.Trim(clean(ch.RpcProvider), "/") // Infura, for example, doesn't like the trailing slash
 		if err := validateRpcEndpoint(ch.Chain, ch.RpcProvider); err != nil {
 			logger.Fatal(err)
 		}
+		for i := range ch.RpcProviders {
+			ch.RpcProviders[i].Url = strings.Trim(clean(ch.RpcProviders[i].Url), "/")
+		}
 		ch.IpfsGateway = clean(ch.IpfsGateway)
 		if ch.Scrape.AppsPerChunk == 0 {
 			settings := configtypes.ScrapeSettings{
@@ -227,7 +233,7 @@ func validateRpcEndpoint(chain, provider string) error {
 func checkUnchainedProvider(chain string, deployed uint64) error {
 	// TODO: Clean this up
 	// TODO: We need to check that the unchained index has been deployed on the chain
-	if os.Getenv("TB_NO_PROVIDER_CHECK") == "true" {
+	if os.Getenv("TB_NO_PROVIDER_CHECK") == "true" || len(os.Getenv("TB_RPC_REPLAY")) > 0 {
 		logger.Info("Skipping rpcProvider check")
 		return nil
 	}
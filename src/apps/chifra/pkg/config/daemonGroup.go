@@ -0,0 +1,14 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package config
+
+import (
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+)
+
+// GetDaemon returns the settings of chifra daemon's API server
+func GetDaemon() configtypes.DaemonGroup {
+	return GetRootConfig().Settings.Daemon
+}
@@ -0,0 +1,38 @@
+package configtypes
+
+import "encoding/json"
+
+// DaemonGroup configures chifra daemon's API server. If CertFile and KeyFile are set, the server
+// serves HTTPS. If any Keys are listed, every request must carry one of them (except as allowed by
+// Anonymous). With no keys, the server is open to anyone who can reach it.
+type DaemonGroup struct {
+	CertFile string      `json:"certFile,omitempty" toml:"certFile,omitempty"`
+	KeyFile  string      `json:"keyFile,omitempty" toml:"keyFile,omitempty"`
+	Keys     []DaemonKey `json:"keys,omitempty" toml:"keys,omitempty"`
+	// Anonymous is the scope of requests without a key, either "none" (the default) or "read"
+	Anonymous string `json:"anonymous,omitempty" toml:"anonymous,omitempty"`
+	// AnonymousMaxRps limits the requests per second from each anonymous client (by IP address)
+	AnonymousMaxRps float64 `json:"anonymousMaxRps,omitempty" toml:"anonymousMaxRps,omitempty"`
+}
+
+func (s *DaemonGroup) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
+
+// DaemonKey is an API key (or bearer token) accepted by the daemon. A key with the "read" scope
+// (the default) may only read data. A key with the "admin" scope may also change it (for example,
+// by editing names, removing monitors or pinning chunks). If MaxRps is not zero, no more than that
+// many requests per second (with bursts of up to Burst requests) are accepted with the key.
+type DaemonKey struct {
+	Name   string  `json:"name" toml:"name"`
+	Key    string  `json:"key" toml:"key"`
+	Scope  string  `json:"scope,omitempty" toml:"scope,omitempty"`
+	MaxRps float64 `json:"maxRps,omitempty" toml:"maxRps,omitempty"`
+	Burst  uint64  `json:"burst,omitempty" toml:"burst,omitempty"`
+}
+
+func (s *DaemonKey) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
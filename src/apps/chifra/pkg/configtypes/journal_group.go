@@ -0,0 +1,23 @@
+package configtypes
+
+import "encoding/json"
+
+// JournalSettings configures the account names used by export --journal. Each value is a
+// colon separated account name in which {name} is replaced with the name of an address (from
+// the names database) or, if it is not named, a shortened form of the address. If a value is
+// empty, the default is used.
+type JournalSettings struct {
+	Assets         string `json:"assets,omitempty" toml:"assets,omitempty"`
+	Income         string `json:"income,omitempty" toml:"income,omitempty"`
+	Expenses       string `json:"expenses,omitempty" toml:"expenses,omitempty"`
+	Gas            string `json:"gas,omitempty" toml:"gas,omitempty"`
+	Mining         string `json:"mining,omitempty" toml:"mining,omitempty"`
+	Staking        string `json:"staking,omitempty" toml:"staking,omitempty"`
+	Reconciliation string `json:"reconciliation,omitempty" toml:"reconciliation,omitempty"`
+	Opening        string `json:"opening,omitempty" toml:"opening,omitempty"`
+}
+
+func (s *JournalSettings) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
@@ -5,6 +5,7 @@ import "encoding/json"
 type NotifyGroup struct {
 	Url    string `json:"url,omitempty" toml:"url"`
 	Author string `json:"author,omitempty" toml:"author"`
+	ApiKey string `json:"apiKey,omitempty" toml:"apiKey,omitempty"`
 }
 
 func (s *NotifyGroup) String() string {
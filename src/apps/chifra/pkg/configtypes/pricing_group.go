@@ -0,0 +1,17 @@
+package configtypes
+
+import "encoding/json"
+
+// PricingSettings configures, per chain, where accounting statements get their US dollar
+// prices. Sources is a comma separated list of price source names consulted in priority order
+// (the first source able to price an asset wins). If empty, the historical order is used.
+type PricingSettings struct {
+	Sources    string `json:"sources,omitempty" toml:"sources,omitempty"`
+	PriceFile  string `json:"priceFile,omitempty" toml:"priceFile,omitempty"`
+	TwapPeriod uint64 `json:"twapPeriod,omitempty" toml:"twapPeriod,omitempty"`
+}
+
+func (s *PricingSettings) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
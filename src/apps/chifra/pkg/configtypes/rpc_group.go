@@ -0,0 +1,37 @@
+package configtypes
+
+import "encoding/json"
+
+// RpcEndpoint is one member of a chain's pool of RPC providers. Requests are spread across the
+// healthy members of the pool in proportion to their weights (a zero weight counts as one). If
+// MaxRps is not zero, no more than that many requests per second are sent to the endpoint.
+type RpcEndpoint struct {
+	Url    string  `json:"url" toml:"url"`
+	Weight uint64  `json:"weight,omitempty" toml:"weight,omitempty"`
+	MaxRps float64 `json:"maxRps,omitempty" toml:"maxRps,omitempty"`
+}
+
+func (s *RpcEndpoint) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
+
+// RpcPolicy controls how a chain's pool of RPC providers retries failed requests and when it
+// ejects an unhealthy endpoint. Zero values select the defaults.
+type RpcPolicy struct {
+	// MaxRetries is the number of times a failed request is retried (default 3)
+	MaxRetries uint64 `json:"maxRetries,omitempty" toml:"maxRetries,omitempty"`
+	// BackoffMs is the delay before the first retry. It doubles with each retry (default 250)
+	BackoffMs uint64 `json:"backoffMs,omitempty" toml:"backoffMs,omitempty"`
+	// MaxBackoffMs caps the delay between retries, including one asked for with Retry-After (default 8000)
+	MaxBackoffMs uint64 `json:"maxBackoffMs,omitempty" toml:"maxBackoffMs,omitempty"`
+	// EjectAfter is the number of consecutive failures after which an endpoint is ejected (default 3)
+	EjectAfter uint64 `json:"ejectAfter,omitempty" toml:"ejectAfter,omitempty"`
+	// EjectSecs is the time an ejected endpoint is left out of the pool (default 60)
+	EjectSecs uint64 `json:"ejectSecs,omitempty" toml:"ejectSecs,omitempty"`
+}
+
+func (s *RpcPolicy) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
@@ -0,0 +1,4 @@
+// Package costbasis tracks the acquired lots of each asset in a stream of reconciled statements
+// and computes the cost basis and realized and unrealized gains using FIFO, LIFO, HIFO, or
+// average cost accounting
+package costbasis
@@ -0,0 +1,274 @@
+package costbasis
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// The cost basis methods
+const (
+	Fifo    = "fifo"
+	Lifo    = "lifo"
+	Hifo    = "hifo"
+	Average = "average"
+)
+
+// The summary periods
+const (
+	Daily     = "daily"
+	Monthly   = "monthly"
+	Quarterly = "quarterly"
+	Yearly    = "yearly"
+)
+
+const (
+	secondsPerYear = 365 * 24 * 60 * 60
+	// dust is the quantity below which a lot (or the remainder of a disposal) is considered empty
+	dust = 1e-12
+)
+
+// lot is a quantity of an asset acquired at a single time and price
+type lot struct {
+	ts       base.Timestamp
+	quantity float64
+	unitCost float64
+}
+
+// positionKey identifies a position. Each token id of a non-fungible token is its own position.
+type positionKey struct {
+	accountedFor base.Address
+	assetAddr    base.Address
+	tokenId      string
+}
+
+// position holds the open lots of a single asset held by a single address and the running
+// totals for the current period (if any)
+type position struct {
+	key       positionKey
+	symbol    string
+	lots      []lot
+	spotPrice float64
+	summary   *types.GainSummary
+}
+
+// Engine consumes reconciled statements in chronological order and matches each outflow with
+// previously acquired lots using the chosen method. Each inflow becomes a new lot valued at the
+// statement's spot price.
+type Engine struct {
+	method    string
+	period    string
+	positions map[positionKey]*position
+	keys      []positionKey
+}
+
+// NewEngine returns an engine using the given cost basis method. If period is not empty, the
+// engine also accumulates a summary for each asset for each period.
+func NewEngine(method, period string) (*Engine, error) {
+	switch method {
+	case Fifo, Lifo, Hifo, Average:
+	default:
+		return nil, fmt.Errorf("unknown cost basis method %s", method)
+	}
+
+	switch period {
+	case "", Daily, Monthly, Quarterly, Yearly:
+	default:
+		return nil, fmt.Errorf("unknown period %s", period)
+	}
+
+	return &Engine{
+		method:    method,
+		period:    period,
+		positions: make(map[positionKey]*position),
+	}, nil
+}
+
+// Process consumes the next statement. It returns the disposals caused by the statement's
+// outflows and the summaries of any periods that the statement closes.
+func (e *Engine) Process(s *types.Statement) ([]types.Disposal, []types.GainSummary) {
+	key := positionKey{accountedFor: s.AccountedFor, assetAddr: s.AssetAddr}
+	if s.IsNft() {
+		key.tokenId = s.TokenId.Text(10)
+	}
+	pos := e.positions[key]
+	if pos == nil {
+		pos = &position{key: key}
+		e.positions[key] = pos
+		e.keys = append(e.keys, key)
+	}
+	pos.symbol = s.AssetSymbol
+
+	summaries := []types.GainSummary{}
+	if len(e.period) > 0 {
+		p := periodOf(s.Timestamp, e.period)
+		if pos.summary != nil && pos.summary.Period != p {
+			summaries = append(summaries, pos.closePeriod())
+		}
+		if pos.summary == nil {
+			pos.summary = &types.GainSummary{
+				AccountedFor: s.AccountedFor,
+				AssetAddr:    s.AssetAddr,
+				Period:       p,
+				Method:       e.method,
+			}
+		}
+		pos.summary.AssetSymbol = s.AssetSymbol
+		pos.summary.BlockNumber = s.BlockNumber
+		pos.summary.Timestamp = s.Timestamp
+	}
+
+	price := float64(s.SpotPrice)
+	if price > 0 {
+		pos.spotPrice = price
+	}
+
+	scale := math.Pow(10, float64(s.Decimals))
+	if in := s.TotalIn().Float64() / scale; in > 0 {
+		pos.acquire(lot{ts: s.Timestamp, quantity: in, unitCost: price}, e.method == Average)
+		if pos.summary != nil {
+			pos.summary.Acquired += base.Float(in)
+		}
+	}
+
+	disposals := []types.Disposal{}
+	if out := s.TotalOut().Float64() / scale; out > 0 {
+		disposals = pos.dispose(e.method, out, price, s)
+		if pos.summary != nil {
+			for _, d := range disposals {
+				pos.summary.Disposed += d.Quantity
+				pos.summary.Proceeds += d.Proceeds
+				pos.summary.CostBasis += d.CostBasis
+				pos.summary.RealizedGain += d.Gain
+			}
+		}
+	}
+
+	return disposals, summaries
+}
+
+// Flush returns the summaries of all open periods. Call it after the last statement.
+func (e *Engine) Flush() []types.GainSummary {
+	summaries := []types.GainSummary{}
+	for _, key := range e.keys {
+		if pos := e.positions[key]; pos.summary != nil {
+			summaries = append(summaries, pos.closePeriod())
+		}
+	}
+	return summaries
+}
+
+// acquire adds a lot to the position. With average cost accounting, the position holds a single
+// lot whose unit cost is the weighted average of everything acquired and whose timestamp is
+// that of the oldest acquisition.
+func (p *position) acquire(l lot, average bool) {
+	if !average || len(p.lots) == 0 {
+		p.lots = append(p.lots, l)
+		return
+	}
+
+	held := &p.lots[0]
+	total := held.quantity + l.quantity
+	held.unitCost = (held.quantity*held.unitCost + l.quantity*l.unitCost) / total
+	held.quantity = total
+}
+
+// dispose removes quantity units from the position's lots and returns a disposal for each lot
+// (or part of a lot) consumed. If the position does not hold enough units (for example, if the
+// history is incomplete) the remainder is reported with a zero cost basis and acquisition time.
+func (p *position) dispose(method string, quantity, price float64, s *types.Statement) []types.Disposal {
+	disposals := []types.Disposal{}
+	remaining := quantity
+	for remaining > dust && len(p.lots) > 0 {
+		i := p.nextLot(method)
+		l := &p.lots[i]
+		take := math.Min(remaining, l.quantity)
+		disposals = append(disposals, p.newDisposal(method, take, price, l.unitCost, l.ts, s))
+		l.quantity -= take
+		remaining -= take
+		if l.quantity <= dust {
+			p.lots = append(p.lots[:i], p.lots[i+1:]...)
+		}
+	}
+
+	if remaining > dust {
+		disposals = append(disposals, p.newDisposal(method, remaining, price, 0, 0, s))
+	}
+
+	return disposals
+}
+
+// nextLot returns the index of the lot the method disposes of first
+func (p *position) nextLot(method string) int {
+	switch method {
+	case Lifo:
+		return len(p.lots) - 1
+	case Hifo:
+		best := 0
+		for i, l := range p.lots {
+			if l.unitCost > p.lots[best].unitCost {
+				best = i
+			}
+		}
+		return best
+	default:
+		// fifo and average (which holds a single lot)
+		return 0
+	}
+}
+
+func (p *position) newDisposal(method string, quantity, price, unitCost float64, acquired base.Timestamp, s *types.Statement) types.Disposal {
+	proceeds := quantity * price
+	costBasis := quantity * unitCost
+	return types.Disposal{
+		AccountedFor:      s.AccountedFor,
+		AssetAddr:         s.AssetAddr,
+		AssetSymbol:       s.AssetSymbol,
+		BlockNumber:       s.BlockNumber,
+		TransactionIndex:  s.TransactionIndex,
+		TransactionHash:   s.TransactionHash,
+		Timestamp:         s.Timestamp,
+		AcquiredTimestamp: acquired,
+		Method:            method,
+		Quantity:          base.Float(quantity),
+		Proceeds:          base.Float(proceeds),
+		CostBasis:         base.Float(costBasis),
+		Gain:              base.Float(proceeds - costBasis),
+		LongTerm:          acquired != 0 && s.Timestamp-acquired > secondsPerYear,
+	}
+}
+
+// closePeriod completes the current period's summary with the position's open lots and returns it
+func (p *position) closePeriod() types.GainSummary {
+	summary := *p.summary
+	p.summary = nil
+
+	var balance, openCost float64
+	for _, l := range p.lots {
+		balance += l.quantity
+		openCost += l.quantity * l.unitCost
+	}
+	summary.Balance = base.Float(balance)
+	summary.OpenCostBasis = base.Float(openCost)
+	summary.SpotPrice = base.Float(p.spotPrice)
+	summary.UnrealizedGain = base.Float(balance*p.spotPrice - openCost)
+	return summary
+}
+
+// periodOf returns the name of the (UTC) period containing the timestamp
+func periodOf(ts base.Timestamp, period string) string {
+	t := time.Unix(int64(ts), 0).UTC()
+	switch period {
+	case Daily:
+		return t.Format("2006-01-02")
+	case Monthly:
+		return t.Format("2006-01")
+	case Quarterly:
+		return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())+2)/3)
+	default:
+		return t.Format("2006")
+	}
+}
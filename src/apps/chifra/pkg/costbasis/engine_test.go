@@ -0,0 +1,146 @@
+package costbasis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+var jan1 = base.Timestamp(1609459200) // 2021-01-01
+
+const day = base.Timestamp(24 * 60 * 60)
+
+// newStatement returns a statement moving whole units of an asset with no decimals
+func newStatement(ts base.Timestamp, in, out int64, price base.Float) types.Statement {
+	return types.Statement{
+		AccountedFor: base.HexToAddress("0x1"),
+		AssetAddr:    base.HexToAddress("0x2"),
+		AssetSymbol:  "TOK",
+		Timestamp:    ts,
+		AmountIn:     *base.NewWei(in),
+		AmountOut:    *base.NewWei(out),
+		SpotPrice:    price,
+	}
+}
+
+// history buys 10 at $1, buys 10 at $3, buys 10 at $2, then sells 15 at $4
+func history() []types.Statement {
+	return []types.Statement{
+		newStatement(jan1, 10, 0, 1),
+		newStatement(jan1+day, 10, 0, 3),
+		newStatement(jan1+2*day, 10, 0, 2),
+		newStatement(jan1+400*day, 0, 15, 4),
+	}
+}
+
+func near(a base.Float, b float64) bool {
+	return math.Abs(float64(a)-b) < 1e-9
+}
+
+func TestEngineMethods(t *testing.T) {
+	tests := []struct {
+		method    string
+		lots      int
+		costBasis float64
+		longTerm  bool
+	}{
+		{Fifo, 2, 10*1 + 5*3, true},
+		{Lifo, 2, 10*2 + 5*3, true},
+		{Hifo, 2, 10*3 + 5*2, true},
+		{Average, 1, 15 * 2, true},
+	}
+
+	for _, test := range tests {
+		engine, err := NewEngine(test.method, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var disposals []types.Disposal
+		for _, s := range history() {
+			d, _ := engine.Process(&s)
+			disposals = append(disposals, d...)
+		}
+
+		if len(disposals) != test.lots {
+			t.Fatal(test.method, "wrong number of disposals", len(disposals))
+		}
+
+		var quantity, proceeds, costBasis, gain base.Float
+		for _, d := range disposals {
+			quantity += d.Quantity
+			proceeds += d.Proceeds
+			costBasis += d.CostBasis
+			gain += d.Gain
+			if d.LongTerm != test.longTerm || d.Method != test.method {
+				t.Error(test.method, "wrong disposal", d)
+			}
+		}
+		if !near(quantity, 15) || !near(proceeds, 60) || !near(costBasis, test.costBasis) || !near(gain, 60-test.costBasis) {
+			t.Error(test.method, "got", quantity, proceeds, costBasis, gain)
+		}
+	}
+}
+
+func TestEngineOversold(t *testing.T) {
+	engine, _ := NewEngine(Fifo, "")
+	s := newStatement(jan1, 5, 0, 1)
+	engine.Process(&s)
+	s = newStatement(jan1+day, 0, 8, 2)
+	disposals, _ := engine.Process(&s)
+	if len(disposals) != 2 {
+		t.Fatal("wrong number of disposals", len(disposals))
+	}
+	if !near(disposals[1].Quantity, 3) || disposals[1].CostBasis != 0 || disposals[1].AcquiredTimestamp != 0 || disposals[1].LongTerm {
+		t.Error("wrong uncovered disposal", disposals[1])
+	}
+}
+
+func TestEnginePeriods(t *testing.T) {
+	engine, err := NewEngine(Fifo, Yearly)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var summaries []types.GainSummary
+	for _, s := range history() {
+		_, sums := engine.Process(&s)
+		summaries = append(summaries, sums...)
+	}
+	summaries = append(summaries, engine.Flush()...)
+
+	if len(summaries) != 2 {
+		t.Fatal("wrong number of summaries", len(summaries))
+	}
+
+	first, second := summaries[0], summaries[1]
+	if first.Period != "2021" || !near(first.Acquired, 30) || first.Disposed != 0 ||
+		!near(first.Balance, 30) || !near(first.OpenCostBasis, 60) || !near(first.UnrealizedGain, 0) {
+		t.Error("wrong first summary", first)
+	}
+	if second.Period != "2022" || !near(second.Disposed, 15) || !near(second.RealizedGain, 35) ||
+		!near(second.Balance, 15) || !near(second.OpenCostBasis, 35) || !near(second.UnrealizedGain, 25) {
+		t.Error("wrong second summary", second)
+	}
+
+	if _, err := NewEngine("bogus", ""); err == nil {
+		t.Error("expected an error for an unknown method")
+	}
+}
+
+func TestPeriodOf(t *testing.T) {
+	ts := base.Timestamp(1628035200) // 2021-08-04
+	tests := map[string]string{
+		Daily:     "2021-08-04",
+		Monthly:   "2021-08",
+		Quarterly: "2021-Q3",
+		Yearly:    "2021",
+	}
+	for period, expected := range tests {
+		if got := periodOf(ts, period); got != expected {
+			t.Error(period, "got", got, "expected", expected)
+		}
+	}
+}
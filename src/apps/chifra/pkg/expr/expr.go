@@ -0,0 +1,421 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+// Package expr parses and evaluates the expressions of the --fields and --where options
+// against the records of the output.
+package expr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// Lookup returns the value at a path (a field name or a dotted path into nested objects and
+// arrays) of a record and whether it was found
+type Lookup func(path []string) (any, bool)
+
+// ModelLookup returns a Lookup that finds the value at a path in the model rendered in the
+// given format or, failing that, in the model rendered as JSON (which holds nested objects) and
+// as text (which holds fields that JSON omits when empty). The other renderings are only built
+// if needed.
+func ModelLookup(modeler types.Modeler, chain, format string, verbose bool, extraOpts map[string]any) Lookup {
+	formats := []string{format}
+	for _, f := range []string{"json", "txt"} {
+		if f != format {
+			formats = append(formats, f)
+		}
+	}
+	models := make([]map[string]any, len(formats))
+	return func(path []string) (any, bool) {
+		for i, f := range formats {
+			if models[i] == nil {
+				models[i] = modeler.Model(chain, f, verbose, extraOpts).Data
+			}
+			if value, ok := lookupPath(models[i], path); ok {
+				return value, true
+			}
+		}
+		return nil, false
+	}
+}
+
+// lookupPath walks the path through maps, slices (with numeric indices), and (by way of their
+// JSON representation) any other nested values
+func lookupPath(data map[string]any, path []string) (any, bool) {
+	var cur any = data
+	for _, key := range path {
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+			continue
+		}
+
+		rv := reflect.ValueOf(cur)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= rv.Len() {
+				return nil, false
+			}
+			cur = rv.Index(i).Interface()
+		case reflect.Map, reflect.Struct, reflect.Pointer:
+			generic, ok := toGeneric(cur)
+			if !ok {
+				return nil, false
+			}
+			m, ok := generic.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if cur, ok = m[key]; !ok {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func toGeneric(value any) (any, bool) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	dec := json.NewDecoder(strings.NewReader(string(bytes)))
+	dec.UseNumber()
+	var ret any
+	if err := dec.Decode(&ret); err != nil {
+		return nil, false
+	}
+	return ret, true
+}
+
+// TextOf returns the value as it appears in text formats. Objects and arrays are rendered as JSON
+// (and values that marshal as strings, such as addresses, are unquoted).
+func TextOf(value any) any {
+	if value == nil {
+		return ""
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if _, ok := value.(fmt.Stringer); ok {
+			return value
+		}
+		buf := bytes.Buffer{}
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(value); err == nil {
+			// Values that marshal as strings (addresses and hashes, for example) are unquoted
+			var str string
+			if json.Unmarshal(buf.Bytes(), &str) == nil {
+				return str
+			}
+			return strings.TrimSpace(buf.String())
+		}
+	}
+	return value
+}
+
+// exprNode is a node of a parsed field expression
+type exprNode interface {
+	eval(lookup Lookup) (any, bool)
+}
+
+type pathNode struct {
+	path []string
+}
+
+type numberNode struct {
+	value *big.Rat
+}
+
+type binaryNode struct {
+	op          byte
+	left, right exprNode
+}
+
+type negateNode struct {
+	operand exprNode
+}
+
+func (n *pathNode) eval(lookup Lookup) (any, bool) {
+	return lookup(n.path)
+}
+
+func (n *numberNode) eval(lookup Lookup) (any, bool) {
+	return n.value, true
+}
+
+func (n *negateNode) eval(lookup Lookup) (any, bool) {
+	value, ok := evalNumber(n.operand, lookup)
+	if !ok {
+		return nil, false
+	}
+	return new(big.Rat).Neg(value), true
+}
+
+func (n *binaryNode) eval(lookup Lookup) (any, bool) {
+	left, ok := evalNumber(n.left, lookup)
+	if !ok {
+		return nil, false
+	}
+	right, ok := evalNumber(n.right, lookup)
+	if !ok {
+		return nil, false
+	}
+
+	ret := new(big.Rat)
+	switch n.op {
+	case '+':
+		ret.Add(left, right)
+	case '-':
+		ret.Sub(left, right)
+	case '*':
+		ret.Mul(left, right)
+	case '/':
+		if right.Sign() == 0 {
+			return nil, false
+		}
+		ret.Quo(left, right)
+	}
+	return ret, true
+}
+
+// evalNumber evaluates the node as a number. Fields holding numbers or numeric strings (such as
+// the decimal strings of wei values) may be used in arithmetic.
+func evalNumber(n exprNode, lookup Lookup) (*big.Rat, bool) {
+	value, ok := n.eval(lookup)
+	if !ok {
+		return nil, false
+	}
+	return numberOf(value)
+}
+
+// numberOf converts numbers and numeric strings (including hex strings such as addresses) to exact fractions
+func numberOf(value any) (*big.Rat, bool) {
+	if r, ok := value.(*big.Rat); ok {
+		return r, true
+	}
+	if num, ok := value.(json.Number); ok {
+		value = num.String()
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetUint64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		if r := new(big.Rat).SetFloat64(rv.Float()); r != nil {
+			return r, true
+		}
+	case reflect.String:
+		if r, ok := new(big.Rat).SetString(strings.TrimSpace(rv.String())); ok {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// ratValue renders a computed value: integers that fit in 64 bits as numbers, other integers
+// as decimal strings, and fractions as decimals with up to eighteen places.
+func ratValue(r *big.Rat) any {
+	if r.IsInt() {
+		if r.Num().IsInt64() {
+			return r.Num().Int64()
+		}
+		return r.Num().String()
+	}
+	str := strings.TrimRight(r.FloatString(18), "0")
+	return strings.TrimSuffix(str, ".")
+}
+
+// Expression is a parsed arithmetic expression of fields and numbers
+type Expression struct {
+	node exprNode
+}
+
+// Eval returns the value of the expression for the record or false if a field it uses is
+// missing (or is not a number where a number is needed)
+func (e *Expression) Eval(lookup Lookup) (any, bool) {
+	return e.node.eval(lookup)
+}
+
+// IsField returns true if the expression is a single field, whose values keep the field's type.
+// The value of arithmetic is an integer or, if it is not whole, a decimal string.
+func (e *Expression) IsField() bool {
+	if r, ok := e.node.(*resultNode); ok {
+		_, isPath := r.exprNode.(*pathNode)
+		return isPath
+	}
+	return false
+}
+
+// Parse parses an arithmetic expression of fields and numbers using the usual precedence of
+// + - * / and parentheses
+func Parse(text string) (*Expression, error) {
+	node, err := parseExpression(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{node}, nil
+}
+
+func parseExpression(text string) (exprNode, error) {
+	p := &exprParser{text: text}
+	node, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.text) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.text[p.pos:], p.pos)
+	}
+	return &resultNode{node}, nil
+}
+
+// resultNode converts the value of a top level expression to its output form. Intermediate
+// results are kept as exact fractions.
+type resultNode struct {
+	exprNode
+}
+
+func (n *resultNode) eval(lookup Lookup) (any, bool) {
+	value, ok := n.exprNode.eval(lookup)
+	if r, isRat := value.(*big.Rat); ok && isRat {
+		return ratValue(r), true
+	}
+	return value, ok
+}
+
+type exprParser struct {
+	text string
+	pos  int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos < len(p.text) {
+		return p.text[p.pos]
+	}
+	return 0
+}
+
+func (p *exprParser) parseSum() (exprNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseProduct() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	switch c := p.peek(); {
+	case c == '-':
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negateNode{operand}, nil
+	case c == '(':
+		p.pos++
+		node, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+	case c >= '0' && c <= '9' || c == '.':
+		return p.parseNumber()
+	case c == '_' || unicode.IsLetter(rune(c)):
+		return p.parsePath()
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos)
+	}
+}
+
+func (p *exprParser) parseNumber() (exprNode, error) {
+	start := p.pos
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		isExponentSign := (c == '+' || c == '-') && (p.text[p.pos-1] == 'e' || p.text[p.pos-1] == 'E')
+		if !(c >= '0' && c <= '9' || c == '.' || c == 'e' || c == 'E' || isExponentSign) {
+			break
+		}
+		p.pos++
+	}
+	value, ok := new(big.Rat).SetString(p.text[start:p.pos])
+	if !ok {
+		return nil, fmt.Errorf("invalid number %s", p.text[start:p.pos])
+	}
+	return &numberNode{value}, nil
+}
+
+func (p *exprParser) parsePath() (exprNode, error) {
+	start := p.pos
+	for p.pos < len(p.text) {
+		c := rune(p.text[p.pos])
+		if !(c == '_' || c == '.' || unicode.IsLetter(c) || unicode.IsDigit(c)) {
+			break
+		}
+		p.pos++
+	}
+	path := strings.Split(p.text[start:p.pos], ".")
+	for _, part := range path {
+		if len(part) == 0 {
+			return nil, fmt.Errorf("invalid field %s", p.text[start:p.pos])
+		}
+	}
+	return &pathNode{path}, nil
+}
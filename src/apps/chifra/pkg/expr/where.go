@@ -0,0 +1,493 @@
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/names"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// Where is the filter given with --where. It is a boolean expression evaluated against each
+// record of the output, for example `value > 1e18 && to in @exchanges`. Operands are fields
+// (or dotted paths or arithmetic expressions as in --fields), numbers, quoted strings, hex
+// literals, and true or false. Comparisons (== != < <= > >=) are numeric if both sides are
+// numbers and otherwise compare strings ignoring case. `x in [a, b]` tests membership in a
+// list and `x in @tag` tests whether x is the address of a name carrying the tag. Conditions
+// combine with &&, ||, !, and parentheses. A comparison with a missing field is false.
+type Where struct {
+	cond condNode
+	tags []*inNode
+}
+
+// ParseWhere parses a --where expression. Tag sets are empty until loaded with LoadTags.
+func ParseWhere(spec string) (*Where, error) {
+	if len(strings.TrimSpace(spec)) == 0 {
+		return nil, nil
+	}
+
+	p := &whereParser{exprParser: exprParser{text: spec}}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.text) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.text[p.pos:], p.pos)
+	}
+	return &Where{cond: cond, tags: p.tags}, nil
+}
+
+// LoadWhere parses a --where expression and loads its tags
+func LoadWhere(spec, chain string) (*Where, error) {
+	where, err := ParseWhere(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err = where.LoadTags(chain); err != nil {
+		return nil, err
+	}
+	return where, nil
+}
+
+// LoadTags loads, from the names database, the addresses of the names carrying each of the
+// tags used in the expression. It is an error if no name carries a tag.
+func (w *Where) LoadTags(chain string) error {
+	if w == nil {
+		return nil
+	}
+
+	for _, node := range w.tags {
+		addrs, err := names.LoadTaggedAddresses(chain, node.tag)
+		if err != nil {
+			return err
+		}
+		node.set = make(map[string]bool, len(addrs))
+		for addr := range addrs {
+			node.set[strings.ToLower(addr.Hex())] = true
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the model passes the filter. Fields are looked up as with --fields.
+func (w *Where) Matches(modeler types.Modeler, chain, format string, verbose bool, extraOpts map[string]any) bool {
+	if w == nil {
+		return true
+	}
+	lookup := ModelLookup(modeler, chain, format, verbose, extraOpts)
+	return w.cond.test(lookup, false) == isTrue
+}
+
+// MayMatchAppearance reports whether a record at the appearance may pass the filter. Only
+// blockNumber and transactionIndex are known before the record is fetched, so this is false
+// only if the expression is false whatever the values of its other fields. This allows the
+// AppearanceFilter to skip fetching data that could never be output.
+func (w *Where) MayMatchAppearance(bn base.Blknum, txid base.Txnum) bool {
+	if w == nil {
+		return true
+	}
+	lookup := func(path []string) (any, bool) {
+		if len(path) == 1 {
+			switch path[0] {
+			case "blockNumber":
+				return bn, true
+			case "transactionIndex":
+				return txid, true
+			}
+		}
+		return nil, false
+	}
+	return w.cond.test(lookup, true) != isFalse
+}
+
+// truth is the value of a condition. A condition is unknown only when testing an appearance,
+// before the fields of the record are available.
+type truth int
+
+const (
+	isFalse truth = iota
+	isTrue
+	isUnknown
+)
+
+func truthOf(b bool) truth {
+	if b {
+		return isTrue
+	}
+	return isFalse
+}
+
+// missing is the value of a condition on a field that is not present
+func missing(partial bool) truth {
+	if partial {
+		return isUnknown
+	}
+	return isFalse
+}
+
+// condNode is a node of a parsed --where expression
+type condNode interface {
+	test(lookup Lookup, partial bool) truth
+}
+
+type orNode struct {
+	left, right condNode
+}
+
+type andNode struct {
+	left, right condNode
+}
+
+type notNode struct {
+	operand condNode
+}
+
+type compareNode struct {
+	op          string
+	left, right exprNode
+}
+
+type inNode struct {
+	operand exprNode
+	list    []exprNode
+	tag     string
+	set     map[string]bool
+}
+
+type truthyNode struct {
+	operand exprNode
+}
+
+func (n *orNode) test(lookup Lookup, partial bool) truth {
+	left := n.left.test(lookup, partial)
+	if left == isTrue {
+		return isTrue
+	}
+	right := n.right.test(lookup, partial)
+	if right == isTrue {
+		return isTrue
+	}
+	if left == isUnknown || right == isUnknown {
+		return isUnknown
+	}
+	return isFalse
+}
+
+func (n *andNode) test(lookup Lookup, partial bool) truth {
+	left := n.left.test(lookup, partial)
+	if left == isFalse {
+		return isFalse
+	}
+	right := n.right.test(lookup, partial)
+	if right == isFalse {
+		return isFalse
+	}
+	if left == isUnknown || right == isUnknown {
+		return isUnknown
+	}
+	return isTrue
+}
+
+func (n *notNode) test(lookup Lookup, partial bool) truth {
+	switch n.operand.test(lookup, partial) {
+	case isTrue:
+		return isFalse
+	case isFalse:
+		return isTrue
+	default:
+		return isUnknown
+	}
+}
+
+func (n *compareNode) test(lookup Lookup, partial bool) truth {
+	left, ok := n.left.eval(lookup)
+	if !ok {
+		return missing(partial)
+	}
+	right, ok := n.right.eval(lookup)
+	if !ok {
+		return missing(partial)
+	}
+
+	cmp := compareValues(left, right)
+	switch n.op {
+	case "==":
+		return truthOf(cmp == 0)
+	case "!=":
+		return truthOf(cmp != 0)
+	case "<":
+		return truthOf(cmp < 0)
+	case "<=":
+		return truthOf(cmp <= 0)
+	case ">":
+		return truthOf(cmp > 0)
+	default:
+		return truthOf(cmp >= 0)
+	}
+}
+
+func (n *inNode) test(lookup Lookup, partial bool) truth {
+	value, ok := n.operand.eval(lookup)
+	if !ok {
+		return missing(partial)
+	}
+
+	if len(n.tag) > 0 {
+		if n.set == nil {
+			return missing(partial)
+		}
+		return truthOf(n.set[strings.ToLower(stringOfValue(value))])
+	}
+
+	for _, item := range n.list {
+		if other, ok := item.eval(lookup); ok && compareValues(value, other) == 0 {
+			return isTrue
+		}
+	}
+	return isFalse
+}
+
+func (n *truthyNode) test(lookup Lookup, partial bool) truth {
+	value, ok := n.operand.eval(lookup)
+	if !ok || value == nil {
+		return missing(partial)
+	}
+	if b, ok := value.(bool); ok {
+		return truthOf(b)
+	}
+	if r, ok := numberOf(value); ok {
+		return truthOf(r.Sign() != 0)
+	}
+	return truthOf(len(stringOfValue(value)) > 0)
+}
+
+// compareValues compares two values as numbers if both are numbers (or numeric strings such as
+// wei values and hex addresses) and otherwise as strings ignoring case
+func compareValues(left, right any) int {
+	if l, ok := numberOf(left); ok {
+		if r, ok := numberOf(right); ok {
+			return l.Cmp(r)
+		}
+	}
+	return strings.Compare(strings.ToLower(stringOfValue(left)), strings.ToLower(stringOfValue(right)))
+}
+
+func stringOfValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	if num, ok := value.(json.Number); ok {
+		return num.String()
+	}
+	return fmt.Sprint(TextOf(value))
+}
+
+type stringNode struct {
+	value string
+}
+
+type boolNode struct {
+	value bool
+}
+
+func (n *stringNode) eval(lookup Lookup) (any, bool) {
+	return n.value, true
+}
+
+func (n *boolNode) eval(lookup Lookup) (any, bool) {
+	return n.value, true
+}
+
+type whereParser struct {
+	exprParser
+	tags []*inNode
+}
+
+// lookingAt reports whether the text at the current position starts with the token
+func (p *whereParser) lookingAt(token string) bool {
+	p.skipSpace()
+	return strings.HasPrefix(p.text[p.pos:], token)
+}
+
+// lookingAtWord reports whether the text at the current position is the word (and not the
+// start of a longer field name)
+func (p *whereParser) lookingAtWord(word string) bool {
+	if !p.lookingAt(word) {
+		return false
+	}
+	end := p.pos + len(word)
+	return end == len(p.text) || !isPathChar(rune(p.text[end]))
+}
+
+func (p *whereParser) parseOr() (condNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.lookingAt("||") {
+		p.pos += 2
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseAnd() (condNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.lookingAt("&&") {
+		p.pos += 2
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseNot() (condNode, error) {
+	if p.lookingAt("!") && !p.lookingAt("!=") {
+		p.pos++
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand}, nil
+	}
+
+	if p.peek() == '(' {
+		// A parenthesis may group conditions or start an arithmetic operand such as (a + b) * 2
+		save := p.pos
+		p.pos++
+		if cond, err := p.parseOr(); err == nil && p.peek() == ')' {
+			p.pos++
+			if !p.lookingAtOperator() {
+				return cond, nil
+			}
+		}
+		p.pos = save
+	}
+
+	return p.parseComparison()
+}
+
+func (p *whereParser) lookingAtOperator() bool {
+	switch p.peek() {
+	case '+', '-', '*', '/', '<', '>', '=':
+		return true
+	}
+	return p.lookingAt("!=") || p.lookingAtWord("in")
+}
+
+var compareOps = []string{"==", "!=", "<=", ">=", "<", ">", "="}
+
+func (p *whereParser) parseComparison() (condNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.lookingAtWord("in") {
+		p.pos += len("in")
+		return p.parseIn(left)
+	}
+
+	for _, op := range compareOps {
+		if p.lookingAt(op) {
+			p.pos += len(op)
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			if op == "=" {
+				op = "=="
+			}
+			return &compareNode{op: op, left: left, right: right}, nil
+		}
+	}
+
+	return &truthyNode{left}, nil
+}
+
+func (p *whereParser) parseIn(operand exprNode) (condNode, error) {
+	switch p.peek() {
+	case '@':
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.text) && (isPathChar(rune(p.text[p.pos])) || p.text[p.pos] == '-' || p.text[p.pos] == ':') {
+			p.pos++
+		}
+		if p.pos == start {
+			return nil, fmt.Errorf("missing tag name at position %d", start)
+		}
+		node := &inNode{operand: operand, tag: p.text[start:p.pos]}
+		p.tags = append(p.tags, node)
+		return node, nil
+
+	case '[':
+		p.pos++
+		node := &inNode{operand: operand}
+		for {
+			item, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			node.list = append(node.list, item)
+			switch p.peek() {
+			case ',':
+				p.pos++
+			case ']':
+				p.pos++
+				return node, nil
+			default:
+				return nil, fmt.Errorf("missing closing bracket")
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("expected @tag or [list] at position %d", p.pos)
+	}
+}
+
+func (p *whereParser) parseOperand() (exprNode, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		end := strings.IndexByte(p.text[p.pos+1:], c)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string at position %d", p.pos)
+		}
+		value := p.text[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return &stringNode{value}, nil
+
+	case p.lookingAt("0x"):
+		start := p.pos
+		p.pos += 2
+		for p.pos < len(p.text) && strings.ContainsRune("0123456789abcdefABCDEF", rune(p.text[p.pos])) {
+			p.pos++
+		}
+		return &stringNode{p.text[start:p.pos]}, nil
+
+	case p.lookingAtWord("true"), p.lookingAtWord("false"):
+		value := p.lookingAtWord("true")
+		p.pos += len(fmt.Sprint(value))
+		return &boolNode{value}, nil
+
+	default:
+		return p.parseSum()
+	}
+}
+
+func isPathChar(c rune) bool {
+	return c == '_' || c == '.' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
@@ -0,0 +1,141 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+var whereTx = types.Transaction{
+	BlockNumber:      100,
+	TransactionIndex: 2,
+	Hash:             base.HexToHash("0xabc"),
+	From:             base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b"),
+	Value:            *base.NewWei(1500000000000000000),
+	GasPrice:         3,
+	Receipt: &types.Receipt{
+		GasUsed: 21000,
+		Logs: []types.Log{
+			{Address: base.HexToAddress("0x1234"), LogIndex: 7},
+		},
+	},
+}
+
+func TestParseWhere(t *testing.T) {
+	valid := []string{
+		"value > 1e18",
+		"value > 1e18 && to in @exchanges",
+		"!isError || (gasUsed * gasPrice) / 1e9 >= 21000",
+		"((blockNumber + 1) * 2 > 10)",
+		"from == 0xf503017d7baf7fbc0fff7492b751025c6a78179b",
+		"name != 'Some Name' && isError == false",
+		"transactionIndex in [0, 1, 2]",
+		"!(blockNumber < 100)",
+	}
+	for _, spec := range valid {
+		if where, err := ParseWhere(spec); err != nil || where == nil {
+			t.Errorf("%s: unexpected error %v", spec, err)
+		}
+	}
+
+	invalid := []string{
+		"value >",
+		"value > 1 &&",
+		"(value > 1",
+		"to in exchanges",
+		"to in @",
+		"to in [0x1, 0x2",
+		"name == 'unterminated",
+		"value > 1 value",
+	}
+	for _, spec := range invalid {
+		if _, err := ParseWhere(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+
+	if where, _ := ParseWhere(" "); where != nil || !where.Matches(&whereTx, "mainnet", "json", false, nil) {
+		t.Error("an empty expression should match everything")
+	}
+}
+
+func TestWhereMatches(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected bool
+	}{
+		{"value > 1e18", true},
+		{"value > 2e18", false},
+		{"value / 1e18 == 1.5", true},
+		{"blockNumber == 100 && transactionIndex == 2", true},
+		{"blockNumber == 100 && transactionIndex == 3", false},
+		{"blockNumber == 99 || transactionIndex == 2", true},
+		{"!(blockNumber == 100)", false},
+		{"from == 0xF503017D7BAF7FBC0FFF7492B751025C6A78179B", true},
+		{"from in [0x1234, 0xf503017d7baf7fbc0fff7492b751025c6a78179b]", true},
+		{"from in [0x1234]", false},
+		{"receipt.gasUsed * gasPrice == 63000", true},
+		{"receipt.logs.0.logIndex == 7", true},
+		{"isError", false},
+		{"isError == false", true},
+		{"missing == 1", false},
+		{"missing != 1", false},
+		{"!missing", true},
+	}
+	for _, test := range tests {
+		where, err := ParseWhere(test.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", test.spec, err)
+		}
+		for _, format := range []string{"json", "csv"} {
+			if got := where.Matches(&whereTx, "mainnet", format, false, nil); got != test.expected {
+				t.Errorf("%s (%s): got %t, expected %t", test.spec, format, got, test.expected)
+			}
+		}
+	}
+}
+
+func TestWhereTags(t *testing.T) {
+	where, err := ParseWhere("from in @gitcoin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !where.MayMatchAppearance(100, 2) {
+		t.Error("a tag is unknown for an appearance")
+	}
+	where.tags[0].set = map[string]bool{"0xf503017d7baf7fbc0fff7492b751025c6a78179b": true}
+	if !where.Matches(&whereTx, "mainnet", "json", false, nil) {
+		t.Error("expected the tagged address to match")
+	}
+	where.tags[0].set = map[string]bool{"0x1234": true}
+	if where.Matches(&whereTx, "mainnet", "json", false, nil) {
+		t.Error("expected an untagged address not to match")
+	}
+}
+
+func TestWhereAppearance(t *testing.T) {
+	tests := []struct {
+		spec     string
+		bn       base.Blknum
+		txid     base.Txnum
+		expected bool
+	}{
+		{"blockNumber >= 100 && value > 1e18", 100, 0, true},
+		{"blockNumber >= 100 && value > 1e18", 99, 0, false},
+		{"blockNumber < 100 || value > 1e18", 200, 0, true},
+		{"!(blockNumber == 100 && transactionIndex == 2)", 100, 2, false},
+		{"!(blockNumber == 100 && isError)", 100, 2, true},
+		{"transactionIndex in [1, 2]", 100, 3, false},
+		{"to in @exchanges && blockNumber > 500", 100, 3, false},
+	}
+	for _, test := range tests {
+		where, err := ParseWhere(test.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", test.spec, err)
+		}
+		if got := where.MayMatchAppearance(test.bn, test.txid); got != test.expected {
+			t.Errorf("%s at %d.%d: got %t, expected %t", test.spec, test.bn, test.txid, got, test.expected)
+		}
+	}
+}
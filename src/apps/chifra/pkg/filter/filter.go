@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/expr"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
 
@@ -24,6 +25,16 @@ type AppearanceFilter struct {
 	currentBn   uint32
 	currentTs   int64
 	BlocksOnly  bool
+	where       *expr.Where
+	whereModel  whereModel
+}
+
+// whereModel is how records are modeled when tested against --where
+type whereModel struct {
+	chain     string
+	format    string
+	verbose   bool
+	extraOpts map[string]any
 }
 
 func NewFilter(reversed, reverted bool, fourBytes []string, exportRange base.BlockRange, recordRange base.RecordRange) *AppearanceFilter {
@@ -57,6 +68,14 @@ func (f *AppearanceFilter) SetSort(sortBy AppearanceSort) {
 	f.sortBy = sortBy
 }
 
+// SetWhere sets the --where expression (with its tags loaded) and how records are modeled when
+// tested against it. ApplyFilter and ApplyRangeFilter skip appearances whose records could never
+// pass and ApplyModelFilter does not count records that do not pass.
+func (f *AppearanceFilter) SetWhere(where *expr.Where, chain, format string, verbose bool, extraOpts map[string]any) {
+	f.where = where
+	f.whereModel = whereModel{chain: chain, format: format, verbose: verbose, extraOpts: extraOpts}
+}
+
 func (f *AppearanceFilter) Reset() {
 	f.currentBn = uint32(0)
 	f.currentTs = int64(0)
@@ -72,13 +91,19 @@ func (f *AppearanceFilter) ApplyFilter(app *types.AppRecord) (passed, finished b
 	if !appRange.Intersects(base.FileRange(f.exportRange)) {
 		return false, false
 	}
+	if !f.where.MayMatchAppearance(base.Blknum(app.BlockNumber), base.Txnum(app.TransactionIndex)) { // --where
+		return false, false
+	}
 	return f.ApplyCountFilter()
 }
 
 // ApplyRangeFilter checks to see if the appearance intersects with the user-supplied --first_block/--last_block pair (if any)
 func (f *AppearanceFilter) ApplyRangeFilter(app *types.AppRecord) (passed, finished bool) {
 	appRange := base.FileRange{First: base.Blknum(app.BlockNumber), Last: base.Blknum(app.BlockNumber)} // --first_block/--last_block
-	return appRange.Intersects(base.FileRange(f.exportRange)), false
+	if !appRange.Intersects(base.FileRange(f.exportRange)) {
+		return false, false
+	}
+	return f.where.MayMatchAppearance(base.Blknum(app.BlockNumber), base.Txnum(app.TransactionIndex)), false // --where
 }
 
 // ApplyCountFilter checks to see if the appearance is at or later than the --first_record and less than (because it's zero-based) --max_records.
@@ -97,6 +122,15 @@ func (f *AppearanceFilter) ApplyCountFilter() (passed, finished bool) {
 	return true, false
 }
 
+// ApplyModelFilter checks the record against --where and, if it passes, counts it as ApplyCountFilter does
+func (f *AppearanceFilter) ApplyModelFilter(model types.Modeler) (passed, finished bool) {
+	m := &f.whereModel
+	if !f.where.Matches(model, m.chain, m.format, m.verbose, m.extraOpts) { // --where
+		return false, false
+	}
+	return f.ApplyCountFilter()
+}
+
 // ApplyTxFilters applies other filters such as the four byte and reverted filters.
 func (f *AppearanceFilter) ApplyTxFilters(tx *types.Transaction) (passed, finished bool) {
 	matchesReverted := !f.reverted || tx.IsError
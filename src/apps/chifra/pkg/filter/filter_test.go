@@ -0,0 +1,33 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/expr"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestApplyModelFilter(t *testing.T) {
+	f := NewFilter(false, false, []string{}, base.BlockRange{First: 0, Last: base.NOPOSN}, base.RecordRange{First: 1, Last: 2})
+	where, err := expr.ParseWhere("blockNumber > 100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetWhere(where, "mainnet", "json", false, nil)
+
+	// records failing --where count toward neither --first_record nor --max_records
+	passed := []base.Blknum{}
+	for _, bn := range []base.Blknum{99, 101, 100, 102, 98, 103, 104} {
+		passes, finished := f.ApplyModelFilter(&types.Log{BlockNumber: bn})
+		if passes {
+			passed = append(passed, bn)
+		}
+		if finished {
+			break
+		}
+	}
+	if len(passed) != 2 || passed[0] != 102 || passed[1] != 103 {
+		t.Errorf("expected blocks 102 and 103, got %v", passed)
+	}
+}
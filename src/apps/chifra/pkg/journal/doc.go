@@ -0,0 +1,3 @@
+// Package journal renders reconciled statements as a double-entry journal in Beancount, hledger,
+// or ledger-cli syntax, including account, commodity, and price declarations
+package journal
@@ -0,0 +1,602 @@
+package journal
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// The journal syntaxes
+const (
+	Beancount = "beancount"
+	Hledger   = "hledger"
+	Ledger    = "ledger"
+)
+
+// DefaultAccounts are the account templates used for any template left empty in the configuration
+var DefaultAccounts = configtypes.JournalSettings{
+	Assets:         "Assets:{name}",
+	Income:         "Income:{name}",
+	Expenses:       "Expenses:{name}",
+	Gas:            "Expenses:Gas",
+	Mining:         "Income:Mining",
+	Staking:        "Income:Staking",
+	Reconciliation: "Equity:Reconciliation",
+	Opening:        "Equity:Opening-Balances",
+}
+
+// quoteCurrency is the currency of every price directive. Spot prices are in US dollars.
+const quoteCurrency = "USD"
+
+// commodity is an asset as it is declared in the journal
+type commodity struct {
+	addr     base.Address
+	symbol   string
+	decimals uint64
+}
+
+// posting moves an amount (in the asset's smallest unit) into (if positive) or out of (if
+// negative) an account
+type posting struct {
+	account   string
+	amount    *big.Int
+	commodity *commodity
+}
+
+// entry is a single balanced journal transaction
+type entry struct {
+	date     string
+	payee    string
+	hash     base.Hash
+	blockNum base.Blknum
+	txIndex  base.Txnum
+	owner    string
+	postings []posting
+}
+
+// price is a price directive for a commodity on a given date
+type price struct {
+	date      string
+	commodity *commodity
+	price     base.Float
+}
+
+// positionKey identifies a balance whose opening amount has already been journaled
+type positionKey struct {
+	owner string
+	asset base.Address
+}
+
+// Journal collects reconciled statements and writes them as a double-entry journal. Each
+// statement's in and out categories become balanced postings between the accounted for
+// address's asset account and the account of the counterparty (or of the category).
+type Journal struct {
+	flavor      string
+	native      string
+	accounts    configtypes.JournalSettings
+	names       map[base.Address]types.Name
+	owned       map[base.Address]bool
+	labels      map[base.Address]string
+	labelOwners map[string]base.Address
+	commodities map[base.Address]*commodity
+	symbols     map[string]base.Address
+	opened      map[string]string
+	priced      map[string]bool
+	prices      []price
+	started     map[positionKey]bool
+	entries     []*entry
+}
+
+// NewJournal returns a journal in the given syntax. The native asset is declared with the given
+// symbol. Any template left empty in accounts is taken from DefaultAccounts. The owned addresses
+// (usually the exported addresses) are treated as asset accounts wherever they appear.
+func NewJournal(flavor, nativeSymbol string, accounts configtypes.JournalSettings, names map[base.Address]types.Name, owned []base.Address) (*Journal, error) {
+	switch flavor {
+	case Beancount, Hledger, Ledger:
+	default:
+		return nil, fmt.Errorf("unknown journal syntax %s", flavor)
+	}
+
+	if len(nativeSymbol) == 0 {
+		nativeSymbol = "ETH"
+	}
+
+	if names == nil {
+		names = map[base.Address]types.Name{}
+	}
+
+	j := &Journal{
+		flavor:      flavor,
+		native:      nativeSymbol,
+		accounts:    withDefaults(accounts),
+		names:       names,
+		owned:       make(map[base.Address]bool, len(owned)),
+		labels:      make(map[base.Address]string),
+		labelOwners: make(map[string]base.Address),
+		commodities: make(map[base.Address]*commodity),
+		symbols:     make(map[string]base.Address),
+		opened:      make(map[string]string),
+		priced:      make(map[string]bool),
+		started:     make(map[positionKey]bool),
+	}
+	for _, addr := range owned {
+		j.owned[addr] = true
+	}
+	return j, nil
+}
+
+func withDefaults(accounts configtypes.JournalSettings) configtypes.JournalSettings {
+	pick := func(value, def string) string {
+		if len(strings.TrimSpace(value)) == 0 {
+			return def
+		}
+		return strings.TrimSpace(value)
+	}
+	return configtypes.JournalSettings{
+		Assets:         pick(accounts.Assets, DefaultAccounts.Assets),
+		Income:         pick(accounts.Income, DefaultAccounts.Income),
+		Expenses:       pick(accounts.Expenses, DefaultAccounts.Expenses),
+		Gas:            pick(accounts.Gas, DefaultAccounts.Gas),
+		Mining:         pick(accounts.Mining, DefaultAccounts.Mining),
+		Staking:        pick(accounts.Staking, DefaultAccounts.Staking),
+		Reconciliation: pick(accounts.Reconciliation, DefaultAccounts.Reconciliation),
+		Opening:        pick(accounts.Opening, DefaultAccounts.Opening),
+	}
+}
+
+// Add journals a single statement. Statements from the same transaction (and for the same
+// accounted for address) are collected into a single journal entry. Statements without a
+// transaction hash (withdrawals, mining rewards, prefunds) each get their own entry.
+func (j *Journal) Add(s *types.Statement) {
+	c := j.commodityOf(s)
+	date := dateOf(s.Timestamp)
+	self := j.selfOf(s)
+	owner := ownerOf(s)
+
+	key := positionKey{owner: owner, asset: s.AssetAddr}
+	if !j.started[key] {
+		j.started[key] = true
+		if s.BegBal.BigInt().Sign() != 0 {
+			opening := &entry{
+				date:     date,
+				payee:    "Opening balance",
+				blockNum: s.BlockNumber,
+				txIndex:  s.TransactionIndex,
+				owner:    owner,
+			}
+			j.post(opening, date, self, j.accounts.Opening, s.BegBal.BigInt(), c)
+			j.entries = append(j.entries, opening)
+		}
+	}
+
+	if s.SpotPrice > 0 && s.PriceSource != "not-priced" {
+		priceKey := date + "|" + c.symbol
+		if !j.priced[priceKey] {
+			j.priced[priceKey] = true
+			j.prices = append(j.prices, price{date: date, commodity: c, price: s.SpotPrice})
+		}
+	}
+
+	var e *entry
+	if n := len(j.entries); n > 0 {
+		last := j.entries[n-1]
+		if !s.TransactionHash.IsZero() && last.hash == s.TransactionHash && last.owner == owner {
+			e = last
+		}
+	}
+	if e == nil {
+		e = &entry{
+			date:     date,
+			payee:    j.payeeOf(s),
+			hash:     s.TransactionHash,
+			blockNum: s.BlockNumber,
+			txIndex:  s.TransactionIndex,
+			owner:    owner,
+		}
+		j.entries = append(j.entries, e)
+	}
+
+	inflows := []struct {
+		amount  *base.Wei
+		account string
+	}{
+		{&s.AmountIn, j.counterparty(s, j.accounts.Income, s.Sender)},
+		{&s.InternalIn, j.counterparty(s, j.accounts.Income, s.Sender)},
+		{&s.SelfDestructIn, j.counterparty(s, j.accounts.Income, s.Sender)},
+		{&s.MinerBaseRewardIn, j.account(j.accounts.Mining, s.AccountedFor)},
+		{&s.MinerNephewRewardIn, j.account(j.accounts.Mining, s.AccountedFor)},
+		{&s.MinerTxFeeIn, j.account(j.accounts.Mining, s.AccountedFor)},
+		{&s.MinerUncleRewardIn, j.account(j.accounts.Mining, s.AccountedFor)},
+		{&s.WithdrawalIn, j.account(j.accounts.Staking, s.AccountedFor)},
+		{&s.PrefundIn, j.account(j.accounts.Opening, s.AccountedFor)},
+		{&s.CorrectingIn, j.account(j.accounts.Reconciliation, s.AccountedFor)},
+	}
+	for _, in := range inflows {
+		if in.amount.BigInt().Sign() == 0 || in.account == self {
+			continue
+		}
+		if s.Sender != s.AccountedFor && j.owned[s.Sender] && in.account == j.account(j.accounts.Assets, s.Sender) {
+			// The sender's own statement journals transfers between owned addresses
+			continue
+		}
+		j.post(e, date, self, in.account, in.amount.BigInt(), c)
+	}
+
+	outflows := []struct {
+		amount  *base.Wei
+		account string
+	}{
+		{&s.AmountOut, j.counterparty(s, j.accounts.Expenses, s.Recipient)},
+		{&s.InternalOut, j.counterparty(s, j.accounts.Expenses, s.Recipient)},
+		{&s.SelfDestructOut, j.counterparty(s, j.accounts.Expenses, s.Recipient)},
+		{&s.GasOut, j.account(j.accounts.Gas, s.AccountedFor)},
+		{&s.CorrectingOut, j.account(j.accounts.Reconciliation, s.AccountedFor)},
+	}
+	for _, out := range outflows {
+		if out.amount.BigInt().Sign() == 0 || out.account == self {
+			continue
+		}
+		j.post(e, date, self, out.account, new(big.Int).Neg(out.amount.BigInt()), c)
+	}
+}
+
+// post adds amount to the asset account and subtracts it from the other account, merging
+// postings to the same account and commodity
+func (j *Journal) post(e *entry, date, asset, other string, amount *big.Int, c *commodity) {
+	for _, p := range []posting{
+		{account: asset, amount: new(big.Int).Set(amount), commodity: c},
+		{account: other, amount: new(big.Int).Neg(amount), commodity: c},
+	} {
+		if _, ok := j.opened[p.account]; !ok || date < j.opened[p.account] {
+			j.opened[p.account] = date
+		}
+		merged := false
+		for i := range e.postings {
+			if e.postings[i].account == p.account && e.postings[i].commodity == p.commodity {
+				e.postings[i].amount.Add(e.postings[i].amount, p.amount)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			e.postings = append(e.postings, p)
+		}
+	}
+}
+
+// Write writes the journal. Declarations come first followed by the price directives and the
+// entries in the order in which they were added.
+func (j *Journal) Write(w io.Writer) error {
+	var sb strings.Builder
+
+	first := ""
+	for _, e := range j.entries {
+		if first == "" || e.date < first {
+			first = e.date
+		}
+	}
+
+	if j.flavor == Beancount {
+		sb.WriteString(fmt.Sprintf("option \"operating_currency\" \"%s\"\n\n", quoteCurrency))
+	}
+
+	comms := make([]*commodity, 0, len(j.commodities))
+	for _, c := range j.commodities {
+		comms = append(comms, c)
+	}
+	sort.Slice(comms, func(i, k int) bool {
+		return comms[i].symbol < comms[k].symbol
+	})
+	for _, c := range comms {
+		switch j.flavor {
+		case Beancount:
+			sb.WriteString(fmt.Sprintf("%s commodity %s\n", first, c.symbol))
+			if name, ok := j.names[c.addr]; ok && len(name.Name) > 0 {
+				sb.WriteString(fmt.Sprintf("  name: \"%s\"\n", quoteString(name.Name)))
+			}
+			sb.WriteString(fmt.Sprintf("  address: \"%s\"\n", c.addr.Hex()))
+			sb.WriteString(fmt.Sprintf("  decimals: %d\n", c.decimals))
+		case Hledger:
+			sb.WriteString(fmt.Sprintf("commodity %s\n", j.commodityName(c)))
+			sb.WriteString(fmt.Sprintf("  ; address: %s\n", c.addr.Hex()))
+			sb.WriteString(fmt.Sprintf("  format %s %s\n", sampleAmount(c.decimals), j.commodityName(c)))
+		case Ledger:
+			sb.WriteString(fmt.Sprintf("commodity %s\n", j.commodityName(c)))
+			sb.WriteString(fmt.Sprintf("    note %s\n", c.addr.Hex()))
+			sb.WriteString(fmt.Sprintf("    format %s %s\n", sampleAmount(c.decimals), j.commodityName(c)))
+		}
+	}
+	if len(comms) > 0 {
+		sb.WriteString("\n")
+	}
+
+	accounts := make([]string, 0, len(j.opened))
+	for account := range j.opened {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	for _, account := range accounts {
+		if j.flavor == Beancount {
+			sb.WriteString(fmt.Sprintf("%s open %s\n", j.opened[account], account))
+		} else {
+			sb.WriteString(fmt.Sprintf("account %s\n", account))
+		}
+	}
+	if len(accounts) > 0 {
+		sb.WriteString("\n")
+	}
+
+	prices := make([]price, len(j.prices))
+	copy(prices, j.prices)
+	sort.SliceStable(prices, func(i, k int) bool {
+		if prices[i].date == prices[k].date {
+			return prices[i].commodity.symbol < prices[k].commodity.symbol
+		}
+		return prices[i].date < prices[k].date
+	})
+	for _, p := range prices {
+		value := strconv.FormatFloat(float64(p.price), 'f', -1, 64)
+		if j.flavor == Beancount {
+			sb.WriteString(fmt.Sprintf("%s price %s %s %s\n", p.date, p.commodity.symbol, value, quoteCurrency))
+		} else {
+			sb.WriteString(fmt.Sprintf("P %s %s %s %s\n", j.formatDate(p.date), j.commodityName(p.commodity), value, quoteCurrency))
+		}
+	}
+	if len(prices) > 0 {
+		sb.WriteString("\n")
+	}
+
+	for _, e := range j.entries {
+		postings := make([]posting, 0, len(e.postings))
+		for _, p := range e.postings {
+			if p.amount.Sign() != 0 {
+				postings = append(postings, p)
+			}
+		}
+		if len(postings) == 0 {
+			continue
+		}
+
+		id := fmt.Sprintf("%d.%d", e.blockNum, e.txIndex)
+		switch j.flavor {
+		case Beancount:
+			sb.WriteString(fmt.Sprintf("%s * \"%s\" \"%s\"\n", e.date, quoteString(e.payee), id))
+			if !e.hash.IsZero() {
+				sb.WriteString(fmt.Sprintf("  tx: \"%s\"\n", e.hash.Hex()))
+			}
+		case Hledger:
+			sb.WriteString(fmt.Sprintf("%s * %s | %s", e.date, cleanPayee(e.payee), id))
+			if !e.hash.IsZero() {
+				sb.WriteString(fmt.Sprintf("  ; tx:%s", e.hash.Hex()))
+			}
+			sb.WriteString("\n")
+		case Ledger:
+			sb.WriteString(fmt.Sprintf("%s * (%s) %s\n", j.formatDate(e.date), id, cleanPayee(e.payee)))
+			if !e.hash.IsZero() {
+				sb.WriteString(fmt.Sprintf("    ; tx: %s\n", e.hash.Hex()))
+			}
+		}
+
+		width := 0
+		for _, p := range postings {
+			width = max(width, len(p.account))
+		}
+		for _, p := range postings {
+			sb.WriteString(fmt.Sprintf("    %-*s  %s %s\n", width, p.account, formatUnits(p.amount, p.commodity.decimals), j.commodityName(p.commodity)))
+		}
+		sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// account expands the template with the label of the address
+func (j *Journal) account(template string, addr base.Address) string {
+	if !strings.Contains(template, "{name}") {
+		return template
+	}
+	return strings.ReplaceAll(template, "{name}", j.labelOf(addr))
+}
+
+// counterparty returns the account of an address on the other side of a transfer. Owned
+// addresses are always asset accounts. For consolidated statements, the entity's members
+// share the entity's asset account.
+func (j *Journal) counterparty(s *types.Statement, template string, addr base.Address) string {
+	if j.owned[addr] {
+		if len(s.Entity) > 0 {
+			return j.selfOf(s)
+		}
+		return j.account(j.accounts.Assets, addr)
+	}
+	return j.account(template, addr)
+}
+
+// selfOf returns the asset account of the statement's accounted for address (or, for a
+// consolidated statement, of its entity)
+func (j *Journal) selfOf(s *types.Statement) string {
+	if len(s.Entity) > 0 {
+		label := accountComponent(s.Entity)
+		if len(label) == 0 {
+			label = "Entity"
+		}
+		return strings.ReplaceAll(j.accounts.Assets, "{name}", label)
+	}
+	return j.account(j.accounts.Assets, s.AccountedFor)
+}
+
+// ownerOf identifies whose balances a statement reports
+func ownerOf(s *types.Statement) string {
+	if len(s.Entity) > 0 {
+		return "entity:" + s.Entity
+	}
+	return s.AccountedFor.Hex()
+}
+
+// labelOf returns the account name component for an address. Named addresses use their
+// name, others a shortened form of the address. Two addresses never share a label.
+func (j *Journal) labelOf(addr base.Address) string {
+	if label, ok := j.labels[addr]; ok {
+		return label
+	}
+
+	short := addr.Hex()[:10]
+	label := ""
+	if name, ok := j.names[addr]; ok {
+		label = accountComponent(name.Name)
+	}
+	if len(label) == 0 {
+		label = short
+	}
+	if owner, ok := j.labelOwners[label]; ok && owner != addr {
+		label = addr.Hex()
+	}
+
+	j.labels[addr] = label
+	j.labelOwners[label] = addr
+	return label
+}
+
+// payeeOf returns the display name of the counterparty of a statement
+func (j *Journal) payeeOf(s *types.Statement) string {
+	addr := s.Sender
+	if addr == s.AccountedFor {
+		addr = s.Recipient
+	}
+	if name, ok := j.names[addr]; ok && len(name.Name) > 0 {
+		return name.Name
+	}
+	return addr.Hex()
+}
+
+// commodityOf returns (declaring if needed) the commodity of the statement's asset
+func (j *Journal) commodityOf(s *types.Statement) *commodity {
+	if c, ok := j.commodities[s.AssetAddr]; ok {
+		return c
+	}
+
+	raw := s.AssetSymbol
+	if s.AssetAddr == base.FAKE_ETH_ADDRESS {
+		raw = j.native
+	}
+	symbol := j.symbolOf(raw, s.AssetAddr)
+	if owner, ok := j.symbols[symbol]; ok && owner != s.AssetAddr {
+		symbol = j.symbolOf(symbol+"-"+s.AssetAddr.Hex()[2:6], s.AssetAddr)
+	}
+
+	c := &commodity{
+		addr:     s.AssetAddr,
+		symbol:   symbol,
+		decimals: uint64(s.Decimals),
+	}
+	j.commodities[s.AssetAddr] = c
+	j.symbols[symbol] = s.AssetAddr
+	return c
+}
+
+// symbolOf makes a symbol acceptable to the journal's syntax. Beancount commodities are
+// upper case, start with a letter, and end with a letter or digit. ledger and hledger accept
+// nearly anything, but symbols other than letters must be quoted (see commodityName).
+func (j *Journal) symbolOf(raw string, addr base.Address) string {
+	var sb strings.Builder
+	for _, r := range strings.ToUpper(raw) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '_' || r == '-' || r == '\'' {
+			sb.WriteRune(r)
+		}
+	}
+	symbol := strings.TrimRight(sb.String(), ".-_'")
+	if len(symbol) == 0 {
+		symbol = "T" + strings.ToUpper(addr.Hex()[2:8])
+	}
+	if j.flavor == Beancount {
+		if symbol[0] < 'A' || symbol[0] > 'Z' {
+			symbol = "X" + symbol
+		}
+		if len(symbol) > 24 {
+			symbol = strings.TrimRight(symbol[:24], ".-_'")
+		}
+	}
+	return symbol
+}
+
+// commodityName returns the symbol as it appears in amounts
+func (j *Journal) commodityName(c *commodity) string {
+	if j.flavor == Beancount {
+		return c.symbol
+	}
+	for _, r := range c.symbol {
+		if r < 'A' || r > 'Z' {
+			return "\"" + c.symbol + "\""
+		}
+	}
+	return c.symbol
+}
+
+func (j *Journal) formatDate(date string) string {
+	if j.flavor == Ledger {
+		return strings.ReplaceAll(date, "-", "/")
+	}
+	return date
+}
+
+// accountComponent turns a name into a single account name component (for example,
+// "Uniswap V2: Router 2" becomes "Uniswap-V2-Router-2" and "Alice's Wallet" becomes
+// "Alices-Wallet")
+func accountComponent(name string) string {
+	words := strings.FieldsFunc(strings.ReplaceAll(name, "'", ""), func(r rune) bool {
+		return !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'))
+	})
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, "-")
+}
+
+func dateOf(ts base.Timestamp) string {
+	return time.Unix(int64(ts), 0).UTC().Format("2006-01-02")
+}
+
+// formatUnits returns the exact decimal value of an amount given in an asset's smallest unit
+func formatUnits(amount *big.Int, decimals uint64) string {
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	abs := new(big.Int).Abs(amount)
+	if decimals == 0 {
+		return sign + abs.String()
+	}
+
+	unit := new(big.Int).Exp(big.NewInt(10), new(big.Int).SetUint64(decimals), nil)
+	whole, frac := new(big.Int).QuoRem(abs, unit, new(big.Int))
+	fracStr := strings.TrimRight(fmt.Sprintf("%0*s", int(decimals), frac.String()), "0")
+	if len(fracStr) == 0 {
+		return sign + whole.String()
+	}
+	return sign + whole.String() + "." + fracStr
+}
+
+// sampleAmount is the sample amount of a commodity format directive (for example, 1000.00
+// for an asset with two decimals)
+func sampleAmount(decimals uint64) string {
+	if decimals == 0 {
+		return "1000"
+	}
+	return "1000." + strings.Repeat("0", int(decimals))
+}
+
+func quoteString(str string) string {
+	return strings.NewReplacer("\\", "", "\"", "'", "\n", " ").Replace(str)
+}
+
+func cleanPayee(str string) string {
+	return strings.NewReplacer("|", "-", ";", ",", "\n", " ").Replace(str)
+}
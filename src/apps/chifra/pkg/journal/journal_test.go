@@ -0,0 +1,346 @@
+package journal
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+var (
+	jan1   = base.Timestamp(1609459200) // 2021-01-01
+	alice  = base.HexToAddress("0x00000000000000000000000000000000000a11ce")
+	bob    = base.HexToAddress("0x0000000000000000000000000000000000000b0b")
+	router = base.HexToAddress("0x7a250d5630b4cf539739df2c5dacb4c659f2488d")
+	stable = base.HexToAddress("0x2791bca1f2de4661ed88a30c99a7a9449aa84174")
+	hash1  = base.HexToHash("0x1111111111111111111111111111111111111111111111111111111111111111")
+	hash2  = base.HexToHash("0x2222222222222222222222222222222222222222222222222222222222222222")
+)
+
+func ether(str string) base.Wei {
+	r, _ := new(big.Rat).SetString(str)
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)))
+	return base.Wei(*new(big.Int).Quo(r.Num(), r.Denom()))
+}
+
+// history receives ether and a token from the router in one transaction (with a prior balance)
+// then sends ether to an unnamed address paying gas
+func history() []types.Statement {
+	return []types.Statement{
+		{
+			AccountedFor:    alice,
+			Sender:          router,
+			Recipient:       alice,
+			TransactionHash: hash1,
+			BlockNumber:     100,
+			Timestamp:       jan1,
+			AssetAddr:       base.FAKE_ETH_ADDRESS,
+			AssetSymbol:     "WEI",
+			Decimals:        18,
+			SpotPrice:       2000,
+			PriceSource:     "maker",
+			BegBal:          ether("0.5"),
+			AmountIn:        ether("1.5"),
+		},
+		{
+			AccountedFor:    alice,
+			Sender:          router,
+			Recipient:       alice,
+			TransactionHash: hash1,
+			BlockNumber:     100,
+			LogIndex:        3,
+			Timestamp:       jan1,
+			AssetAddr:       stable,
+			AssetSymbol:     "usdc.e",
+			Decimals:        6,
+			SpotPrice:       1,
+			PriceSource:     "stable-coin",
+			AmountIn:        *base.NewWei(10250000),
+		},
+		{
+			AccountedFor:     alice,
+			Sender:           alice,
+			Recipient:        bob,
+			TransactionHash:  hash2,
+			BlockNumber:      200,
+			TransactionIndex: 4,
+			Timestamp:        jan1 + 86400,
+			AssetAddr:        base.FAKE_ETH_ADDRESS,
+			AssetSymbol:      "WEI",
+			Decimals:         18,
+			PriceSource:      "not-priced",
+			BegBal:           ether("2"),
+			AmountOut:        ether("1"),
+			GasOut:           ether("0.01"),
+		},
+	}
+}
+
+func render(t *testing.T, flavor string, statements []types.Statement, owned ...base.Address) string {
+	t.Helper()
+	names := map[base.Address]types.Name{
+		alice:  {Address: alice, Name: "Alice's Wallet"},
+		router: {Address: router, Name: "Uniswap V2: Router 2"},
+	}
+	j, err := NewJournal(flavor, "ETH", configtypes.JournalSettings{}, names, owned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range statements {
+		j.Add(&statements[i])
+	}
+	var buf bytes.Buffer
+	if err := j.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// checkBalanced fails if the postings of any entry do not sum to zero for each commodity
+func checkBalanced(t *testing.T, journal string) int {
+	t.Helper()
+	nEntries := 0
+	for _, block := range strings.Split(journal, "\n\n") {
+		sums := map[string]*big.Rat{}
+		isEntry := false
+		for _, line := range strings.Split(block, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) > 1 && strings.Contains(fields[1], "*") {
+				isEntry = true
+				continue
+			}
+			if !isEntry || !strings.HasPrefix(line, "    ") || strings.HasPrefix(strings.TrimSpace(line), ";") {
+				continue
+			}
+			if len(fields) != 3 {
+				t.Fatalf("unexpected posting %q", line)
+			}
+			amount, ok := new(big.Rat).SetString(fields[1])
+			if !ok {
+				t.Fatalf("bad amount in %q", line)
+			}
+			if sums[fields[2]] == nil {
+				sums[fields[2]] = new(big.Rat)
+			}
+			sums[fields[2]].Add(sums[fields[2]], amount)
+		}
+		if isEntry {
+			nEntries++
+			for commodity, sum := range sums {
+				if sum.Sign() != 0 {
+					t.Errorf("entry does not balance for %s (%s):\n%s", commodity, sum.FloatString(18), block)
+				}
+			}
+		}
+	}
+	return nEntries
+}
+
+func TestBeancount(t *testing.T) {
+	journal := render(t, Beancount, history())
+
+	expected := []string{
+		`option "operating_currency" "USD"`,
+		"2021-01-01 commodity ETH",
+		"2021-01-01 commodity USDC.E\n  address: \"" + stable.Hex() + "\"\n  decimals: 6",
+		"2021-01-01 open Assets:Alices-Wallet",
+		"2021-01-01 open Equity:Opening-Balances",
+		"2021-01-01 open Income:Uniswap-V2-Router-2",
+		"2021-01-02 open Expenses:0x00000000",
+		"2021-01-02 open Expenses:Gas",
+		"2021-01-01 price ETH 2000 USD",
+		"2021-01-01 price USDC.E 1 USD",
+		"2021-01-01 * \"Opening balance\" \"100.0\"",
+		"2021-01-01 * \"Uniswap V2: Router 2\" \"100.0\"\n  tx: \"" + hash1.Hex() + "\"",
+		"Income:Uniswap-V2-Router-2  -10.25 USDC.E",
+		"2021-01-02 * \"" + bob.Hex() + "\" \"200.4\"",
+		"Assets:Alices-Wallet  -1.01 ETH",
+		"Expenses:Gas          0.01 ETH",
+	}
+	for _, want := range expected {
+		if !strings.Contains(journal, want) {
+			t.Errorf("missing %q in:\n%s", want, journal)
+		}
+	}
+
+	if strings.Contains(journal, "price ETH 0") || strings.Count(journal, " price ") != 2 {
+		t.Errorf("unpriced statements should not produce price directives:\n%s", journal)
+	}
+
+	if n := checkBalanced(t, journal); n != 3 {
+		t.Errorf("expected 3 entries, got %d", n)
+	}
+}
+
+func TestHledgerAndLedger(t *testing.T) {
+	journal := render(t, Hledger, history())
+	for _, want := range []string{
+		"commodity \"USDC.E\"\n  ; address: " + stable.Hex() + "\n  format 1000.000000 \"USDC.E\"",
+		"account Income:Uniswap-V2-Router-2",
+		"P 2021-01-01 ETH 2000 USD",
+		"2021-01-01 * Uniswap V2: Router 2 | 100.0  ; tx:" + hash1.Hex(),
+		"10.25 \"USDC.E\"",
+	} {
+		if !strings.Contains(journal, want) {
+			t.Errorf("hledger: missing %q in:\n%s", want, journal)
+		}
+	}
+	checkBalanced(t, journal)
+
+	journal = render(t, Ledger, history())
+	for _, want := range []string{
+		"commodity ETH\n    note " + base.FAKE_ETH_ADDRESS.Hex(),
+		"P 2021/01/01 ETH 2000 USD",
+		"2021/01/02 * (200.4) " + bob.Hex() + "\n    ; tx: " + hash2.Hex(),
+	} {
+		if !strings.Contains(journal, want) {
+			t.Errorf("ledger: missing %q in:\n%s", want, journal)
+		}
+	}
+	checkBalanced(t, journal)
+}
+
+func TestOwnedTransfers(t *testing.T) {
+	// Alice sends one ether to Bob and both are exported. Only Alice's statement is journaled.
+	statements := []types.Statement{
+		{
+			AccountedFor:    alice,
+			Sender:          alice,
+			Recipient:       bob,
+			TransactionHash: hash1,
+			Timestamp:       jan1,
+			AssetAddr:       base.FAKE_ETH_ADDRESS,
+			Decimals:        18,
+			AmountOut:       ether("1"),
+		},
+		{
+			AccountedFor:    bob,
+			Sender:          alice,
+			Recipient:       bob,
+			TransactionHash: hash1,
+			Timestamp:       jan1,
+			AssetAddr:       base.FAKE_ETH_ADDRESS,
+			Decimals:        18,
+			AmountIn:        ether("1"),
+		},
+	}
+	journal := render(t, Beancount, statements, alice, bob)
+	if n := checkBalanced(t, journal); n != 1 {
+		t.Errorf("expected a single entry, got %d:\n%s", n, journal)
+	}
+	if !strings.Contains(journal, "Assets:0x00000000     1 ETH") {
+		t.Errorf("owned counterparty should be an asset account:\n%s", journal)
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	j, _ := NewJournal(Beancount, "", configtypes.JournalSettings{}, nil, nil)
+	first := j.commodityOf(&types.Statement{AssetAddr: stable, AssetSymbol: "1INCH"})
+	second := j.commodityOf(&types.Statement{AssetAddr: router, AssetSymbol: "1inch"})
+	native := j.commodityOf(&types.Statement{AssetAddr: base.FAKE_ETH_ADDRESS, AssetSymbol: "WEI"})
+	if first.symbol != "X1INCH" || second.symbol != "X1INCH-7A25" || native.symbol != "ETH" {
+		t.Errorf("unexpected symbols %s %s %s", first.symbol, second.symbol, native.symbol)
+	}
+
+	if _, err := NewJournal("quicken", "", configtypes.JournalSettings{}, nil, nil); err == nil {
+		t.Error("expected an error for an unknown syntax")
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		decimals uint64
+		want     string
+	}{
+		{0, 18, "0"},
+		{1, 18, "0.000000000000000001"},
+		{-1500000, 6, "-1.5"},
+		{2000000, 6, "2"},
+		{7, 0, "7"},
+	}
+	for _, tt := range tests {
+		if got := formatUnits(big.NewInt(tt.amount), tt.decimals); got != tt.want {
+			t.Errorf("formatUnits(%d, %d) = %s, want %s", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestEntityStatements(t *testing.T) {
+	// A consolidated statement in which Alice pays Bob (both members, flagged) and gas
+	statements := []types.Statement{
+		{
+			AccountedFor:    alice,
+			Sender:          alice,
+			Recipient:       bob,
+			TransactionHash: hash1,
+			Timestamp:       jan1,
+			AssetAddr:       base.FAKE_ETH_ADDRESS,
+			Decimals:        18,
+			BegBal:          ether("3"),
+			AmountIn:        ether("1"),
+			AmountOut:       ether("1"),
+			GasOut:          ether("0.5"),
+			Entity:          "Treasury DAO",
+			IntraEntity:     true,
+		},
+	}
+	journal := render(t, Beancount, statements, alice, bob)
+	if n := checkBalanced(t, journal); n != 2 {
+		t.Errorf("expected an opening entry and a gas entry, got %d:\n%s", n, journal)
+	}
+	for _, want := range []string{
+		"Assets:Treasury-DAO      3 ETH",
+		"Assets:Treasury-DAO  -0.5 ETH",
+		"Expenses:Gas         0.5 ETH",
+	} {
+		if !strings.Contains(journal, want) {
+			t.Errorf("missing %q in:\n%s", want, journal)
+		}
+	}
+	if strings.Contains(journal, "Assets:Alices-Wallet") || strings.Contains(journal, "Assets:0x") {
+		t.Errorf("members should share the entity's account:\n%s", journal)
+	}
+}
+
+func TestZeroHashStatements(t *testing.T) {
+	// Two withdrawals in consecutive blocks carry no transaction hash
+	statements := []types.Statement{
+		{
+			AccountedFor:     alice,
+			Sender:           base.WithdrawalSender,
+			Recipient:        alice,
+			BlockNumber:      100,
+			TransactionIndex: 99995,
+			Timestamp:        jan1,
+			AssetAddr:        base.FAKE_ETH_ADDRESS,
+			Decimals:         18,
+			WithdrawalIn:     ether("0.25"),
+		},
+		{
+			AccountedFor:     alice,
+			Sender:           base.WithdrawalSender,
+			Recipient:        alice,
+			BlockNumber:      101,
+			TransactionIndex: 99995,
+			Timestamp:        jan1 + 12,
+			AssetAddr:        base.FAKE_ETH_ADDRESS,
+			Decimals:         18,
+			BegBal:           ether("0.25"),
+			WithdrawalIn:     ether("0.5"),
+		},
+	}
+	journal := render(t, Beancount, statements)
+	if n := checkBalanced(t, journal); n != 2 {
+		t.Errorf("expected an entry for each withdrawal, got %d:\n%s", n, journal)
+	}
+	for _, want := range []string{"\"100.99995\"", "\"101.99995\"", "Assets:Alices-Wallet  0.25 ETH", "Assets:Alices-Wallet  0.5 ETH"} {
+		if !strings.Contains(journal, want) {
+			t.Errorf("missing %q in:\n%s", want, journal)
+		}
+	}
+}
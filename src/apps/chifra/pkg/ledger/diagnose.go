@@ -0,0 +1,231 @@
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// The classifications reported by Diagnose
+const (
+	MissedTransfer = "missed-transfer"
+	UnindexedMint  = "unindexed-mint"
+	Rebasing       = "rebasing"
+	FeeOnTransfer  = "fee-on-transfer"
+	IntraBlock     = "intra-block"
+	NullTransfer   = "null-transfer"
+	Unknown        = "unknown"
+)
+
+// balanceAtFunc returns the accounted for address's balance of a token at the end of a block
+type balanceAtFunc func(bn base.Blknum) (*base.Wei, error)
+
+// NeedsDiagnosis returns true if the statement is a fungible token statement that did not
+// reconcile without correcting entries.
+func NeedsDiagnosis(s *types.Statement) bool {
+	if s.IsEth() || s.IsNft() {
+		return false
+	}
+	return len(s.CorrectingReason) > 0 || !s.Reconciled()
+}
+
+// Diagnose explains why a token statement did not reconcile. If the balance at the start of the
+// statement's block differs from the balance at the previous appearance, it bisects the blocks in
+// between with balanceOf to find the block at which the balance changed and looks in that block for
+// a transfer the index did not report. If the balance changed by a different amount than the
+// transfer within the statement's block, it compares the two. The result holds one record for each
+// drift found.
+func (l *Ledger) Diagnose(conn *rpc.Connection, s *types.Statement) ([]types.Discrepancy, error) {
+	if !NeedsDiagnosis(s) {
+		return []types.Discrepancy{}, nil
+	}
+
+	key := l.ctxKey(s.BlockNumber, s.TransactionIndex)
+	ctx := l.Contexts[key]
+	if ctx == nil {
+		return []types.Discrepancy{}, fmt.Errorf("no context for statement at %d.%d", s.BlockNumber, s.TransactionIndex)
+	}
+
+	balanceAt := func(bn base.Blknum) (*base.Wei, error) {
+		bal, err := conn.GetBalanceAtToken(s.AssetAddr, l.AccountFor, fmt.Sprintf("0x%x", bn))
+		if bal == nil && err == nil {
+			err = fmt.Errorf("no balance for %s at block %d", s.AssetAddr.Hex(), bn)
+		}
+		return bal, err
+	}
+
+	logsAt := func(bn base.Blknum) ([]types.Log, error) {
+		return conn.GetLogsByNumber(bn, conn.GetBlockTimestamp(bn))
+	}
+
+	return l.diagnose(s, ctx, balanceAt, logsAt)
+}
+
+func (l *Ledger) diagnose(s *types.Statement, ctx *ledgerContext, balanceAt balanceAtFunc, logsAt func(base.Blknum) ([]types.Log, error)) ([]types.Discrepancy, error) {
+	newDiscrepancy := func() types.Discrepancy {
+		return types.Discrepancy{
+			AccountedFor:     s.AccountedFor,
+			AssetAddr:        s.AssetAddr,
+			AssetSymbol:      s.AssetSymbol,
+			Decimals:         s.Decimals,
+			BlockNumber:      s.BlockNumber,
+			TransactionIndex: s.TransactionIndex,
+			LogIndex:         s.LogIndex,
+			TransactionHash:  s.TransactionHash,
+			Timestamp:        s.Timestamp,
+			PrevBal:          s.PrevBal,
+			BegBal:           s.BegBal,
+			EndBal:           s.EndBal,
+			AmountNet:        *new(base.Wei).Sub(&s.AmountIn, &s.AmountOut),
+			CorrectingReason: s.CorrectingReason,
+		}
+	}
+
+	ret := []types.Discrepancy{}
+	if s.CorrectingReason == NullTransfer {
+		d := newDiscrepancy()
+		d.AmountNet = s.CorrectingIn
+		d.DriftBlock = s.BlockNumber
+		d.DriftAmount = *new(base.Wei).Sub(&s.EndBal, &s.BegBal)
+		d.Classification = NullTransfer
+		return append(ret, d), nil
+	}
+
+	// The balance moved between the previous appearance and the start of this block
+	if begDrift := new(base.Wei).Sub(&s.BegBal, &s.PrevBal); !begDrift.IsZero() {
+		d := newDiscrepancy()
+		if ctx.PrevBlock+1 < ctx.CurBlock {
+			block, bal, probes, err := bisectDrift(ctx.PrevBlock, ctx.CurBlock-1, &s.PrevBal, &s.BegBal, balanceAt)
+			if err != nil {
+				return ret, err
+			}
+			d.DriftBlock = block
+			d.DriftAmount = *new(base.Wei).Sub(bal, &s.PrevBal)
+			d.Probes = probes
+		} else {
+			// The previous appearance is in this block, so the drift is another transfer in this block
+			d.DriftBlock = ctx.CurBlock
+			d.DriftAmount = *begDrift
+		}
+
+		logs, err := logsAt(d.DriftBlock)
+		if err != nil {
+			return ret, err
+		}
+		d.Classification, d.DriftHash = classifyBlockDrift(s.AccountedFor, s.AssetAddr, logs)
+		if d.DriftBlock == ctx.CurBlock && d.Classification != Rebasing {
+			d.Classification = IntraBlock
+		}
+		ret = append(ret, d)
+	}
+
+	// The balance moved by a different amount than the transfer during this block
+	actual := new(base.Wei).Sub(&s.EndBal, &s.BegBal)
+	expected := new(base.Wei).Sub(&s.AmountIn, &s.AmountOut)
+	if endDrift := new(base.Wei).Sub(actual, expected); !endDrift.IsZero() {
+		d := newDiscrepancy()
+		d.DriftBlock = s.BlockNumber
+		d.DriftAmount = *endDrift
+
+		logs, err := logsAt(s.BlockNumber)
+		if err != nil {
+			return ret, err
+		}
+		d.Classification = classifyTxDrift(expected, actual, countTransfers(s.AccountedFor, s.AssetAddr, logs))
+		ret = append(ret, d)
+	}
+
+	if len(ret) == 0 {
+		d := newDiscrepancy()
+		d.DriftBlock = s.BlockNumber
+		d.Classification = Unknown
+		ret = append(ret, d)
+	}
+
+	return ret, nil
+}
+
+// bisectDrift searches the blocks after lo up to and including hi (whose balances are loBal and
+// hiBal, which differ) for a block at which the balance changed from loBal. It returns that block,
+// the balance at that block, and the number of balances it requested. If the balance changed more
+// than once, the block found is one of the changes, not necessarily the first.
+func bisectDrift(lo, hi base.Blknum, loBal, hiBal *base.Wei, balanceAt balanceAtFunc) (base.Blknum, *base.Wei, uint64, error) {
+	probes := uint64(0)
+	bal := hiBal
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		midBal, err := balanceAt(mid)
+		probes++
+		if err != nil {
+			return 0, nil, probes, err
+		}
+		if midBal.Cmp(loBal) == 0 {
+			lo = mid
+		} else {
+			hi, bal = mid, midBal
+		}
+	}
+	return hi, bal, probes, nil
+}
+
+// transferParties returns the sender and recipient of an ERC-20 Transfer log emitted by the asset
+func transferParties(asset base.Address, log *types.Log) (base.Address, base.Address, bool) {
+	if log.Address != asset || len(log.Topics) != 3 || log.Topics[0] != transferTopic {
+		return base.ZeroAddr, base.ZeroAddr, false
+	}
+	return base.HexToAddress(log.Topics[1].Hex()), base.HexToAddress(log.Topics[2].Hex()), true
+}
+
+// classifyBlockDrift looks for a transfer of the asset to or from the address among a block's logs.
+// A transfer from the zero address is an unindexed mint. Any other transfer is one that was missed.
+// If there is no transfer, the balance changed without an event (for example, a rebasing token).
+func classifyBlockDrift(addr, asset base.Address, logs []types.Log) (string, base.Hash) {
+	for i := range logs {
+		sender, recipient, ok := transferParties(asset, &logs[i])
+		if !ok || (sender != addr && recipient != addr) {
+			continue
+		}
+		if sender.IsZero() {
+			return UnindexedMint, logs[i].TransactionHash
+		}
+		return MissedTransfer, logs[i].TransactionHash
+	}
+	return Rebasing, base.Hash{}
+}
+
+// countTransfers returns the number of transfers of the asset to or from the address among the logs
+func countTransfers(addr, asset base.Address, logs []types.Log) int {
+	cnt := 0
+	for i := range logs {
+		if sender, recipient, ok := transferParties(asset, &logs[i]); ok && (sender == addr || recipient == addr) {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// classifyTxDrift classifies a difference between the expected and actual change in balance during a
+// block. If more than one transfer touched the address in the block, each statement sees the others'
+// movements. Otherwise, receiving less (or sending more) than the transfer claims is a fee on transfer.
+func classifyTxDrift(expected, actual *base.Wei, transfers int) string {
+	if transfers > 1 {
+		return IntraBlock
+	}
+
+	if actual.Cmp(expected) < 0 {
+		if expected.BigInt().Sign() > 0 && actual.BigInt().Sign() >= 0 {
+			return FeeOnTransfer
+		}
+		if expected.BigInt().Sign() < 0 {
+			return FeeOnTransfer
+		}
+	}
+
+	if transfers == 0 {
+		return Rebasing
+	}
+
+	return Unknown
+}
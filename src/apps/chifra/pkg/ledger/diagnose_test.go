@@ -0,0 +1,101 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestBisectDrift(t *testing.T) {
+	// The balance is 100 through block 1336 and 150 from block 1337 on
+	balanceAt := func(bn base.Blknum) (*base.Wei, error) {
+		if bn < 1337 {
+			return base.NewWei(100), nil
+		}
+		return base.NewWei(150), nil
+	}
+
+	block, bal, probes, err := bisectDrift(1000, 2000, base.NewWei(100), base.NewWei(150), balanceAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block != 1337 || bal.Text(10) != "150" {
+		t.Error("wrong drift", block, bal)
+	}
+	if probes > 10 {
+		t.Error("too many probes", probes)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	acct := base.HexToAddress("0x00000000000000000000000000000000000000aa")
+	other := base.HexToAddress("0x00000000000000000000000000000000000000bb")
+	token := base.HexToAddress("0x00000000000000000000000000000000000000cc")
+	missed := base.HexToHash("0x01")
+
+	transfer := func(from, to base.Address, hash base.Hash) types.Log {
+		return types.Log{
+			Address:         token,
+			Topics:          []base.Hash{transferTopic, base.HexToHash(from.Hex()), base.HexToHash(to.Hex())},
+			TransactionHash: hash,
+		}
+	}
+
+	statement := func(prev, beg, end, in int64) *types.Statement {
+		return &types.Statement{
+			AccountedFor:     acct,
+			AssetAddr:        token,
+			BlockNumber:      2000,
+			PrevBal:          *base.NewWei(prev),
+			BegBal:           *base.NewWei(beg),
+			EndBal:           *base.NewWei(end),
+			AmountIn:         *base.NewWei(in),
+			CorrectingReason: "begbal",
+		}
+	}
+
+	balanceAt := func(bn base.Blknum) (*base.Wei, error) {
+		if bn < 1500 {
+			return base.NewWei(100), nil
+		}
+		return base.NewWei(150), nil
+	}
+
+	tests := []struct {
+		name     string
+		s        *types.Statement
+		logs     []types.Log
+		expected []string
+	}{
+		{"missed", statement(100, 150, 160, 10), []types.Log{transfer(other, acct, missed)}, []string{MissedTransfer}},
+		{"mint", statement(100, 150, 160, 10), []types.Log{transfer(base.ZeroAddr, acct, missed)}, []string{UnindexedMint}},
+		{"rebasing", statement(100, 150, 160, 10), []types.Log{transfer(other, base.ZeroAddr, missed)}, []string{Rebasing}},
+		{"fee", statement(150, 150, 159, 10), []types.Log{transfer(other, acct, missed)}, []string{FeeOnTransfer}},
+		{"both", statement(100, 150, 159, 10), []types.Log{transfer(other, acct, missed)}, []string{MissedTransfer, FeeOnTransfer}},
+		{"intra", statement(150, 150, 170, 10), []types.Log{transfer(other, acct, missed), transfer(other, acct, missed)}, []string{IntraBlock}},
+	}
+
+	l := &Ledger{AccountFor: acct}
+	ctx := newLedgerContext(1000, 2000, 3000, false, false, false)
+	for _, test := range tests {
+		logsAt := func(bn base.Blknum) ([]types.Log, error) {
+			return test.logs, nil
+		}
+		got, err := l.diagnose(test.s, ctx, balanceAt, logsAt)
+		if err != nil {
+			t.Fatal(test.name, err)
+		}
+		if len(got) != len(test.expected) {
+			t.Fatal(test.name, "wrong number of discrepancies", got)
+		}
+		for i, d := range got {
+			if d.Classification != test.expected[i] {
+				t.Error(test.name, "got", d.Classification, "expected", test.expected[i])
+			}
+		}
+		if test.name == "missed" && (got[0].DriftBlock != 1500 || got[0].DriftAmount.Text(10) != "50" || got[0].DriftHash != missed) {
+			t.Error("wrong drift", got[0])
+		}
+	}
+}
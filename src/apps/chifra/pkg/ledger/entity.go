@@ -0,0 +1,335 @@
+package ledger
+
+import (
+	"bufio"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/names"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// The ways in which Consolidate treats transfers between the members of an entity
+const (
+	Eliminate = "eliminate"
+	Flag      = "flag"
+)
+
+// EntityCorrection is the correcting reason of a consolidated statement that does not reconcile
+// from the sum of its members' statements (for example, when an internal transfer between two
+// members is reported with the transaction's sender)
+const EntityCorrection = "entity"
+
+// Entity is a named set of addresses (for example, the wallets of a treasury) whose statements
+// are consolidated as if they were a single account.
+type Entity struct {
+	Name    string
+	Members []base.Address
+	members map[base.Address]bool
+}
+
+// NewEntity returns an entity with the given members. Duplicates are ignored.
+func NewEntity(name string, members []base.Address) *Entity {
+	e := &Entity{
+		Name:    name,
+		Members: make([]base.Address, 0, len(members)),
+		members: make(map[base.Address]bool, len(members)),
+	}
+	for _, addr := range members {
+		if !e.members[addr] {
+			e.members[addr] = true
+			e.Members = append(e.Members, addr)
+		}
+	}
+	return e
+}
+
+// IsMember returns true if the address belongs to the entity
+func (e *Entity) IsMember(addr base.Address) bool {
+	return e.members[addr]
+}
+
+// LoadEntity returns the entity described by spec. If spec is the path of a file, the entity is
+// named for the file and each line of the file (other than blank lines and comments starting
+// with #) starts with a member's address. Otherwise, the entity's members are the addresses in
+// the names database carrying spec as a tag (see names.HasTag).
+func LoadEntity(chain, spec string) (*Entity, error) {
+	if file.FileExists(spec) {
+		return loadEntityFile(spec)
+	}
+
+	tagged, err := names.LoadTaggedAddresses(chain, spec)
+	if err != nil {
+		return nil, fmt.Errorf("entity %s is neither a file nor a tag in the names database: %w", spec, err)
+	}
+
+	members := make([]base.Address, 0, len(tagged))
+	for addr := range tagged {
+		members = append(members, addr)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Hex() < members[j].Hex()
+	})
+	return NewEntity(spec, members), nil
+}
+
+func loadEntityFile(path string) (*Entity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	members := make([]base.Address, 0)
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if !base.IsValidAddress(fields[0]) {
+			return nil, fmt.Errorf("invalid address %s at line %d of %s", fields[0], lineNum, path)
+		}
+		members = append(members, base.HexToAddress(fields[0]))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("entity file %s contains no addresses", path)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return NewEntity(name, members), nil
+}
+
+// assetKey identifies a consolidated balance. Each token id of a non-fungible token is its own
+// balance.
+type assetKey struct {
+	addr    base.Address
+	tokenId string
+}
+
+func cloneWei(w *base.Wei) *base.Wei {
+	return (*base.Wei)(new(big.Int).Set(w.BigInt()))
+}
+
+func keyOf(s *types.Statement) assetKey {
+	return assetKey{addr: s.AssetAddr, tokenId: s.TokenId.String()}
+}
+
+// Consolidate combines the statements of the entity's members into one statement per transaction
+// and asset. The consolidated balances are the sums of the members' balances (members with no
+// statement in a transaction contribute their most recent ending balance). Transfers between
+// members are either removed from the amounts (Eliminate), in which case a statement that did
+// nothing but move an asset within the entity is dropped, or kept and the statement marked as
+// intra-entity (Flag). Each statement is accounted for by the first member appearing in it.
+func (e *Entity) Consolidate(statements []types.Statement, mode string) []types.Statement {
+	sorted := make([]types.Statement, 0, len(statements))
+	for _, s := range statements {
+		if e.IsMember(s.AccountedFor) {
+			sorted = append(sorted, s)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].BlockNumber == sorted[j].BlockNumber {
+			return sorted[i].TransactionIndex < sorted[j].TransactionIndex
+		}
+		return sorted[i].BlockNumber < sorted[j].BlockNumber
+	})
+
+	// A member's balance before its first statement is that statement's beginning balance
+	type memberKey struct {
+		member base.Address
+		asset  assetKey
+	}
+	current := make(map[memberKey]*base.Wei)
+	totals := make(map[assetKey]*base.Wei)
+	lastGroup := make(map[assetKey]int)
+	for i := range sorted {
+		s := &sorted[i]
+		mk := memberKey{s.AccountedFor, keyOf(s)}
+		if current[mk] == nil {
+			current[mk] = cloneWei(&s.BegBal)
+			if totals[mk.asset] == nil {
+				totals[mk.asset] = new(base.Wei)
+			}
+			totals[mk.asset].Add(totals[mk.asset], &s.BegBal)
+		}
+	}
+
+	// Statements of the same transaction and asset form a group
+	groups := make([][]*types.Statement, 0, len(sorted))
+	index := make(map[string]int)
+	for i := range sorted {
+		s := &sorted[i]
+		id := fmt.Sprintf("%d.%d.%s.%s", s.BlockNumber, s.TransactionIndex, s.AssetAddr.Hex(), s.TokenId.String())
+		if g, ok := index[id]; ok {
+			groups[g] = append(groups[g], s)
+		} else {
+			index[id] = len(groups)
+			groups = append(groups, []*types.Statement{s})
+		}
+	}
+	for g, group := range groups {
+		lastGroup[keyOf(group[0])] = g
+	}
+
+	ret := make([]types.Statement, 0, len(groups))
+	prevEnd := make(map[assetKey]*base.Wei)
+	for g, group := range groups {
+		ak := keyOf(group[0])
+		cs := e.consolidateGroup(group, mode)
+
+		// Beginning balances come from each member's first statement in the group, ending
+		// balances from its last. Other members have not changed.
+		begBal := cloneWei(totals[ak])
+		endBal := cloneWei(totals[ak])
+		seen := make(map[base.Address]bool)
+		for i, s := range group {
+			mk := memberKey{s.AccountedFor, ak}
+			if !seen[s.AccountedFor] {
+				seen[s.AccountedFor] = true
+				begBal.Add(begBal, new(base.Wei).Sub(&s.BegBal, current[mk]))
+			}
+			isLast := true
+			for _, later := range group[i+1:] {
+				if later.AccountedFor == s.AccountedFor {
+					isLast = false
+					break
+				}
+			}
+			if isLast {
+				endBal.Add(endBal, new(base.Wei).Sub(&s.EndBal, current[mk]))
+				current[mk] = cloneWei(&s.EndBal)
+			}
+		}
+		totals[ak] = endBal
+
+		cs.BegBal = *begBal
+		cs.EndBal = *endBal
+		cs.ReconType &^= types.First | types.Last
+		if prev, ok := prevEnd[ak]; ok {
+			cs.PrevBal = *prev
+		} else {
+			cs.PrevBal = *begBal
+			cs.ReconType |= types.First
+		}
+		if lastGroup[ak] == g {
+			cs.ReconType |= types.Last
+		}
+		prevEnd[ak] = cloneWei(endBal)
+
+		if diff := new(base.Wei).Sub(&cs.EndBal, cs.EndBalCalc()); diff.Cmp(base.NewWei(0)) != 0 {
+			if diff.Cmp(base.NewWei(0)) > 0 {
+				cs.CorrectingIn = *new(base.Wei).Add(&cs.CorrectingIn, diff)
+			} else {
+				cs.CorrectingOut = *new(base.Wei).Sub(&cs.CorrectingOut, diff)
+			}
+			if len(cs.CorrectingReason) > 0 {
+				cs.CorrectingReason += "-"
+			}
+			cs.CorrectingReason += EntityCorrection
+		}
+
+		if mode == Eliminate && !cs.IsMaterial() && cs.BegBal.Cmp(&cs.EndBal) == 0 {
+			continue
+		}
+		ret = append(ret, cs)
+	}
+
+	return ret
+}
+
+// consolidateGroup sums the amounts of a group of statements from the same transaction and
+// asset. Balances are set by the caller.
+func (e *Entity) consolidateGroup(group []*types.Statement, mode string) types.Statement {
+	first := group[0]
+	ret := types.Statement{
+		AccountedFor:     first.AccountedFor,
+		Sender:           first.Sender,
+		Recipient:        first.Recipient,
+		BlockNumber:      first.BlockNumber,
+		TransactionIndex: first.TransactionIndex,
+		TransactionHash:  first.TransactionHash,
+		LogIndex:         first.LogIndex,
+		Timestamp:        first.Timestamp,
+		AssetAddr:        first.AssetAddr,
+		AssetSymbol:      first.AssetSymbol,
+		AssetType:        first.AssetType,
+		Decimals:         first.Decimals,
+		TokenId:          first.TokenId,
+		SpotPrice:        first.SpotPrice,
+		PriceSource:      first.PriceSource,
+		FiatCurrency:     first.FiatCurrency,
+		FxRate:           first.FxRate,
+		ReconType:        first.ReconType,
+		Entity:           e.Name,
+	}
+
+	add := func(dest, src *base.Wei) {
+		*dest = *new(base.Wei).Add(dest, src)
+	}
+
+	reasons := make([]string, 0)
+	for _, s := range group {
+		intraIn := e.IsMember(s.Sender)
+		intraOut := e.IsMember(s.Recipient)
+
+		ins := []struct{ dest, src *base.Wei }{
+			{&ret.AmountIn, &s.AmountIn},
+			{&ret.InternalIn, &s.InternalIn},
+			{&ret.SelfDestructIn, &s.SelfDestructIn},
+		}
+		for _, in := range ins {
+			if intraIn && !in.src.IsZero() {
+				ret.IntraEntity = true
+				if mode == Eliminate {
+					continue
+				}
+			}
+			add(in.dest, in.src)
+		}
+
+		outs := []struct{ dest, src *base.Wei }{
+			{&ret.AmountOut, &s.AmountOut},
+			{&ret.InternalOut, &s.InternalOut},
+			{&ret.SelfDestructOut, &s.SelfDestructOut},
+		}
+		for _, out := range outs {
+			if intraOut && !out.src.IsZero() {
+				ret.IntraEntity = true
+				if mode == Eliminate {
+					continue
+				}
+			}
+			add(out.dest, out.src)
+		}
+
+		add(&ret.MinerBaseRewardIn, &s.MinerBaseRewardIn)
+		add(&ret.MinerNephewRewardIn, &s.MinerNephewRewardIn)
+		add(&ret.MinerTxFeeIn, &s.MinerTxFeeIn)
+		add(&ret.MinerUncleRewardIn, &s.MinerUncleRewardIn)
+		add(&ret.WithdrawalIn, &s.WithdrawalIn)
+		add(&ret.PrefundIn, &s.PrefundIn)
+		add(&ret.CorrectingIn, &s.CorrectingIn)
+		add(&ret.CorrectingOut, &s.CorrectingOut)
+		add(&ret.GasOut, &s.GasOut)
+
+		if len(s.CorrectingReason) > 0 {
+			reasons = append(reasons, s.CorrectingReason)
+		}
+	}
+	ret.CorrectingReason = strings.Join(reasons, "-")
+
+	return ret
+}
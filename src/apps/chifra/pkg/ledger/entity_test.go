@@ -0,0 +1,151 @@
+package ledger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+var (
+	memberA  = base.HexToAddress("0x00000000000000000000000000000000000000aa")
+	memberB  = base.HexToAddress("0x00000000000000000000000000000000000000bb")
+	outsider = base.HexToAddress("0x00000000000000000000000000000000000000cc")
+	token    = base.HexToAddress("0x00000000000000000000000000000000000000dd")
+)
+
+// entityHistory has an outsider pay A, A pay B (plus a token transfer from A to B in the same
+// transaction), and B pay the outsider. B's first statement is in the second transaction.
+func entityHistory() []types.Statement {
+	eth := func(acct, from, to base.Address, bn base.Blknum, beg, in, out, gas, end int64) types.Statement {
+		return types.Statement{
+			AccountedFor:     acct,
+			Sender:           from,
+			Recipient:        to,
+			BlockNumber:      bn,
+			TransactionIndex: 1,
+			AssetAddr:        base.FAKE_ETH_ADDRESS,
+			AssetSymbol:      "WEI",
+			BegBal:           *base.NewWei(beg),
+			PrevBal:          *base.NewWei(beg),
+			AmountIn:         *base.NewWei(in),
+			AmountOut:        *base.NewWei(out),
+			GasOut:           *base.NewWei(gas),
+			EndBal:           *base.NewWei(end),
+		}
+	}
+	tok := eth(memberA, memberA, memberB, 20, 100, 0, 50, 0, 50)
+	tok.AssetAddr = token
+	tokB := eth(memberB, memberA, memberB, 20, 0, 50, 0, 0, 50)
+	tokB.AssetAddr = token
+
+	return []types.Statement{
+		eth(memberA, outsider, memberA, 10, 5, 10, 0, 0, 15),
+		eth(memberA, memberA, memberB, 20, 15, 0, 4, 1, 10),
+		tok,
+		eth(memberB, memberA, memberB, 20, 3, 4, 0, 0, 7),
+		tokB,
+		eth(memberB, memberB, outsider, 30, 7, 0, 2, 0, 5),
+		eth(outsider, outsider, memberA, 10, 99, 0, 10, 0, 89), // not a member, ignored
+	}
+}
+
+func TestConsolidateEliminate(t *testing.T) {
+	entity := NewEntity("treasury", []base.Address{memberA, memberB, memberA})
+	if len(entity.Members) != 2 || !entity.IsMember(memberB) || entity.IsMember(outsider) {
+		t.Fatal("unexpected members", entity.Members)
+	}
+
+	got := entity.Consolidate(entityHistory(), Eliminate)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 statements (the intra-entity token transfer is dropped), got %d", len(got))
+	}
+
+	expected := []struct {
+		bn                     base.Blknum
+		beg, in, out, gas, end int64
+		intra, first, last     bool
+		accountedFor           base.Address
+	}{
+		{10, 8, 10, 0, 0, 18, false, true, false, memberA},
+		{20, 18, 0, 0, 1, 17, true, false, false, memberA},
+		{30, 17, 0, 2, 0, 15, false, false, true, memberB},
+	}
+	for i, want := range expected {
+		s := got[i]
+		if s.BlockNumber != want.bn || s.BegBal.Cmp(base.NewWei(want.beg)) != 0 || s.AmountIn.Cmp(base.NewWei(want.in)) != 0 ||
+			s.AmountOut.Cmp(base.NewWei(want.out)) != 0 || s.GasOut.Cmp(base.NewWei(want.gas)) != 0 || s.EndBal.Cmp(base.NewWei(want.end)) != 0 {
+			t.Errorf("statement %d: got beg %s in %s out %s gas %s end %s", i, s.BegBal.String(), s.AmountIn.String(), s.AmountOut.String(), s.GasOut.String(), s.EndBal.String())
+		}
+		if s.IntraEntity != want.intra || (s.ReconType&types.First != 0) != want.first || (s.ReconType&types.Last != 0) != want.last {
+			t.Errorf("statement %d: intra %t reconType %s", i, s.IntraEntity, s.ReconType.String())
+		}
+		if !s.Reconciled() || len(s.CorrectingReason) > 0 {
+			t.Errorf("statement %d does not reconcile (%s)", i, s.CorrectingReason)
+		}
+		if s.Entity != "treasury" || s.AccountedFor != want.accountedFor {
+			t.Errorf("statement %d: entity %s accountedFor %s", i, s.Entity, s.AccountedFor.Hex())
+		}
+	}
+}
+
+func TestConsolidateFlag(t *testing.T) {
+	entity := NewEntity("treasury", []base.Address{memberA, memberB})
+	got := entity.Consolidate(entityHistory(), Flag)
+	if len(got) != 4 {
+		t.Fatalf("expected 4 statements, got %d", len(got))
+	}
+
+	for _, s := range got {
+		if !s.Reconciled() {
+			t.Errorf("statement at %d for %s does not reconcile", s.BlockNumber, s.AssetAddr.Hex())
+		}
+		if s.BlockNumber == 20 {
+			if !s.IntraEntity || s.AmountIn.Cmp(&s.AmountOut) != 0 || s.AmountIn.IsZero() {
+				t.Errorf("intra-entity amounts should be kept and flagged: in %s out %s", s.AmountIn.String(), s.AmountOut.String())
+			}
+		}
+	}
+}
+
+func TestConsolidateCorrection(t *testing.T) {
+	// Only A's side of the transfer to B is present, so the entity's balance drops without an outflow
+	history := entityHistory()
+	history = append(history[:3], history[5])
+
+	entity := NewEntity("treasury", []base.Address{memberA, memberB})
+	got := entity.Consolidate(history, Eliminate)
+	for _, s := range got {
+		if !s.Reconciled() {
+			t.Errorf("statement at %d does not reconcile", s.BlockNumber)
+		}
+		if s.BlockNumber == 20 && s.IsEth() && (s.CorrectingReason != EntityCorrection || s.CorrectingOut.Cmp(base.NewWei(4)) != 0) {
+			t.Errorf("expected an entity correction of 4, got %s %s", s.CorrectingReason, s.CorrectingOut.String())
+		}
+	}
+}
+
+func TestLoadEntityFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "treasury.csv")
+	contents := "# the treasury\n" + memberA.Hex() + ",hot wallet\n\n" + memberB.Hex() + "\n"
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entity, err := LoadEntity("mainnet", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entity.Name != "treasury" || len(entity.Members) != 2 || entity.Members[0] != memberA {
+		t.Errorf("unexpected entity %s %v", entity.Name, entity.Members)
+	}
+
+	if err := os.WriteFile(path, []byte("not-an-address\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadEntity("mainnet", path); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+}
@@ -0,0 +1,69 @@
+package ledger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/replay"
+)
+
+// TestMain gives the tests a configuration if the machine running them has none. The tests that
+// use fixtures never reach the node it names, so unless recording, the node is not checked either.
+func TestMain(m *testing.M) {
+	if len(os.Getenv("TB_RPC_RECORD")) == 0 {
+		os.Setenv("TB_NO_PROVIDER_CHECK", "true")
+	}
+
+	folder := ""
+	if !file.FileExists(filepath.Join(config.PathToRootConfig(), "trueBlocks.toml")) {
+		var err error
+		if folder, err = os.MkdirTemp("", "ledger"); err == nil {
+			err = os.WriteFile(filepath.Join(folder, "trueBlocks.toml"), []byte(fixturesConfig), 0644)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Setenv("XDG_CONFIG_HOME", folder)
+	}
+
+	code := m.Run()
+	if len(folder) > 0 {
+		os.RemoveAll(folder)
+	}
+	os.Exit(code)
+}
+
+const fixturesConfig = `[version]
+  current = "v2.0.0-release"
+
+[settings]
+  defaultChain = "mainnet"
+
+[chains]
+  [chains.mainnet]
+    chain = "mainnet"
+    chainId = "1"
+    rpcProvider = "http://localhost:8545"
+    symbol = "ETH"
+`
+
+// useFixtures answers the test's RPC requests from the fixtures in testdata/replay. If TB_RPC_RECORD
+// names a folder, the requests go to the node instead and are recorded there, so running the tests
+// with TB_RPC_RECORD=$(pwd)/testdata/replay against an archive node re-records the fixtures.
+func useFixtures(t *testing.T) {
+	if len(os.Getenv("TB_RPC_RECORD")) > 0 {
+		return
+	}
+	transport, err := replay.NewTransport(replay.Replay, "testdata/replay", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query.SetTransport(transport)
+	t.Cleanup(func() { query.SetTransport(nil) })
+}
@@ -13,20 +13,22 @@ import (
 
 // Ledger is a structure that carries enough information to complate a reconciliation
 type Ledger struct {
-	Chain       string
-	AccountFor  base.Address
-	FirstBlock  base.Blknum
-	LastBlock   base.Blknum
-	Names       map[base.Address]types.Name
-	TestMode    bool
-	Contexts    map[ledgerContextKey]*ledgerContext
-	AsEther     bool
-	NoZero      bool
-	Reversed    bool
-	UseTraces   bool
-	Conn        *rpc.Connection
-	assetFilter []base.Address
-	theTx       *types.Transaction
+	Chain        string
+	AccountFor   base.Address
+	FirstBlock   base.Blknum
+	LastBlock    base.Blknum
+	Names        map[base.Address]types.Name
+	TestMode     bool
+	Contexts     map[ledgerContextKey]*ledgerContext
+	AsEther      bool
+	NoZero       bool
+	Reversed     bool
+	UseTraces    bool
+	FiatCurrency string
+	Nfts         bool
+	Conn         *rpc.Connection
+	assetFilter  []base.Address
+	theTx        *types.Transaction
 }
 
 // NewLedger returns a new empty Ledger struct
@@ -1,11 +1,6 @@
-//go:build integration
-// +build integration
-
 package ledger
 
 import (
-	"encoding/json"
-	"fmt"
 	"testing"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
@@ -15,6 +10,7 @@ import (
 )
 
 func TestGetStatementFromLog(t *testing.T) {
+	useFixtures(t)
 	bn := base.Blknum(9279453)
 	txid := base.Txnum(208)
 	log := types.Log{
@@ -53,8 +49,17 @@ func TestGetStatementFromLog(t *testing.T) {
 		TransactionIndex: uint32(txid),
 	})
 	l.SetContexts("mainnet", apps)
-	s, _ := l.getStatementsFromLog(conn, &log)
-	b, _ := json.MarshalIndent(s, "", "  ")
-	fmt.Println(string(b))
-	fmt.Println("reconciled:", s.Reconciled())
+	s, err := l.getStatementsFromLog(conn, &log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.AmountOut.String() != "10" || !s.AmountIn.IsZero() {
+		t.Errorf("expected 10 out and nothing in, got %s out and %s in", s.AmountOut.String(), s.AmountIn.String())
+	}
+	if s.BegBal.String() != "100" || s.EndBal.String() != "90" {
+		t.Errorf("expected the balance to go from 100 to 90, got %s to %s", s.BegBal.String(), s.EndBal.String())
+	}
+	if !s.Reconciled() {
+		t.Error("expected the statement to reconcile")
+	}
 }
@@ -0,0 +1,231 @@
+package ledger
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/colors"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var transferSingleTopic = base.HexToHash(
+	"0xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62",
+)
+
+var transferBatchTopic = base.HexToHash(
+	"0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb",
+)
+
+// nftTransfer is the movement of a quantity of a single token id from one address to another
+type nftTransfer struct {
+	assetType string
+	sender    base.Address
+	recipient base.Address
+	tokenId   base.Wei
+	quantity  base.Wei
+}
+
+// isNftTransfer returns true if the log is an ERC-721 Transfer (which, unlike an ERC-20 Transfer,
+// indexes its third argument) or an ERC-1155 TransferSingle or TransferBatch.
+func isNftTransfer(log *types.Log) bool {
+	if len(log.Topics) == 0 {
+		return false
+	}
+
+	switch log.Topics[0] {
+	case transferTopic, transferSingleTopic, transferBatchTopic:
+		return len(log.Topics) == 4
+	}
+	return false
+}
+
+// getNftStatementsFromLog returns a statement for each token id moved to or from the accounted for
+// address by an ERC-721 or ERC-1155 transfer log. Each statement is reconciled against the holder's
+// balance of that single token id (as reported by ownerOf or balanceOf(address,uint256)).
+func (l *Ledger) getNftStatementsFromLog(conn *rpc.Connection, log *types.Log) ([]types.Statement, error) {
+	transfers, err := decodeNftTransfer(log)
+	if err != nil {
+		return []types.Statement{}, err
+	}
+
+	sym := log.Address.Prefix(6)
+	if name := l.Names[log.Address]; name.Address == log.Address && name.Symbol != "" {
+		sym = name.Symbol
+	}
+
+	key := l.ctxKey(log.BlockNumber, log.TransactionIndex)
+	ctx := l.Contexts[key]
+
+	statements := make([]types.Statement, 0, len(transfers))
+	for _, t := range transfers {
+		if l.AccountFor != t.sender && l.AccountFor != t.recipient {
+			continue
+		}
+
+		s := types.Statement{
+			AccountedFor:     l.AccountFor,
+			Sender:           t.sender,
+			Recipient:        t.recipient,
+			BlockNumber:      log.BlockNumber,
+			TransactionIndex: log.TransactionIndex,
+			LogIndex:         log.LogIndex,
+			TransactionHash:  log.TransactionHash,
+			Timestamp:        log.Timestamp,
+			AssetAddr:        log.Address,
+			AssetSymbol:      sym,
+			AssetType:        t.assetType,
+			TokenId:          t.tokenId,
+			Decimals:         0,
+			SpotPrice:        0.0,
+			PriceSource:      "not-priced",
+		}
+
+		// Do not collapse, may be both
+		if l.AccountFor == t.sender {
+			s.AmountOut = t.quantity
+		}
+
+		// Do not collapse, may be both
+		if l.AccountFor == t.recipient {
+			s.AmountIn = t.quantity
+		}
+
+		isErc1155 := t.assetType == "erc1155"
+		blocks := []base.Blknum{ctx.PrevBlock, ctx.CurBlock - 1, ctx.CurBlock}
+		bals := []*base.Wei{&s.PrevBal, &s.BegBal, &s.EndBal}
+		for i, bn := range blocks {
+			bal, err := conn.GetBalanceAtNft(log.Address, l.AccountFor, &t.tokenId, isErc1155, fmt.Sprintf("0x%x", bn))
+			if bal == nil {
+				return statements, err
+			}
+			*bals[i] = *bal
+		}
+
+		id := fmt.Sprintf(" %d.%d.%d", s.BlockNumber, s.TransactionIndex, s.LogIndex)
+		if !l.trialBalance(t.assetType, &s) {
+			if !utils.IsFuzzing() {
+				logger.Warn(colors.Yellow+"NFT statement at ", id, " does not reconcile."+colors.Off)
+			}
+		} else {
+			if !utils.IsFuzzing() {
+				logger.Progress(true, colors.Green+"Transaction", id, "reconciled       "+colors.Off)
+			}
+		}
+
+		statements = append(statements, s)
+	}
+
+	return statements, nil
+}
+
+// decodeNftTransfer returns the token ids and quantities moved by an ERC-721 Transfer, an ERC-1155
+// TransferSingle, or an ERC-1155 TransferBatch log
+func decodeNftTransfer(log *types.Log) ([]nftTransfer, error) {
+	if !isNftTransfer(log) {
+		return nil, fmt.Errorf("not an nft transfer")
+	}
+
+	topicToAddress := func(h base.Hash) base.Address {
+		return base.HexToAddress(h.Hex())
+	}
+
+	switch log.Topics[0] {
+	case transferTopic:
+		// Transfer(address indexed _from, address indexed _to, uint256 indexed _tokenId)
+		return []nftTransfer{{
+			assetType: "erc721",
+			sender:    topicToAddress(log.Topics[1]),
+			recipient: topicToAddress(log.Topics[2]),
+			tokenId:   *base.HexToWei(log.Topics[3].Hex()),
+			quantity:  *base.NewWei(1),
+		}}, nil
+
+	case transferSingleTopic:
+		// TransferSingle(address indexed _operator, address indexed _from, address indexed _to, uint256 _id, uint256 _value)
+		words, err := dataWords(log.Data)
+		if err != nil {
+			return nil, err
+		} else if len(words) < 2 {
+			return nil, fmt.Errorf("TransferSingle data too short")
+		}
+		return []nftTransfer{{
+			assetType: "erc1155",
+			sender:    topicToAddress(log.Topics[2]),
+			recipient: topicToAddress(log.Topics[3]),
+			tokenId:   base.Wei(*words[0]),
+			quantity:  base.Wei(*words[1]),
+		}}, nil
+
+	default:
+		// TransferBatch(address indexed _operator, address indexed _from, address indexed _to, uint256[] _ids, uint256[] _values)
+		words, err := dataWords(log.Data)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := dynamicArray(words, 0)
+		if err != nil {
+			return nil, err
+		}
+		values, err := dynamicArray(words, 1)
+		if err != nil {
+			return nil, err
+		} else if len(ids) != len(values) {
+			return nil, fmt.Errorf("TransferBatch has %d ids but %d values", len(ids), len(values))
+		}
+
+		transfers := make([]nftTransfer, 0, len(ids))
+		for i := range ids {
+			transfers = append(transfers, nftTransfer{
+				assetType: "erc1155",
+				sender:    topicToAddress(log.Topics[2]),
+				recipient: topicToAddress(log.Topics[3]),
+				tokenId:   base.Wei(*ids[i]),
+				quantity:  base.Wei(*values[i]),
+			})
+		}
+		return transfers, nil
+	}
+}
+
+// dataWords splits a log's data into 32-byte words
+func dataWords(data string) ([]*big.Int, error) {
+	if !strings.HasPrefix(data, "0x") {
+		data = "0x" + data
+	}
+	bytes, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, err
+	} else if len(bytes)%32 != 0 {
+		return nil, fmt.Errorf("log data is not a whole number of words")
+	}
+
+	words := make([]*big.Int, 0, len(bytes)/32)
+	for i := 0; i < len(bytes); i += 32 {
+		words = append(words, new(big.Int).SetBytes(bytes[i:i+32]))
+	}
+	return words, nil
+}
+
+// dynamicArray returns the uint256[] whose offset (in bytes) is found in the given head word
+func dynamicArray(words []*big.Int, head int) ([]*big.Int, error) {
+	if head >= len(words) {
+		return nil, fmt.Errorf("log data too short")
+	}
+
+	start := words[head].Uint64() / 32
+	if !words[head].IsUint64() || start >= uint64(len(words)) {
+		return nil, fmt.Errorf("invalid array offset")
+	}
+
+	n := words[start].Uint64()
+	if !words[start].IsUint64() || n > uint64(len(words)) || start+1+n > uint64(len(words)) {
+		return nil, fmt.Errorf("invalid array length")
+	}
+	return words[start+1 : start+1+n], nil
+}
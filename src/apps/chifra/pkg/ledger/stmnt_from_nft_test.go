@@ -0,0 +1,64 @@
+package ledger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func word(v uint64) string {
+	return fmt.Sprintf("%064x", v)
+}
+
+func TestDecodeNftTransfer(t *testing.T) {
+	operator := base.HexToHash("0x00000000000000000000000000000000000000000000000000000000000000aa")
+	from := base.HexToHash("0x00000000000000000000000000000000000000000000000000000000000000bb")
+	to := base.HexToHash("0x00000000000000000000000000000000000000000000000000000000000000cc")
+	tokenId := base.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000539")
+
+	// An ERC-20 Transfer indexes only two arguments
+	erc20 := types.Log{Topics: []base.Hash{transferTopic, from, to}, Data: "0x" + word(5)}
+	if isNftTransfer(&erc20) {
+		t.Error("an ERC-20 transfer is not an nft transfer")
+	}
+
+	erc721 := types.Log{Topics: []base.Hash{transferTopic, from, to, tokenId}, Data: "0x"}
+	transfers, err := decodeNftTransfer(&erc721)
+	if err != nil || len(transfers) != 1 {
+		t.Fatal("wrong erc721 transfers", transfers, err)
+	}
+	if tr := transfers[0]; tr.assetType != "erc721" || tr.sender.Hex() != "0x00000000000000000000000000000000000000bb" ||
+		tr.recipient.Hex() != "0x00000000000000000000000000000000000000cc" || tr.tokenId.Text(10) != "1337" || tr.quantity.Text(10) != "1" {
+		t.Error("wrong erc721 transfer", tr)
+	}
+
+	single := types.Log{Topics: []base.Hash{transferSingleTopic, operator, from, to}, Data: "0x" + word(7) + word(3)}
+	if transfers, err = decodeNftTransfer(&single); err != nil || len(transfers) != 1 {
+		t.Fatal("wrong erc1155 transfers", transfers, err)
+	}
+	if tr := transfers[0]; tr.assetType != "erc1155" || tr.sender.Hex() != "0x00000000000000000000000000000000000000bb" ||
+		tr.tokenId.Text(10) != "7" || tr.quantity.Text(10) != "3" {
+		t.Error("wrong erc1155 transfer", tr)
+	}
+
+	// ids at offset 0x40 ([1, 2]), values at offset 0xa0 ([10, 20])
+	data := []string{word(0x40), word(0xa0), word(2), word(1), word(2), word(2), word(10), word(20)}
+	batch := types.Log{Topics: []base.Hash{transferBatchTopic, operator, from, to}, Data: "0x" + strings.Join(data, "")}
+	if transfers, err = decodeNftTransfer(&batch); err != nil || len(transfers) != 2 {
+		t.Fatal("wrong batch transfers", transfers, err)
+	}
+	for i, expected := range [][2]string{{"1", "10"}, {"2", "20"}} {
+		if transfers[i].tokenId.Text(10) != expected[0] || transfers[i].quantity.Text(10) != expected[1] {
+			t.Error("wrong batch transfer", i, transfers[i])
+		}
+	}
+
+	data[2] = word(3)
+	batch.Data = "0x" + strings.Join(data, "")
+	if _, err = decodeNftTransfer(&batch); err == nil {
+		t.Error("expected an error for mismatched id and value counts")
+	}
+}
@@ -17,7 +17,24 @@ func (l *Ledger) getStatementsFromReceipt(conn *rpc.Connection, filter *filter.A
 	for _, log := range receipt.Logs {
 		addrArray := []base.Address{l.AccountFor}
 		if filter.ApplyLogFilter(&log, addrArray) && l.assetOfInterest(log.Address) {
-			if statement, err := l.getStatementsFromLog(conn, &log); err != nil {
+			if isNftTransfer(&log) {
+				if !l.Nfts {
+					continue
+				}
+				if nftStatements, err := l.getNftStatementsFromLog(conn, &log); err != nil {
+					return statements, err
+				} else {
+					for _, statement := range nftStatements {
+						if !l.NoZero || statement.IsMaterial() {
+							statements = append(statements, statement)
+						}
+					}
+				}
+
+			} else if l.Nfts {
+				continue
+
+			} else if statement, err := l.getStatementsFromLog(conn, &log); err != nil {
 				return statements, err
 			} else {
 				if statement.Sender == l.AccountFor || statement.Recipient == l.AccountFor {
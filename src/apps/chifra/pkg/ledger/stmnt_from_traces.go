@@ -20,6 +20,7 @@ func (l *Ledger) getStatementsFromTraces(conn *rpc.Connection, trans *types.Tran
 	ret.MinerNephewRewardIn.SetUint64(0)
 	ret.MinerTxFeeIn.SetUint64(0)
 	ret.MinerUncleRewardIn.SetUint64(0)
+	ret.WithdrawalIn.SetUint64(0)
 	ret.CorrectingIn.SetUint64(0)
 	ret.PrefundIn.SetUint64(0)
 	ret.SelfDestructIn.SetUint64(0)
@@ -36,7 +36,7 @@ func (l *Ledger) GetStatements(conn *rpc.Connection, filter *filter.AppearanceFi
 	key := l.ctxKey(trans.BlockNumber, trans.TransactionIndex)
 	ctx := l.Contexts[key]
 
-	if l.assetOfInterest(base.FAKE_ETH_ADDRESS) {
+	if !l.Nfts && l.assetOfInterest(base.FAKE_ETH_ADDRESS) {
 		// TODO: We ignore errors in the next few lines, but we should not
 		// TODO: BOGUS PERF - This greatly increases the number of times we call into eth_getBalance which is quite slow
 		prevBal, _ := conn.GetBalanceAt(l.AccountFor, ctx.PrevBlock)
@@ -78,6 +78,9 @@ func (l *Ledger) GetStatements(conn *rpc.Connection, filter *filter.AppearanceFi
 			}
 			gasPrice := new(base.Wei).SetUint64(uint64(trans.GasPrice))
 			gasOut := new(base.Wei).Mul(gasUsed, gasPrice)
+			// The fee for the blob gas (EIP-4844) is paid on top of the regular gas fee
+			blobGasOut := new(base.Wei).SetUint64(uint64(trans.BlobGasCost()))
+			gasOut = gasOut.Add(gasOut, blobGasOut)
 
 			ret.AmountOut = trans.Value
 			ret.GasOut = *gasOut
@@ -93,10 +96,12 @@ func (l *Ledger) GetStatements(conn *rpc.Connection, filter *filter.AppearanceFi
 					ret.MinerNephewRewardIn = trans.Rewards.Nephew
 					ret.MinerTxFeeIn = trans.Rewards.TxFee
 					ret.MinerUncleRewardIn = trans.Rewards.Uncle
+				} else if trans.TransactionIndex == types.WithdrawalAmt {
+					// Both partial (reward) and full (exit) withdrawals from the consensus layer
+					ret.WithdrawalIn = trans.Value
 				} else {
 					ret.AmountIn = trans.Value
 				}
-				// TODO: BOGUS PERF - WHAT ABOUT WITHDRAWALS?
 			}
 		}
 
@@ -3,6 +3,7 @@ package ledger
 import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/pricing"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
 
@@ -18,17 +19,29 @@ func (l *Ledger) trialBalance(reason string, s *types.Statement) bool {
 
 	// TODO: BOGUS PERF
 	var okay bool
-	if okay = s.Reconciled(); !okay {
+	if okay = s.Reconciled(); !okay && !s.IsNft() {
 		if okay = s.CorrectForNullTransfer(l.theTx); !okay {
 			_ = s.CorrectForSomethingElse(l.theTx)
 		}
 	}
 
 	// TODO: BOGUS PERF
-	if s.IsMaterial() {
+	if s.IsMaterial() && !s.IsNft() {
 		s.SpotPrice, s.PriceSource, _ = pricing.PriceUsd(l.Conn, s)
 	}
 
+	if len(l.FiatCurrency) > 0 {
+		s.FiatCurrency = l.FiatCurrency
+		ts := s.Timestamp
+		if ts == 0 {
+			ts, _ = tslib.FromBnToTs(l.Conn.Chain, s.BlockNumber)
+		}
+		var err error
+		if s.FxRate, err = pricing.FxRate(l.FiatCurrency, ts); err != nil {
+			logger.Warn(err)
+		}
+	}
+
 	if l.TestMode {
 		s.DebugStatement(ctx)
 	}
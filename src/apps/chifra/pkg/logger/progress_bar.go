@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -16,13 +17,14 @@ const (
 )
 
 type BarOptions struct {
-	Enabled     bool    // enable progress bar
-	Type        BarType // if Expanding, the bar doesn't know how many object there will be, so grows
-	Prefix      string  // the string to display first
-	Fill        string  // the fill value for progress bar
-	Start       int64   // the starting value (defaults to zero)
-	Total       int64   // the total (possibly estimated for Expanding) number of objects
-	PrefixColor string  // the color of the prefix
+	Enabled     bool             // enable progress bar
+	Type        BarType          // if Expanding, the bar doesn't know how many object there will be, so grows
+	Prefix      string           // the string to display first
+	Fill        string           // the fill value for progress bar
+	Start       int64            // the starting value (defaults to zero)
+	Total       int64            // the total (possibly estimated for Expanding) number of objects
+	PrefixColor string           // the color of the prefix
+	Report      func(msg string) // if not nil, the bar's progress is also reported to it (for example, by a server job)
 }
 
 type ProgressBar struct {
@@ -78,6 +80,9 @@ func (bar *ProgressBar) Tick() {
 }
 
 func (bar *ProgressBar) Finish(newLine bool) time.Duration {
+	if bar.Report != nil {
+		bar.Report(fmt.Sprintf("%s %d/%d (100%%)", strings.TrimSpace(bar.Prefix), bar.cur, bar.cur))
+	}
 	if bar.Enabled && loggerWriter != nil {
 		atomic.StoreInt64(&bar.Total, bar.cur)
 		if bar.Type == Expanding {
@@ -97,7 +102,8 @@ func (bar *ProgressBar) Finish(newLine bool) time.Duration {
 }
 
 func (bar *ProgressBar) display() {
-	if bar.Enabled && loggerWriter != nil {
+	show := bar.Enabled && loggerWriter != nil
+	if show || bar.Report != nil {
 		last := bar.percent
 		if bar.Total == 0 {
 			bar.percent = 99
@@ -110,6 +116,11 @@ func (bar *ProgressBar) display() {
 		if bar.Total == 0 {
 			bar.percent = 100
 		}
+		if bar.Report != nil && bar.percent != last {
+			bar.Report(fmt.Sprintf("%s %d/%d (%d%%)", strings.TrimSpace(bar.Prefix), bar.cur, bar.Total, bar.percent))
+		}
+	}
+	if show {
 		timeDatePart := "DATE|TIME"
 		if timingMode {
 			now := time.Now()
@@ -0,0 +1,125 @@
+package monitor
+
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// Cursor records how far a named consumer (given with chifra export --cursor) has read a
+// monitor's appearances: the last appearance exported and the block to which the monitor had
+// been scanned at the time. Each monitor may have any number of cursors, one per name.
+type Cursor struct {
+	Name             string       `json:"name"`
+	Address          base.Address `json:"address"`
+	BlockNumber      base.Blknum  `json:"blockNumber"`
+	TransactionIndex base.Txnum   `json:"transactionIndex"`
+	LastScanned      base.Blknum  `json:"lastScanned"`
+	NRecords         uint64       `json:"nRecords"`
+	path             string
+	pending          *types.AppRecord
+}
+
+var cursorNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// IsValidCursorName returns true if the name may be used to name a cursor (and its file)
+func IsValidCursorName(name string) bool {
+	return cursorNameRe.MatchString(name)
+}
+
+// PathToCursorFile returns the path to the named cursor of the address's monitor
+func PathToCursorFile(chain string, address base.Address, name string) string {
+	return filepath.Join(config.PathToCache(chain), "cursors", address.Hex(), name+".json")
+}
+
+// ReadCursor reads the named cursor of the monitor. If the cursor does not yet exist, the
+// returned cursor is before the monitor's first appearance.
+func (mon *Monitor) ReadCursor(name string) (*Cursor, error) {
+	return readCursorFile(PathToCursorFile(mon.Chain, mon.Address, name), name, mon.Address)
+}
+
+func readCursorFile(path, name string, address base.Address) (*Cursor, error) {
+	cursor := &Cursor{Name: name, Address: address, path: path}
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cursor, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(contents, cursor); err != nil {
+		return nil, fmt.Errorf("could not read cursor %s: %w", path, err)
+	}
+	return cursor, nil
+}
+
+// IsBefore returns true if the appearance comes after the cursor (and so has not been exported)
+func (c *Cursor) IsBefore(app *types.AppRecord) bool {
+	if c.NRecords == 0 {
+		return true
+	}
+	bn, txid := base.Blknum(app.BlockNumber), base.Txnum(app.TransactionIndex)
+	return bn > c.BlockNumber || (bn == c.BlockNumber && txid > c.TransactionIndex)
+}
+
+// Advance notes that a record at the appearance (given by its block and transaction index) was
+// exported. The cursor moves past the latest such appearance, but only when it is saved. Reading
+// an appearance does not advance the cursor, only exporting one of its records does. A nil
+// cursor (an export without --cursor) ignores the call.
+func (c *Cursor) Advance(bn base.Blknum, txid base.Txnum) {
+	if c == nil {
+		return
+	}
+	app := types.AppRecord{BlockNumber: uint32(bn), TransactionIndex: uint32(txid)}
+	if c.pending == nil || app.BlockNumber > c.pending.BlockNumber ||
+		(app.BlockNumber == c.pending.BlockNumber && app.TransactionIndex > c.pending.TransactionIndex) {
+		c.pending = &app
+	}
+	c.NRecords++
+}
+
+// Save moves the cursor past the appearances exported since it was read and records the block
+// to which the monitor has been scanned. The file is replaced atomically, so a consumer never
+// sees a partially written cursor and an interrupted export leaves the cursor where it was.
+func (c *Cursor) Save(lastScanned base.Blknum) error {
+	if c.pending != nil {
+		c.BlockNumber = base.Blknum(c.pending.BlockNumber)
+		c.TransactionIndex = base.Txnum(c.pending.TransactionIndex)
+		c.pending = nil
+	}
+	c.LastScanned = lastScanned
+
+	contents, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(append(contents, '\n')); err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func Test_Cursor_SaveAndRead(t *testing.T) {
+	addr := base.HexToAddress("0x049029dd41661e58f99271a0112dfd34695f7000")
+	path := filepath.Join(t.TempDir(), "cursors", addr.Hex(), "consumer.json")
+
+	cursor, err := readCursorFile(path, "consumer", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cursor.IsBefore(&types.AppRecord{BlockNumber: 0, TransactionIndex: 0}) {
+		t.Error("a new cursor should be before every appearance")
+	}
+
+	for _, app := range testApps {
+		cursor.Advance(base.Blknum(app.BlockNumber), base.Txnum(app.TransactionIndex))
+	}
+	if cursor.BlockNumber != 0 {
+		t.Error("the cursor should not move until it is saved")
+	}
+	if err := cursor.Save(2002003); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp"))
+	if len(matches) != 0 {
+		t.Error("temporary files were left behind", matches)
+	}
+
+	cursor, err = readCursorFile(path, "consumer", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor.BlockNumber != 1001003 || cursor.TransactionIndex != 2 || cursor.LastScanned != 2002003 || cursor.NRecords != 3 {
+		t.Errorf("unexpected cursor %+v", cursor)
+	}
+	if cursor.IsBefore(&types.AppRecord{BlockNumber: 1001003, TransactionIndex: 2}) {
+		t.Error("the last exported appearance should not be exported again")
+	}
+	if !cursor.IsBefore(&types.AppRecord{BlockNumber: 1001003, TransactionIndex: 3}) ||
+		!cursor.IsBefore(&types.AppRecord{BlockNumber: 1001004, TransactionIndex: 0}) {
+		t.Error("later appearances should be exported")
+	}
+
+	// Another consumer of the same monitor has its own cursor
+	other, err := readCursorFile(filepath.Join(filepath.Dir(path), "other.json"), "other", addr)
+	if err != nil || other.NRecords != 0 {
+		t.Errorf("expected a new cursor, got %+v (%v)", other, err)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readCursorFile(path, "consumer", addr); err == nil {
+		t.Error("expected an error reading a corrupt cursor")
+	}
+}
+
+func Test_Cursor_Names(t *testing.T) {
+	for _, name := range []string{"etl", "nightly-job_2"} {
+		if !IsValidCursorName(name) {
+			t.Errorf("%s should be valid", name)
+		}
+	}
+	for _, name := range []string{"", "../up", "a/b", "a b"} {
+		if IsValidCursorName(name) {
+			t.Errorf("%s should be invalid", name)
+		}
+	}
+}
+
+func Test_Cursor_Filter(t *testing.T) {
+	mon := GetTestMonitor(t)
+	defer func() {
+		RemoveTestMonitor(&mon, t)
+	}()
+
+	mon.Cursor = &Cursor{BlockNumber: 1001002, TransactionIndex: 1, NRecords: 2}
+	apps, cnt, err := mon.ReadAndFilterAppearances(filter.NewEmptyFilter(), false /* withCount */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cnt != 1 || apps[0].BlockNumber != 1001003 {
+		t.Errorf("expected only the appearance after the cursor, got %v", apps)
+	}
+	// reading an appearance does not advance the cursor (only exporting one of its records does)
+	if mon.Cursor.pending != nil || mon.Cursor.NRecords != 2 {
+		t.Errorf("the cursor should not advance, got %+v", mon.Cursor)
+	}
+
+	var nilCursor *Cursor
+	nilCursor.Advance(1001003, 0)
+}
@@ -61,6 +61,11 @@ func (mon *Monitor) ReadAndFilterAppearances(filt *filter.AppearanceFilter, with
 	prev := fromDisc[0]
 	apps = make([]types.Appearance, 0, len(fromDisc))
 	for _, app := range fromDisc {
+		if mon.Cursor != nil && !mon.Cursor.IsBefore(&app) { // --cursor
+			prev = app
+			continue
+		}
+
 		var passes bool
 		var finished bool
 		if withCount {
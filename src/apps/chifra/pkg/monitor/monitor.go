@@ -36,6 +36,7 @@ type Monitor struct {
 	Staged  bool         `json:"-"`
 	Chain   string       `json:"-"`
 	ReadFp  *os.File     `json:"-"`
+	Cursor  *Cursor      `json:"-"`
 	Header
 }
 
@@ -0,0 +1,156 @@
+package monitor
+
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+const (
+	WatcherRunning = "running"
+	WatcherPaused  = "paused"
+	WatcherStopped = "stopped"
+)
+
+// Watcher freshens a list of monitors each time it is woken (for example, when the scraper writes a
+// chunk or updates the stage) and, if nothing wakes it, once each interval. It may be paused and
+// resumed while it runs.
+type Watcher struct {
+	interval time.Duration
+	list     func() []Monitor
+	refresh  func([]Monitor) (bool, error)
+	wake     chan string
+	mutex    sync.Mutex
+	running  bool
+	paused   bool
+	status   types.WatcherStatus
+}
+
+var activeWatcher atomic.Pointer[Watcher]
+
+// ActiveWatcher returns the watcher running in this process, if any
+func ActiveWatcher() *Watcher {
+	return activeWatcher.Load()
+}
+
+// NewWatcher returns a watcher that calls refresh with the monitors returned by list
+func NewWatcher(interval time.Duration, list func() []Monitor, refresh func([]Monitor) (bool, error)) *Watcher {
+	return &Watcher{
+		interval: interval,
+		list:     list,
+		refresh:  refresh,
+		wake:     make(chan string, 1),
+	}
+}
+
+// Run freshens the monitors immediately and then each time the watcher wakes until the context
+// is canceled or the refresh is canceled. While paused, the watcher wakes but does nothing.
+func (w *Watcher) Run(ctx context.Context) error {
+	activeWatcher.Store(w)
+	defer activeWatcher.CompareAndSwap(w, nil)
+
+	w.mutex.Lock()
+	w.running = true
+	w.mutex.Unlock()
+	defer func() {
+		w.mutex.Lock()
+		w.running = false
+		w.mutex.Unlock()
+	}()
+
+	reason := "start"
+	for {
+		if !w.IsPaused() {
+			if canceled := w.freshen(reason); canceled {
+				return nil
+			}
+		}
+
+		timer := time.NewTimer(w.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case reason = <-w.wake:
+			timer.Stop()
+		case <-timer.C:
+			reason = "interval"
+		}
+	}
+}
+
+// freshen refreshes the monitors once and records the result
+func (w *Watcher) freshen(reason string) bool {
+	monitors := w.list()
+	canceled, err := w.refresh(monitors)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.status.NRuns++
+	w.status.NMonitors = len(monitors)
+	w.status.LastRun = base.FormattedDate(base.Timestamp(time.Now().Unix()))
+	w.status.LastReason = reason
+	w.status.LastError = ""
+	if err != nil {
+		w.status.LastError = err.Error()
+	}
+	return canceled
+}
+
+// Wake asks the watcher to freshen the monitors now. Wakes that arrive while the watcher is busy
+// are combined into one.
+func (w *Watcher) Wake(reason string) {
+	select {
+	case w.wake <- reason:
+	default:
+	}
+}
+
+// Pause stops the watcher from freshening the monitors until it is resumed
+func (w *Watcher) Pause() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.paused = true
+}
+
+// Resume undoes Pause and freshens the monitors to catch up on anything missed while paused
+func (w *Watcher) Resume() {
+	w.mutex.Lock()
+	wasPaused := w.paused
+	w.paused = false
+	w.mutex.Unlock()
+	if wasPaused {
+		w.Wake("resume")
+	}
+}
+
+// IsPaused returns true if the watcher has been paused
+func (w *Watcher) IsPaused() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.paused
+}
+
+// Status reports the watcher's state and the result of its latest run
+func (w *Watcher) Status() types.WatcherStatus {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	status := w.status
+	switch {
+	case !w.running:
+		status.State = WatcherStopped
+	case w.paused:
+		status.State = WatcherPaused
+	default:
+		status.State = WatcherRunning
+	}
+	return status
+}
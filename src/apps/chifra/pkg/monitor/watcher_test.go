@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_Watcher(t *testing.T) {
+	runs := make(chan int, 10)
+	nRuns := 0
+	refresh := func(monitors []Monitor) (bool, error) {
+		nRuns++
+		runs <- nRuns
+		if nRuns == 2 {
+			return false, errors.New("refresh failed")
+		}
+		return false, nil
+	}
+	list := func() []Monitor {
+		return []Monitor{{}, {}}
+	}
+
+	watcher := NewWatcher(time.Hour, list, refresh)
+	if watcher.Status().State != WatcherStopped || ActiveWatcher() != nil {
+		t.Fatal("a new watcher should be stopped")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- watcher.Run(ctx)
+	}()
+
+	wait := func(expected int) {
+		t.Helper()
+		select {
+		case n := <-runs:
+			if n != expected {
+				t.Fatalf("expected run %d, got %d", expected, n)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for run %d", expected)
+		}
+	}
+
+	wait(1) // freshens at once
+	watcher.Wake("chunkWritten")
+	wait(2)
+
+	// The status is recorded after the refresh returns
+	time.Sleep(50 * time.Millisecond)
+	status := watcher.Status()
+	if status.State != WatcherRunning || status.NRuns != 2 || status.NMonitors != 2 ||
+		status.LastReason != "chunkWritten" || status.LastError != "refresh failed" {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if ActiveWatcher() != watcher {
+		t.Error("the running watcher should be active")
+	}
+
+	watcher.Pause()
+	if watcher.Status().State != WatcherPaused {
+		t.Error("expected the watcher to be paused")
+	}
+	watcher.Wake("stageUpdated")
+	select {
+	case n := <-runs:
+		t.Errorf("a paused watcher should not freshen, got run %d", n)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	watcher.Resume()
+	wait(3)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+	if watcher.Status().State != WatcherStopped || ActiveWatcher() != nil {
+		t.Error("a canceled watcher should be stopped")
+	}
+}
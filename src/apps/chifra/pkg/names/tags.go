@@ -0,0 +1,53 @@
+package names
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// HasTag returns true if a name's tags (such as 31-Gitcoin:Core) include the tag. The numeric
+// sorting prefix and case are ignored, and the tag may match the whole tag or any part of it.
+func HasTag(tags, tag string) bool {
+	tag = strings.ToLower(tag)
+	for _, t := range strings.Split(strings.ToLower(tags), ",") {
+		t = strings.TrimSpace(t)
+		if t == tag {
+			return true
+		}
+		if i := strings.Index(t, "-"); i > 0 && strings.Trim(t[:i], "0123456789") == "" {
+			t = t[i+1:]
+		}
+		if t == tag {
+			return true
+		}
+		for _, part := range strings.Split(t, ":") {
+			if part == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LoadTaggedAddresses returns the addresses of the names carrying the tag. It is an error if
+// no name carries the tag.
+func LoadTaggedAddresses(chain, tag string) (map[base.Address]bool, error) {
+	namesMap, err := LoadNamesMap(chain, types.All, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[base.Address]bool)
+	for addr, name := range namesMap {
+		if HasTag(name.Tags, tag) {
+			ret[addr] = true
+		}
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("no names are tagged %s", tag)
+	}
+	return ret, nil
+}
@@ -0,0 +1,14 @@
+package names
+
+import "testing"
+
+func TestHasTag(t *testing.T) {
+	for _, tag := range []string{"gitcoin", "Core", "gitcoin:core", "31-Gitcoin:Core"} {
+		if !HasTag("31-Gitcoin:Core", tag) {
+			t.Errorf("expected %s to match", tag)
+		}
+	}
+	if HasTag("31-Gitcoin:Core", "31") || HasTag("31-Gitcoin:Core", "coin") {
+		t.Error("expected only whole tags to match")
+	}
+}
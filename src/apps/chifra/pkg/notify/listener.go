@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// Listener receives notifications in the process that produced them (for example, the daemon
+// running the scraper), whether or not they are also sent to the notify endpoint
+type Listener func(msg Message, meta *types.MetaData, payload any)
+
+var listeners = struct {
+	sync.RWMutex
+	next int
+	m    map[int]Listener
+}{m: make(map[int]Listener)}
+
+// AddListener adds a listener and returns a function that removes it
+func AddListener(listener Listener) (remove func()) {
+	listeners.Lock()
+	defer listeners.Unlock()
+	id := listeners.next
+	listeners.next++
+	listeners.m[id] = listener
+	return func() {
+		listeners.Lock()
+		defer listeners.Unlock()
+		delete(listeners.m, id)
+	}
+}
+
+// HasListeners returns true if any listener is present
+func HasListeners() bool {
+	listeners.RLock()
+	defer listeners.RUnlock()
+	return len(listeners.m) > 0
+}
+
+// Publish delivers the notification to each listener. The listeners are called synchronously, so
+// they must not block.
+func Publish[T NotificationPayload](notification Notification[T]) {
+	listeners.RLock()
+	defer listeners.RUnlock()
+	for _, listener := range listeners.m {
+		listener(notification.Msg, notification.Meta, notification.Payload)
+	}
+}
@@ -14,5 +14,6 @@ type NotificationPayload interface {
 	[]NotificationPayloadAppearance |
 		[]NotificationPayloadChunkWritten |
 		NotificationPayloadChunkWritten |
+		NotificationPayloadReorg |
 		string
 }
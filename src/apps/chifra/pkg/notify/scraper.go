@@ -11,6 +11,7 @@ const (
 	MessageChunkWritten Message = "chunkWritten"
 	MessageStageUpdated Message = "stageUpdated"
 	MessageAppearance   Message = "appearance"
+	MessageReorg        Message = "reorg"
 )
 
 type NotificationPayloadAppearance struct {
@@ -59,8 +60,24 @@ func NewAppearanceNotification(meta *types.MetaData, appearances []NotificationP
 	}
 }
 
+func NewReorgNotification(meta *types.MetaData, reorg NotificationPayloadReorg) *Notification[NotificationPayloadReorg] {
+	return &Notification[NotificationPayloadReorg]{
+		Msg:     MessageReorg,
+		Meta:    meta,
+		Payload: reorg,
+	}
+}
+
 type NotificationPayloadChunkWritten struct {
 	Cid    string `json:"cid"`
 	Range  string `json:"range"`
 	Author string `json:"author"`
 }
+
+// NotificationPayloadReorg reports a chain reorganization detected by the scraper. BlockNumber
+// is the first block whose hash changed. Everything at or after that block was rolled back.
+type NotificationPayloadReorg struct {
+	BlockNumber string `json:"blockNumber"`
+	OldHash     string `json:"oldHash"`
+	NewHash     string `json:"newHash"`
+}
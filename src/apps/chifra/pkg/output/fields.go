@@ -0,0 +1,114 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/expr"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// Field is a single column of the output selected with --fields. It is either a field of the
+// model (possibly a dotted path into a nested object or array such as receipt.gasUsed or
+// logs.0.address) or an arithmetic expression of such fields and numbers (such as value/1e18
+// or gasUsed*gasPrice). A column may be renamed with name=field or name=expression.
+type Field struct {
+	Name string
+	expr *expr.Expression
+}
+
+// Fields is the list of columns selected with --fields, in order
+type Fields []Field
+
+// ParseFields parses a comma-separated --fields specification
+func ParseFields(spec string) (Fields, error) {
+	if len(strings.TrimSpace(spec)) == 0 {
+		return nil, nil
+	}
+
+	ret := make(Fields, 0)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			return nil, fmt.Errorf("empty field in %s", spec)
+		}
+
+		name, text := part, part
+		if i := strings.Index(part, "="); i >= 0 {
+			name, text = strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+1:])
+			if len(name) == 0 || strings.ContainsAny(name, "+-*/() ") {
+				return nil, fmt.Errorf("invalid column name in %s", part)
+			}
+		}
+
+		expression, err := expr.Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %s: %w", part, err)
+		}
+		ret = append(ret, Field{Name: name, expr: expression})
+	}
+	return ret, nil
+}
+
+// Project returns a model with only the selected columns. Fields are looked up in the model
+// rendered in the output's format and, if not found there (as with nested objects, which are
+// present only in JSON), in the model's other renderings. JSON, Parquet, and SQLite keep the
+// types of the fields and report missing values as nulls. Because the result of arithmetic may be
+// an integer in one row and a decimal string in the next, Parquet and SQLite store it as text. In
+// other formats, objects and arrays are rendered as JSON strings and missing values as empty
+// strings.
+func (fields Fields) Project(modeler types.Modeler, chain, format string, verbose bool, extraOpts map[string]any) types.Model {
+	lookup := expr.ModelLookup(modeler, chain, format, verbose, extraOpts)
+	typed := format == "json" || format == "parquet" || format == "sqlite"
+	tabular := format == "parquet" || format == "sqlite"
+
+	ret := types.Model{
+		Data:  make(map[string]any, len(fields)),
+		Order: make([]string, 0, len(fields)),
+	}
+	for _, field := range fields {
+		value, ok := field.expr.Eval(lookup)
+		if !ok {
+			if typed {
+				value = nil
+			} else {
+				value = ""
+			}
+		} else if !typed {
+			value = expr.TextOf(value)
+		} else if tabular && !field.expr.IsField() {
+			value = fmt.Sprint(value)
+		}
+		ret.Data[field.Name] = value
+		ret.Order = append(ret.Order, field.Name)
+	}
+	return ret
+}
+
+// orderedObject marshals a model's data as a JSON object with its keys in the model's order
+type orderedObject types.Model
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, key := range o.Order {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.Data[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
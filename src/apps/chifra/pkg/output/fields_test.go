@@ -0,0 +1,123 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestParseFields(t *testing.T) {
+	valid := []string{
+		"blockNumber",
+		"blockNumber, hash ,receipt.gasUsed",
+		"value/1e18",
+		"fee=gasUsed*gasPrice",
+		"-(a + b) * 2.5 / c.d.0",
+		"x=1.5e-3*value",
+	}
+	for _, spec := range valid {
+		if fields, err := ParseFields(spec); err != nil || len(fields) == 0 {
+			t.Errorf("%s: unexpected error %v", spec, err)
+		}
+	}
+
+	invalid := []string{
+		"blockNumber,,hash",
+		"value/",
+		"(value",
+		"a..b",
+		"=value",
+		"a b=value",
+		"value % 2",
+		"1e",
+	}
+	for _, spec := range invalid {
+		if _, err := ParseFields(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+
+	if fields, _ := ParseFields(""); fields != nil {
+		t.Error("an empty specification should select nothing")
+	}
+}
+
+var fieldsTx = types.Transaction{
+	BlockNumber:      100,
+	TransactionIndex: 2,
+	Hash:             base.HexToHash("0xabc"),
+	From:             base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b"),
+	Value:            *base.NewWei(1500000000000000000),
+	GasPrice:         3,
+	Receipt: &types.Receipt{
+		GasUsed: 21000,
+		Logs: []types.Log{
+			{Address: base.HexToAddress("0x1234"), LogIndex: 7},
+		},
+	},
+}
+
+func TestProjectText(t *testing.T) {
+	fields, err := ParseFields("hash,blockNumber,receipt.gasUsed,fee=receipt.gasUsed*gasPrice,value/1e18,receipt.logs.0.logIndex,missing,fee2=missing*2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := bytes.Buffer{}
+	if err := StreamModel(&buf, fields.Project(&fieldsTx, "mainnet", "csv", false, nil), OutputOptions{Format: "csv"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "hash,blockNumber,receipt.gasUsed,fee,value/1e18,receipt.logs.0.logIndex,missing,fee2\n" +
+		fieldsTx.Hash.Hex() + ",100,21000,63000,1.5,7,,\n"
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestProjectJson(t *testing.T) {
+	fields, err := ParseFields("blockNumber,hash,eth=value/1e18,receipt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := bytes.Buffer{}
+	jw := NewDefaultJsonWriter(&buf, false)
+	model := fields.Project(&fieldsTx, "mainnet", "json", false, nil)
+	if err := StreamModel(jw, model, OutputOptions{Format: "json", Fields: "blockNumber,hash,eth=value/1e18,receipt"}); err != nil {
+		t.Fatal(err)
+	}
+	jw.Close()
+
+	out := buf.String()
+	b, h, e, r := strings.Index(out, `"blockNumber"`), strings.Index(out, `"hash"`), strings.Index(out, `"eth": "1.5"`), strings.Index(out, `"receipt": {`)
+	if b < 0 || h < b || e < h || r < e {
+		t.Errorf("fields missing or out of order:\n%s", out)
+	}
+	if strings.Contains(out, `"from"`) {
+		t.Errorf("unselected field present:\n%s", out)
+	}
+}
+
+func TestProjectTyped(t *testing.T) {
+	fields, err := ParseFields("blockNumber,from,fee=receipt.gasUsed*gasPrice,missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := fields.Project(&fieldsTx, "mainnet", "parquet", false, nil)
+	if bn, ok := model.Data["blockNumber"].(base.Blknum); !ok || bn != 100 {
+		t.Errorf("expected a block number, got %T %v", model.Data["blockNumber"], model.Data["blockNumber"])
+	}
+	if _, ok := model.Data["from"].(base.Address); !ok {
+		t.Errorf("expected an address, got %T", model.Data["from"])
+	}
+	if model.Data["fee"] != "63000" {
+		t.Errorf("expected arithmetic as text, got %T %v", model.Data["fee"], model.Data["fee"])
+	}
+	if model.Data["missing"] != nil {
+		t.Errorf("expected a null for a missing field, got %v", model.Data["missing"])
+	}
+}
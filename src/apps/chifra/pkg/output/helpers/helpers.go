@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/globals"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
@@ -22,6 +23,13 @@ func init() {
 func PreRunWithJsonWriter(cmdName string, getOptions func() *globals.GlobalOptions) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
 		opts := getOptions()
+		opts.SetFormatFromOutput()
+		// A database is opened, not truncated like other --output files
+		if opts.Format == "sqlite" {
+			opts.Writer = openSqliteWriter(opts.OutputFn)
+			return
+		}
+
 		var outputWriter io.Writer
 		// Prepare the default writer (stdout or --output file)
 		outputWriter = os.Stdout
@@ -35,6 +43,9 @@ func PreRunWithJsonWriter(cmdName string, getOptions func() *globals.GlobalOptio
 		if opts.Format == "json" {
 			jw := output.NewDefaultJsonWriter(outputWriter, true)
 			opts.Writer = jw
+		} else if opts.Format == "parquet" {
+			// ...or ParquetWriter...
+			opts.Writer = output.NewParquetWriter(outputWriter)
 		} else {
 			// ...or set the default writer as global writer for the current command
 			// invocation
@@ -47,6 +58,21 @@ func PostRunWithJsonWriter(getOptions func() *globals.GlobalOptions) func(cmd *c
 	return func(cmd *cobra.Command, args []string) {
 		opts := getOptions()
 		w := opts.Writer
+		// If the global writer is a ParquetWriter or SqliteWriter, close it (write the
+		// file or commit the last rows). Other writers (stdout or the --output file) are
+		// left open.
+		switch w := w.(type) {
+		case *output.ParquetWriter:
+			if err := w.Close(); err != nil {
+				logger.Error(err)
+			}
+			return
+		case *output.SqliteWriter:
+			if err := w.Close(); err != nil {
+				logger.Error(err)
+			}
+			return
+		}
 		// Try to cast the global writer to JsonWriter
 		jw, ok := w.(*output.JsonWriter)
 		if !ok {
@@ -61,6 +87,42 @@ func PostRunWithJsonWriter(getOptions func() *globals.GlobalOptions) func(cmd *c
 // SetWriterForCommand sets the writer for currently running command, but only if
 // we are running with --file
 func SetWriterForCommand(cmdName string, opts *globals.GlobalOptions) {
+	// The global writer is a ParquetWriter or SqliteWriter. Keep it if this command wants
+	// the same output, otherwise close it (write the parquet file's footer or commit the
+	// database) and switch to the default writer
+	switch w := opts.Writer.(type) {
+	case *output.ParquetWriter:
+		if opts.Format == "parquet" {
+			return
+		}
+		if err := w.Close(); err != nil {
+			logger.Error(err)
+		}
+		opts.Writer = os.Stdout
+	case *output.SqliteWriter:
+		if opts.Format == "sqlite" && w.Path == opts.OutputFn {
+			return
+		}
+		if err := w.Close(); err != nil {
+			logger.Error(err)
+		}
+		opts.Writer = os.Stdout
+	}
+
+	// The command wants parquet or sqlite, so close the JsonWriter if present and create
+	// the command's writer
+	if opts.Format == "parquet" || opts.Format == "sqlite" {
+		if jw, ok := opts.Writer.(*output.JsonWriter); ok {
+			jw.Close()
+		}
+		if opts.Format == "parquet" {
+			opts.Writer = output.NewParquetWriter(opts.GetOutputFileWriter())
+		} else {
+			opts.Writer = openSqliteWriter(opts.OutputFn)
+		}
+		return
+	}
+
 	// Try to cast the default writer to JsonWriter
 	jw, ok := opts.Writer.(*output.JsonWriter)
 	wantsJson := (opts.Format == "json")
@@ -113,3 +175,13 @@ func CloseJsonWriterIfNeededApi(cmdName string, err error, opts *globals.GlobalO
 		opts.Writer.(*output.JsonWriter).Close()
 	}
 }
+
+// openSqliteWriter opens the --output database. As with other --output files, there is no
+// good way to recover from an error (and --output is disabled in the server).
+func openSqliteWriter(path string) *output.SqliteWriter {
+	sw, err := output.NewSqliteWriter(path)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	return sw
+}
@@ -0,0 +1,362 @@
+package output
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/parquet-go/parquet-go"
+)
+
+// DefaultRowGroupSize is the number of rows the ParquetWriter buffers before writing a row group
+const DefaultRowGroupSize = 64 * 1024
+
+type columnKind int
+
+const (
+	columnString columnKind = iota
+	columnBool
+	columnInt
+	columnUint
+	columnFloat
+	columnTimestamp
+	// columnNull is a column whose values have all been null so far. If none are ever set, it is
+	// written as a string column.
+	columnNull
+)
+
+var columnKindNames = map[columnKind]string{
+	columnString:    "string",
+	columnBool:      "boolean",
+	columnInt:       "integer",
+	columnUint:      "unsigned integer",
+	columnFloat:     "floating point",
+	columnTimestamp: "timestamp",
+	columnNull:      "null",
+}
+
+type parquetColumn struct {
+	name string
+	kind columnKind
+}
+
+// spooledCell is a non-null value of one row, already converted to its column's type
+type spooledCell struct {
+	Col int
+	B   bool
+	I   int64
+	F   float64
+	S   []byte
+}
+
+// ParquetWriter writes models as the rows of a Parquet file. The file's columns are the union of
+// the fields of every model written (in the order they are first seen and with types derived from
+// their first non-null values). Missing fields are written as nulls. A value that cannot be stored
+// in its column's type is an error. Because the schema is not known until
+// the last model is written, rows are spooled to a temporary file and written (one row group at a
+// time) when the writer is closed, so memory use does not grow with the size of the export. Close
+// must be called to write the file.
+type ParquetWriter struct {
+	outputWriter io.Writer
+	columns      []parquetColumn
+	columnIndex  map[string]int
+	spool        *os.File
+	buffered     *bufio.Writer
+	encoder      *gob.Encoder
+	nRows        int
+	RowGroupSize int64
+	// The name of the schema (the kind of model in the file, for example "transaction")
+	Name string
+}
+
+// NewParquetWriter returns a ParquetWriter writing to w
+func NewParquetWriter(w io.Writer) *ParquetWriter {
+	return &ParquetWriter{
+		outputWriter: w,
+		columnIndex:  make(map[string]int),
+		RowGroupSize: DefaultRowGroupSize,
+		Name:         "chifra",
+	}
+}
+
+// Write is present so that the ParquetWriter may be used as the global writer. Parquet files
+// contain only models, so writing raw bytes is an error.
+func (w *ParquetWriter) Write(p []byte) (n int, err error) {
+	return 0, errors.New("parquet output may only contain data models")
+}
+
+// WriteModel appends the model to the file as a single row
+func (w *ParquetWriter) WriteModel(model types.Model) error {
+	if w.spool == nil {
+		spool, err := os.CreateTemp("", "chifra-parquet-*")
+		if err != nil {
+			return err
+		}
+		w.spool = spool
+		w.buffered = bufio.NewWriter(spool)
+		w.encoder = gob.NewEncoder(w.buffered)
+	}
+
+	cells := make([]spooledCell, 0, len(model.Order))
+	for _, key := range model.Order {
+		value := model.Data[key]
+		col, ok := w.columnIndex[key]
+		if !ok {
+			col = len(w.columns)
+			w.columnIndex[key] = col
+			w.columns = append(w.columns, parquetColumn{name: key, kind: columnNull})
+		}
+		if value == nil {
+			continue
+		}
+		if w.columns[col].kind == columnNull {
+			w.columns[col].kind = kindOf(value)
+		}
+		pv, ok := toParquetValue(w.columns[col].kind, value)
+		if !ok {
+			return fmt.Errorf("the %s value %v in row %d does not fit the %s column %s", reflect.TypeOf(value), value, w.nRows+1, columnKindNames[w.columns[col].kind], key)
+		}
+		cells = append(cells, toSpooledCell(col, pv))
+	}
+
+	w.nRows++
+	return w.encoder.Encode(cells)
+}
+
+// Close writes the file (its schema, the spooled rows, and its footer) and removes the spooled
+// rows. It does not close the underlying writer. A Parquet file needs at least one column, so an
+// export with no rows writes an empty file with a single string column named after the schema.
+func (w *ParquetWriter) Close() error {
+	if w.outputWriter == nil {
+		return nil
+	}
+	defer func() {
+		w.outputWriter = nil
+		if w.spool != nil {
+			w.spool.Close()
+			os.Remove(w.spool.Name())
+			w.spool = nil
+		}
+	}()
+
+	columns := w.columns
+	if len(columns) == 0 {
+		columns = []parquetColumn{{name: w.Name, kind: columnString}}
+	}
+
+	fields := make([]parquet.Field, 0, len(columns))
+	for _, col := range columns {
+		fields = append(fields, &orderedField{Node: parquet.Optional(nodeOf(col.kind)), name: col.name})
+	}
+	writer := parquet.NewWriter(
+		w.outputWriter,
+		parquet.NewSchema(w.Name, &orderedGroup{fields: fields}),
+		parquet.Compression(&parquet.Snappy),
+		parquet.MaxRowsPerRowGroup(w.RowGroupSize),
+		parquet.CreatedBy("chifra", "", ""),
+	)
+
+	if w.spool != nil {
+		if err := w.buffered.Flush(); err != nil {
+			return err
+		}
+		if _, err := w.spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		decoder := gob.NewDecoder(bufio.NewReader(w.spool))
+		for i := 0; i < w.nRows; i++ {
+			var cells []spooledCell
+			if err := decoder.Decode(&cells); err != nil {
+				return err
+			}
+			row := make(parquet.Row, len(columns))
+			for c := range row {
+				row[c] = parquet.NullValue().Level(0, 0, c)
+			}
+			for _, cell := range cells {
+				row[cell.Col] = fromSpooledCell(columns[cell.Col].kind, cell).Level(0, 1, cell.Col)
+			}
+			if _, err := writer.WriteRows([]parquet.Row{row}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return writer.Close()
+}
+
+func toSpooledCell(col int, pv parquet.Value) spooledCell {
+	cell := spooledCell{Col: col}
+	switch pv.Kind() {
+	case parquet.Boolean:
+		cell.B = pv.Boolean()
+	case parquet.Int64:
+		cell.I = pv.Int64()
+	case parquet.Double:
+		cell.F = pv.Double()
+	default:
+		cell.S = pv.ByteArray()
+	}
+	return cell
+}
+
+func fromSpooledCell(kind columnKind, cell spooledCell) parquet.Value {
+	switch kind {
+	case columnBool:
+		return parquet.BooleanValue(cell.B)
+	case columnInt, columnUint, columnTimestamp:
+		return parquet.Int64Value(cell.I)
+	case columnFloat:
+		return parquet.DoubleValue(cell.F)
+	default:
+		return parquet.ByteArrayValue(cell.S)
+	}
+}
+
+// kindOf returns the type of the column in which the value is stored. Addresses, hashes, and
+// big numbers (which models present as decimal strings) are stored as strings so that values
+// larger than any Parquet decimal are preserved. Objects and arrays are stored as JSON.
+func kindOf(value any) columnKind {
+	switch value.(type) {
+	case base.Timestamp:
+		return columnTimestamp
+	case base.Address, base.Hash, base.Wei, *base.Wei:
+		return columnString
+	}
+
+	if value == nil {
+		return columnString
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Bool:
+		return columnBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return columnInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return columnUint
+	case reflect.Float32, reflect.Float64:
+		return columnFloat
+	default:
+		return columnString
+	}
+}
+
+func nodeOf(kind columnKind) parquet.Node {
+	switch kind {
+	case columnBool:
+		return parquet.Leaf(parquet.BooleanType)
+	case columnInt:
+		return parquet.Int(64)
+	case columnUint:
+		return parquet.Uint(64)
+	case columnFloat:
+		return parquet.Leaf(parquet.DoubleType)
+	case columnTimestamp:
+		return parquet.Timestamp(parquet.Millisecond)
+	default:
+		return parquet.String()
+	}
+}
+
+// toParquetValue converts the value to the column's type. It returns false if the value cannot
+// be represented in the column.
+func toParquetValue(kind columnKind, value any) (parquet.Value, bool) {
+	if kind == columnString {
+		return parquet.ByteArrayValue([]byte(stringOf(value))), true
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Bool:
+		if kind == columnBool {
+			return parquet.BooleanValue(rv.Bool()), true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch kind {
+		case columnInt:
+			return parquet.Int64Value(rv.Int()), true
+		case columnUint:
+			if rv.Int() >= 0 {
+				return parquet.Int64Value(rv.Int()), true
+			}
+		case columnTimestamp:
+			return parquet.Int64Value(rv.Int() * 1000), true
+		case columnFloat:
+			return parquet.DoubleValue(float64(rv.Int())), true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch kind {
+		case columnUint:
+			return parquet.Int64Value(int64(rv.Uint())), true
+		case columnInt:
+			if rv.Uint() <= math.MaxInt64 {
+				return parquet.Int64Value(int64(rv.Uint())), true
+			}
+		case columnFloat:
+			return parquet.DoubleValue(float64(rv.Uint())), true
+		}
+	case reflect.Float32, reflect.Float64:
+		if kind == columnFloat {
+			return parquet.DoubleValue(rv.Float()), true
+		}
+	}
+	return parquet.Value{}, false
+}
+
+func stringOf(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case base.Address:
+		return v.Hex()
+	case base.Hash:
+		return v.Hex()
+	case base.Wei:
+		return v.String()
+	case *base.Wei:
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer:
+		if bytes, err := json.Marshal(value); err == nil {
+			return string(bytes)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// orderedGroup is a parquet group whose columns keep the order of the model's fields. (The
+// library's parquet.Group sorts its columns by name.)
+type orderedGroup struct {
+	parquet.Group
+	fields []parquet.Field
+}
+
+func (g *orderedGroup) Fields() []parquet.Field {
+	return g.fields
+}
+
+type orderedField struct {
+	parquet.Node
+	name string
+}
+
+func (f *orderedField) Name() string {
+	return f.name
+}
+
+func (f *orderedField) Value(base reflect.Value) reflect.Value {
+	return base.MapIndex(reflect.ValueOf(f.name))
+}
@@ -0,0 +1,195 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/parquet-go/parquet-go"
+)
+
+func parquetModel(bn base.Blknum, extra bool) types.Model {
+	m := types.Model{
+		Data: map[string]any{
+			"blockNumber": bn,
+			"timestamp":   base.Timestamp(1609459200),
+			"from":        base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b"),
+			"value":       "123456789012345678901234567890",
+			"isError":     bn%2 == 1,
+			"spotPrice":   1.5,
+			"articulated": map[string]any{"name": "transfer"},
+		},
+		Order: []string{"blockNumber", "timestamp", "from", "value", "isError", "spotPrice", "articulated"},
+	}
+	if extra {
+		m.Data["extra"] = "added"
+		m.Order = append(m.Order, "extra")
+	}
+	return m
+}
+
+func TestParquetWriter(t *testing.T) {
+	buf := bytes.Buffer{}
+	pw := NewParquetWriter(&buf)
+	pw.RowGroupSize = 2
+	for i := 0; i < 5; i++ {
+		if err := pw.WriteModel(parquetModel(base.Blknum(i), i == 3)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.NumRows() != 5 || len(f.RowGroups()) != 3 {
+		t.Errorf("expected 5 rows in 3 row groups, got %d rows in %d groups", f.NumRows(), len(f.RowGroups()))
+	}
+
+	fields := f.Schema().Fields()
+	expected := []struct {
+		name string
+		kind parquet.Kind
+	}{
+		{"blockNumber", parquet.Int64},
+		{"timestamp", parquet.Int64},
+		{"from", parquet.ByteArray},
+		{"value", parquet.ByteArray},
+		{"isError", parquet.Boolean},
+		{"spotPrice", parquet.Double},
+		{"articulated", parquet.ByteArray},
+		{"extra", parquet.ByteArray},
+	}
+	if len(fields) != len(expected) {
+		t.Fatalf("expected %d columns, got %d", len(expected), len(fields))
+	}
+	for i, want := range expected {
+		if fields[i].Name() != want.name || fields[i].Type().Kind() != want.kind {
+			t.Errorf("column %d: got %s %s, want %s %s", i, fields[i].Name(), fields[i].Type().Kind(), want.name, want.kind)
+		}
+	}
+	if lt := fields[1].Type().LogicalType(); lt == nil || lt.Timestamp == nil {
+		t.Error("timestamp column should have the timestamp logical type")
+	}
+
+	rows := make([]parquet.Row, 5)
+	reader := parquet.NewReader(bytes.NewReader(buf.Bytes()))
+	n := 0
+	for n < len(rows) {
+		cnt, err := reader.ReadRows(rows[n:])
+		// the values of a row may refer to the reader's buffers, which are reused
+		for i := n; i < n+cnt; i++ {
+			rows[i] = rows[i].Clone()
+		}
+		n += cnt
+		if err != nil || cnt == 0 {
+			break
+		}
+	}
+	if n != 5 {
+		t.Fatalf("expected to read 5 rows, got %d", n)
+	}
+	row := rows[3]
+	if row[0].Int64() != 3 || row[1].Int64() != 1609459200000 || !row[4].Boolean() || row[5].Double() != 1.5 {
+		t.Errorf("unexpected values %v", row)
+	}
+	if string(row[2].ByteArray()) != "0xf503017d7baf7fbc0fff7492b751025c6a78179b" ||
+		string(row[3].ByteArray()) != "123456789012345678901234567890" ||
+		string(row[6].ByteArray()) != `{"name":"transfer"}` ||
+		string(row[7].ByteArray()) != "added" {
+		t.Errorf("unexpected strings %v", row)
+	}
+	// a field first seen in a later row is null in the earlier ones
+	if !rows[0][7].IsNull() || !rows[4][7].IsNull() {
+		t.Errorf("expected nulls in rows without the field, got %v and %v", rows[0][7], rows[4][7])
+	}
+}
+
+func TestParquetWriterTypes(t *testing.T) {
+	model := func(value any) types.Model {
+		return types.Model{Data: map[string]any{"value": value}, Order: []string{"value"}}
+	}
+
+	// a column's type is that of its first value that is not null
+	buf := bytes.Buffer{}
+	pw := NewParquetWriter(&buf)
+	for _, value := range []any{nil, uint64(7)} {
+		if err := pw.WriteModel(model(value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind := f.Schema().Fields()[0].Type().Kind(); kind != parquet.Int64 {
+		t.Errorf("expected an integer column, got %s", kind)
+	}
+
+	// a later value of another type is an error rather than a null
+	pw = NewParquetWriter(&bytes.Buffer{})
+	defer pw.Close()
+	if err := pw.WriteModel(model(uint64(7))); err != nil {
+		t.Fatal(err)
+	}
+	if err := pw.WriteModel(model("seven")); err == nil {
+		t.Error("expected an error writing a string to an integer column")
+	}
+}
+
+func TestParquetWriterEmpty(t *testing.T) {
+	buf := bytes.Buffer{}
+	pw := NewParquetWriter(&buf)
+	if err := pw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.NumRows() != 0 || len(f.Schema().Fields()) != 1 {
+		t.Errorf("expected no rows and one column, got %d rows and columns %v", f.NumRows(), f.Schema().Fields())
+	}
+	if _, err := pw.Write([]byte("text")); err == nil {
+		t.Error("expected an error writing raw bytes")
+	}
+}
+
+func TestStreamManyParquet(t *testing.T) {
+	buf := bytes.Buffer{}
+	pw := NewParquetWriter(&buf)
+	renderData := func(modelChan chan types.Modeler, errorChan chan error) {
+		for i := 0; i < 3; i++ {
+			modelChan <- &types.Receipt{
+				BlockNumber:     base.Blknum(123 + i),
+				TransactionHash: base.HexToHash("0xdeadbeef"),
+				GasUsed:         100,
+				Status:          1,
+			}
+		}
+	}
+
+	rCtx := NewRenderContext()
+	if err := StreamMany(rCtx, renderData, OutputOptions{Writer: pw, Format: "parquet"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := input.Model("mainnet", "parquet", false, nil).Order
+	if f.NumRows() != 3 || len(f.Schema().Fields()) != len(order) || f.Schema().Fields()[0].Name() != order[0] {
+		t.Errorf("unexpected file with %d rows and columns %v", f.NumRows(), f.Schema().Fields())
+	}
+}
@@ -2,6 +2,7 @@ package output
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
@@ -11,10 +12,17 @@ type RenderCtx struct {
 	Cancel    context.CancelFunc `json:"-"`
 	ModelChan chan types.Modeler `json:"-"`
 	ErrorChan chan error         `json:"-"`
+	nErrors   int32
 }
 
 func NewRenderContext() *RenderCtx {
-	ctx, cancel := context.WithCancel(context.Background())
+	return NewRenderContextFrom(context.Background())
+}
+
+// NewRenderContextFrom returns a render context that is canceled when ctx is done (for
+// example, when an API request's client goes away or a background job is canceled)
+func NewRenderContextFrom(ctx context.Context) *RenderCtx {
+	ctx, cancel := context.WithCancel(ctx)
 	return &RenderCtx{
 		Ctx:    ctx,
 		Cancel: cancel,
@@ -37,3 +45,9 @@ func (r *RenderCtx) WasCanceled() bool {
 		return false
 	}
 }
+
+// HadErrors returns true if StreamMany reported any errors. StreamMany reports the errors sent
+// on its error channel (in the output or the log) rather than returning them.
+func (r *RenderCtx) HadErrors() bool {
+	return atomic.LoadInt32(&r.nErrors) > 0
+}
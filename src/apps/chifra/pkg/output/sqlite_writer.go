@@ -0,0 +1,356 @@
+package output
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	_ "modernc.org/sqlite"
+)
+
+// SqliteBatchSize is the number of rows the SqliteWriter writes in each database transaction
+const SqliteBatchSize = 10000
+
+// naturalKey is the primary key of the table of a model with a natural key
+type naturalKey struct {
+	fields []string
+	// optional holds the definitions of the key's columns that only some rows have (for example,
+	// the tokenId of an NFT's statements). They are created with the table and, with a default,
+	// complete the key of rows without them.
+	optional map[string]string
+}
+
+// naturalKeys are the primary keys of the tables of models with a natural key. The tables of
+// other models (and tables whose first row is missing one of the key's required fields, for
+// example because of --fields) have no primary key, so their rows are appended rather than upserted.
+var naturalKeys = map[string]naturalKey{
+	"appearances": {fields: []string{"address", "blockNumber", "transactionIndex"}},
+	"blocks":      {fields: []string{"blockNumber"}},
+	"logs":        {fields: []string{"blockNumber", "transactionIndex", "logIndex"}},
+	"names":       {fields: []string{"address"}},
+	"receipts":    {fields: []string{"blockNumber", "transactionIndex"}},
+	"states":      {fields: []string{"address", "blockNumber"}},
+	"statements": {
+		fields:   []string{"accountedFor", "blockNumber", "transactionIndex", "logIndex", "assetAddr", "tokenId"},
+		optional: map[string]string{"tokenId": "TEXT NOT NULL DEFAULT ''"},
+	},
+	"tokens": {
+		fields:   []string{"address", "holder", "blockNumber", "transactionIndex"},
+		optional: map[string]string{"transactionIndex": "INTEGER NOT NULL DEFAULT 0"},
+	},
+	"traces":       {fields: []string{"blockNumber", "transactionIndex", "traceAddress"}},
+	"transactions": {fields: []string{"blockNumber", "transactionIndex"}},
+	"withdrawals":  {fields: []string{"blockNumber", "index"}},
+}
+
+type sqliteTable struct {
+	columns map[string]bool
+	keys    []string
+}
+
+// SqliteWriter writes models into the tables of a SQLite database. Each kind of model has its
+// own table (named for the model, for example "transactions"), created from the first row
+// written to it. Columns for fields first seen in later rows are added as they appear. Rows are
+// upserted on the table's natural key, so writing the same data again updates rather than
+// duplicates it. Close must be called to commit the final batch.
+type SqliteWriter struct {
+	Path   string
+	db     *sql.DB
+	tx     *sql.Tx
+	tables map[string]*sqliteTable
+	stmts  map[string]*sql.Stmt
+	nRows  int
+}
+
+// NewSqliteWriter opens (or creates) the database at path
+func NewSqliteWriter(path string) (*SqliteWriter, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// A single connection lets the batch's transaction reuse the prepared statements
+	db.SetMaxOpenConns(1)
+	if _, err = db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not open database %s: %w", path, err)
+	}
+	return &SqliteWriter{
+		Path:   path,
+		db:     db,
+		tables: make(map[string]*sqliteTable),
+		stmts:  make(map[string]*sql.Stmt),
+	}, nil
+}
+
+// Write is present so that the SqliteWriter may be used as the global writer. The database
+// contains only models, so writing raw bytes is an error.
+func (w *SqliteWriter) Write(p []byte) (n int, err error) {
+	return 0, errors.New("sqlite output may only contain data models")
+}
+
+// TableName returns the name of the table holding models of the same type as the given one
+func TableName(model types.Modeler) string {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	name := strings.ToLower(t.Name()[:1]) + t.Name()[1:]
+	if strings.HasSuffix(name, "s") {
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// WriteModel upserts the model into the given table
+func (w *SqliteWriter) WriteModel(table string, model types.Model) error {
+	tbl, err := w.prepareTable(table, model)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := w.statement(table, tbl, model.Order)
+	if err != nil {
+		return err
+	}
+
+	if w.tx == nil {
+		if w.tx, err = w.db.Begin(); err != nil {
+			return err
+		}
+	}
+
+	values := make([]any, 0, len(model.Order))
+	for _, key := range model.Order {
+		values = append(values, sqliteValueOf(model.Data[key]))
+	}
+	if _, err = w.tx.Stmt(stmt).Exec(values...); err != nil {
+		return err
+	}
+
+	w.nRows++
+	if w.nRows%SqliteBatchSize == 0 {
+		return w.commit()
+	}
+	return nil
+}
+
+// Close commits any uncommitted rows and closes the database
+func (w *SqliteWriter) Close() error {
+	err := w.commit()
+	for _, stmt := range w.stmts {
+		stmt.Close()
+	}
+	if cerr := w.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (w *SqliteWriter) commit() error {
+	if w.tx == nil {
+		return nil
+	}
+	err := w.tx.Commit()
+	w.tx = nil
+	return err
+}
+
+// prepareTable creates the table if it does not exist and adds any of the model's fields that
+// the table does not yet have as columns. There is a single connection to the database, so the
+// batch's transaction is committed before the schema is read or changed (and before a new
+// statement is prepared).
+func (w *SqliteWriter) prepareTable(table string, model types.Model) (*sqliteTable, error) {
+	tbl := w.tables[table]
+	if tbl == nil {
+		if err := w.commit(); err != nil {
+			return nil, err
+		}
+		var err error
+		if tbl, err = w.readTable(table); err != nil {
+			return nil, err
+		}
+		if tbl == nil {
+			if tbl, err = w.createTable(table, model); err != nil {
+				return nil, err
+			}
+		}
+		w.tables[table] = tbl
+	}
+
+	for _, key := range model.Order {
+		if !tbl.columns[key] {
+			if err := w.commit(); err != nil {
+				return nil, err
+			}
+			sqlStr := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", quote(table), quote(key), sqliteTypeOf(model.Data[key]))
+			if _, err := w.db.Exec(sqlStr); err != nil {
+				return nil, err
+			}
+			tbl.columns[key] = true
+		}
+	}
+
+	return tbl, nil
+}
+
+// readTable returns the columns and primary key of an existing table or nil if there is no such table
+func (w *SqliteWriter) readTable(table string) (*sqliteTable, error) {
+	rows, err := w.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", quote(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tbl := &sqliteTable{columns: make(map[string]bool)}
+	pks := make(map[int]string)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, typ string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		tbl.columns[name] = true
+		if pk > 0 {
+			pks[pk] = name
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(tbl.columns) == 0 {
+		return nil, nil
+	}
+	for i := 1; i <= len(pks); i++ {
+		tbl.keys = append(tbl.keys, pks[i])
+	}
+	return tbl, nil
+}
+
+func (w *SqliteWriter) createTable(table string, model types.Model) (*sqliteTable, error) {
+	tbl := &sqliteTable{columns: make(map[string]bool)}
+	for _, key := range model.Order {
+		tbl.columns[key] = true
+	}
+
+	key := naturalKeys[table]
+	tbl.keys = key.fields
+	for _, field := range key.fields {
+		if !tbl.columns[field] && key.optional[field] == "" {
+			tbl.keys = nil
+			break
+		}
+	}
+	optional := make(map[string]string)
+	for _, field := range tbl.keys {
+		if def := key.optional[field]; def != "" {
+			optional[field] = def
+		}
+	}
+
+	defs := make([]string, 0, len(model.Order)+len(optional)+1)
+	for _, field := range model.Order {
+		if def, ok := optional[field]; ok {
+			defs = append(defs, quote(field)+" "+def)
+			delete(optional, field)
+		} else {
+			defs = append(defs, quote(field)+" "+sqliteTypeOf(model.Data[field]))
+		}
+	}
+	// The optional key fields the first row does not have
+	for _, field := range tbl.keys {
+		if def, ok := optional[field]; ok {
+			tbl.columns[field] = true
+			defs = append(defs, quote(field)+" "+def)
+		}
+	}
+	if len(tbl.keys) > 0 {
+		defs = append(defs, "PRIMARY KEY ("+quoteAll(tbl.keys)+")")
+	}
+
+	sqlStr := fmt.Sprintf("CREATE TABLE %s (%s)", quote(table), strings.Join(defs, ", "))
+	if _, err := w.db.Exec(sqlStr); err != nil {
+		return nil, err
+	}
+	return tbl, nil
+}
+
+// statement returns the (cached) upsert statement for rows with the given fields
+func (w *SqliteWriter) statement(table string, tbl *sqliteTable, fields []string) (*sql.Stmt, error) {
+	id := table + ":" + strings.Join(fields, ",")
+	if stmt, ok := w.stmts[id]; ok {
+		return stmt, nil
+	}
+	if err := w.commit(); err != nil {
+		return nil, err
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(fields)), ", ")
+	sqlStr := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quote(table), quoteAll(fields), placeholders)
+	if len(tbl.keys) > 0 {
+		updates := make([]string, 0, len(fields))
+		for _, field := range fields {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", quote(field), quote(field)))
+		}
+		sqlStr += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", quoteAll(tbl.keys), strings.Join(updates, ", "))
+	}
+
+	stmt, err := w.db.Prepare(sqlStr)
+	if err != nil {
+		return nil, err
+	}
+	w.stmts[id] = stmt
+	return stmt, nil
+}
+
+func sqliteTypeOf(value any) string {
+	switch kindOf(value) {
+	case columnBool, columnInt, columnUint, columnTimestamp:
+		return "INTEGER"
+	case columnFloat:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+// sqliteValueOf converts the value to one the driver stores in a column of the value's type.
+// Timestamps are stored as seconds and unsigned values too large for an INTEGER as text.
+func sqliteValueOf(value any) any {
+	if value == nil {
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch kindOf(value) {
+	case columnBool:
+		return rv.Bool()
+	case columnInt, columnTimestamp:
+		return rv.Int()
+	case columnUint:
+		if rv.Uint() > math.MaxInt64 {
+			return fmt.Sprint(rv.Uint())
+		}
+		return int64(rv.Uint())
+	case columnFloat:
+		return rv.Float()
+	default:
+		return stringOf(value)
+	}
+}
+
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteAll(names []string) string {
+	ret := make([]string, 0, len(names))
+	for _, name := range names {
+		ret = append(ret, quote(name))
+	}
+	return strings.Join(ret, ", ")
+}
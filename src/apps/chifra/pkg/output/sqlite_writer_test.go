@@ -0,0 +1,137 @@
+package output
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func sqliteTransactions(value string) []types.Transaction {
+	wei, _ := new(base.Wei).SetString(value, 10)
+	return []types.Transaction{
+		{BlockNumber: 100, TransactionIndex: 1, Timestamp: 1609459200, Value: *base.NewWei(1)},
+		{BlockNumber: 100, TransactionIndex: 2, Timestamp: 1609459200, Value: *base.NewWei(2)},
+		{BlockNumber: 101, TransactionIndex: 0, Timestamp: 1609459212, Value: *wei},
+	}
+}
+
+func writeSqlite(t *testing.T, path string, models ...types.Modeler) {
+	t.Helper()
+	sw, err := NewSqliteWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range models {
+		if err := sw.WriteModel(TableName(m), m.Model("mainnet", "sqlite", false, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSqliteUpsert(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sqlite")
+
+	first := sqliteTransactions("3")
+	writeSqlite(t, path, &first[0], &first[1], &first[2])
+	// Running the same export again (with a changed value) updates rather than duplicates
+	second := sqliteTransactions("123456789012345678901234567890")
+	writeSqlite(t, path, &second[2], &second[0])
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var cnt int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM transactions`).Scan(&cnt); err != nil || cnt != 3 {
+		t.Fatalf("expected 3 rows, got %d (%v)", cnt, err)
+	}
+
+	var value string
+	var timestamp int64
+	row := db.QueryRow(`SELECT "value", "timestamp" FROM transactions WHERE "blockNumber" = 101 AND "transactionIndex" = 0`)
+	if err := row.Scan(&value, &timestamp); err != nil {
+		t.Fatal(err)
+	}
+	if value != "123456789012345678901234567890" || timestamp != 1609459212 {
+		t.Errorf("unexpected row value %s timestamp %d", value, timestamp)
+	}
+
+	var typ string
+	if err := db.QueryRow(`SELECT type FROM pragma_table_info('transactions') WHERE name = 'blockNumber'`).Scan(&typ); err != nil || typ != "INTEGER" {
+		t.Errorf("expected an INTEGER blockNumber, got %s (%v)", typ, err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('transactions') WHERE pk > 0`).Scan(&cnt); err != nil || cnt != 2 {
+		t.Errorf("expected a two column primary key, got %d (%v)", cnt, err)
+	}
+}
+
+func TestSqliteTables(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sqlite")
+
+	name := types.Name{Address: base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b"), Name: "First"}
+	log := types.Log{BlockNumber: 100, TransactionIndex: 1, LogIndex: 4}
+	writeSqlite(t, path, &name, &log)
+	name.Name = "Second"
+	writeSqlite(t, path, &name)
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var cnt int
+	var got string
+	if err := db.QueryRow(`SELECT COUNT(*), MAX("name") FROM names`).Scan(&cnt, &got); err != nil || cnt != 1 || got != "Second" {
+		t.Errorf("expected one upserted name, got %d %s (%v)", cnt, got, err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM logs`).Scan(&cnt); err != nil || cnt != 1 {
+		t.Errorf("expected one log, got %d (%v)", cnt, err)
+	}
+
+	if TableName(&types.Status{}) != "statuses" || TableName(&log) != "logs" {
+		t.Error("unexpected table names", TableName(&types.Status{}), TableName(&log))
+	}
+}
+
+func TestSqliteKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sqlite")
+
+	nft := func(id int64) *types.Statement {
+		return &types.Statement{BlockNumber: 100, TransactionIndex: 1, LogIndex: 2, AssetType: "erc721", TokenId: *base.NewWei(id)}
+	}
+	ether := &types.Statement{BlockNumber: 100, TransactionIndex: 1, LogIndex: 2}
+	status := &types.Status{}
+
+	// Two tokens moved by the same log are different statements, and statements without a
+	// tokenId are upserted like the others
+	for i := 0; i < 2; i++ {
+		writeSqlite(t, path, ether, nft(1), nft(2), status)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var cnt int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM statements`).Scan(&cnt); err != nil || cnt != 3 {
+		t.Errorf("expected 3 statements, got %d (%v)", cnt, err)
+	}
+	// a table without a natural key has no primary key, so its rows are appended
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('statuses') WHERE pk > 0`).Scan(&cnt); err != nil || cnt != 0 {
+		t.Errorf("expected no primary key, got %d (%v)", cnt, err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM statuses`).Scan(&cnt); err != nil || cnt != 2 {
+		t.Errorf("expected 2 statuses, got %d (%v)", cnt, err)
+	}
+}
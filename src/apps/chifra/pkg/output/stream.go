@@ -3,13 +3,17 @@ package output
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"text/template"
 
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/expr"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/progress"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
 
@@ -36,6 +40,10 @@ type OutputOptions struct {
 	Writer io.Writer
 	// Extra options passed to model, for example command-specific output formatting flags
 	Extra map[string]any
+	// If present, the comma-separated columns (fields, dotted paths, or expressions) to output
+	Fields string
+	// If present, an expression filtering the records to output
+	Where string
 }
 
 var formatToSeparator = map[string]rune{
@@ -56,13 +64,26 @@ func StreamModel(w io.Writer, model types.Model, options OutputOptions) error {
 			// This should never happen
 			panic("streaming JSON requires JsonWriter")
 		}
-		_, err := jw.WriteCompoundItem("", model.Data)
+		var obj any = model.Data
+		if len(options.Fields) > 0 {
+			// Keep the order of the selected fields
+			obj = orderedObject(model)
+		}
+		_, err := jw.WriteCompoundItem("", obj)
 		if err != nil {
 			return err
 		}
 		return nil
 	}
 
+	if options.Format == "parquet" {
+		pw, ok := w.(*ParquetWriter)
+		if !ok {
+			return errors.New("streaming parquet requires ParquetWriter")
+		}
+		return pw.WriteModel(model)
+	}
+
 	// Store map items as strings. All formats other than JSON need string data
 	strs := make([]string, 0, len(model.Order))
 	for _, key := range model.Order {
@@ -122,6 +143,18 @@ func StreamMany(rCtx *RenderCtx, fetchData fetchDataFunc, options OutputOptions)
 		close(modelChan)
 		close(errorChan)
 	}()
+	defer func() {
+		// If we return early (the context is canceled or a write fails), fetchData may still be
+		// sending. Drain its channels so that its goroutine finishes rather than blocking forever.
+		go func() {
+			for range modelChan {
+			}
+		}()
+		go func() {
+			for range errorChan {
+			}
+		}()
+	}()
 
 	isJson := options.Format == "json"
 	var jw *JsonWriter
@@ -143,6 +176,16 @@ func StreamMany(rCtx *RenderCtx, fetchData fetchDataFunc, options OutputOptions)
 		return err
 	}
 
+	fields, err := ParseFields(options.Fields)
+	if err != nil {
+		return err
+	}
+
+	where, err := expr.LoadWhere(options.Where, options.Chain)
+	if err != nil {
+		return err
+	}
+
 	errsMutex := sync.Mutex{}
 	for {
 		select {
@@ -151,16 +194,28 @@ func StreamMany(rCtx *RenderCtx, fetchData fetchDataFunc, options OutputOptions)
 				return nil
 			}
 
+			if !where.Matches(model, options.Chain, options.Format, options.Verbose, options.Extra) {
+				continue
+			}
+
 			// If the output is JSON and we are printing another item, put `,` in front of it
 			var err error
-			modelValue := model.Model(options.Chain, options.Format, options.Verbose, options.Extra)
+			var modelValue types.Model
+			if len(fields) > 0 {
+				modelValue = fields.Project(model, options.Chain, options.Format, options.Verbose, options.Extra)
+			} else {
+				modelValue = model.Model(options.Chain, options.Format, options.Verbose, options.Extra)
+			}
 			if customFormat {
 				err = StreamWithTemplate(options.Writer, modelValue, tmpl)
+			} else if sw, ok := options.Writer.(*SqliteWriter); ok {
+				err = sw.WriteModel(TableName(model), modelValue)
 			} else {
 				err = StreamModel(options.Writer, modelValue, OutputOptions{
 					NoHeader:   !first || options.NoHeader,
 					Format:     options.Format,
 					JsonIndent: "  ",
+					Fields:     options.Fields,
 				})
 			}
 			if err != nil {
@@ -172,6 +227,10 @@ func StreamMany(rCtx *RenderCtx, fetchData fetchDataFunc, options OutputOptions)
 			if !ok {
 				continue
 			}
+			atomic.AddInt32(&rCtx.nErrors, 1)
+			if reporter := progress.ReporterFrom(rCtx.Ctx); reporter != nil {
+				reporter.ReportError(err)
+			}
 			errsMutex.Lock()
 			if isJson {
 				jw.WriteError(err)
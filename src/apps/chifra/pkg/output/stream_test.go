@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
@@ -191,6 +192,37 @@ func TestStreamMany(t *testing.T) {
 	if result.Data[1].BlockNumber != 124 {
 		t.Fatal("mismatched data")
 	}
+	if rCtx.HadErrors() {
+		t.Fatal("no errors were reported")
+	}
+}
+
+func TestStreamManyErrors(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	jw := NewJsonWriter(buffer)
+	jw.DefaultField = DefaultField{
+		Key:       "data",
+		FieldType: FieldArray,
+	}
+
+	renderData := func(modelChan chan types.Modeler, errorChan chan error) {
+		modelChan <- &types.Receipt{BlockNumber: 123}
+		errorChan <- fmt.Errorf("could not fetch block 124")
+	}
+
+	// StreamMany reports the error rather than returning it, but the caller can tell
+	rCtx := NewRenderContext()
+	err := StreamMany(rCtx, renderData, OutputOptions{
+		Writer: jw,
+		Format: "json",
+	})
+	jw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rCtx.HadErrors() {
+		t.Fatal("expected the error to be recorded")
+	}
 }
 
 func TestApiFormat(t *testing.T) {
@@ -214,3 +246,35 @@ func TestApiFormat(t *testing.T) {
 		t.Fatal("Api format is no longer allow. Should error here.")
 	}
 }
+
+func TestStreamManyCanceled(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	jw := NewJsonWriter(buffer)
+	jw.DefaultField = DefaultField{
+		Key:       "data",
+		FieldType: FieldArray,
+	}
+
+	rCtx := NewRenderContext()
+	finished := make(chan bool)
+	renderData := func(modelChan chan types.Modeler, errorChan chan error) {
+		defer close(finished)
+		for bn := base.Blknum(0); bn < 100; bn++ {
+			if bn == 1 {
+				rCtx.Cancel()
+			}
+			modelChan <- &types.Receipt{BlockNumber: bn}
+			errorChan <- fmt.Errorf("could not fetch block %d", bn)
+		}
+	}
+
+	// StreamMany returns when the context is canceled, but fetchData must not be left blocked
+	if err := StreamMany(rCtx, renderData, OutputOptions{Writer: jw, Format: "json"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetchData was left blocked after the context was canceled")
+	}
+}
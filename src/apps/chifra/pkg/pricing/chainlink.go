@@ -0,0 +1,91 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+var (
+	chainlinkEthUsd                = base.HexToAddress("0x5f4ec3df9cbd43714fe2740f5e3616155c5b8419") // ETH / USD aggregator proxy
+	chainlinkEthUsd_deployed       = base.Blknum(10606501)
+	chainlinkFeedRegistry          = base.HexToAddress("0x47fb2585d2c56fe188d0e6ec628a38b74fceeedf")
+	chainlinkFeedRegistry_deployed = base.Blknum(12864088)
+	chainlinkUsdDenomination       = base.HexToAddress("0x0000000000000000000000000000000000000348") // ISO 4217 code for USD
+)
+
+const (
+	clLatestRoundDataSelector         = "0xfeaf968c" // latestRoundData()
+	clDecimalsSelector                = "0x313ce567" // decimals()
+	clRegistryLatestRoundDataSelector = "0xbcfd032d" // latestRoundData(address,address)
+	clRegistryDecimalsSelector        = "0x58e2d3a8" // decimals(address,address)
+)
+
+// chainlinkSource prices assets using Chainlink's latestRoundData as of the statement's block. ETH
+// is priced with the ETH / USD aggregator. Tokens are priced with the Feed Registry (so only
+// tokens with a USD feed are priced).
+type chainlinkSource struct{}
+
+func (s chainlinkSource) Name() string {
+	return "chainlink"
+}
+
+func (s chainlinkSource) PriceUsd(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error) {
+	var roundData, decimals []byte
+	if statement.IsEth() {
+		if statement.BlockNumber <= chainlinkEthUsd_deployed {
+			return 0.0, "", nil
+		}
+		if roundData, err = ethCall(conn, chainlinkEthUsd, clLatestRoundDataSelector, statement.BlockNumber); err != nil {
+			return 0.0, "not-priced", err
+		}
+		if decimals, err = ethCall(conn, chainlinkEthUsd, clDecimalsSelector, statement.BlockNumber); err != nil {
+			return 0.0, "not-priced", err
+		}
+
+	} else {
+		if statement.BlockNumber <= chainlinkFeedRegistry_deployed {
+			return 0.0, "", nil
+		}
+		args := encodeAddress(statement.AssetAddr) + encodeAddress(chainlinkUsdDenomination)
+		if roundData, err = ethCall(conn, chainlinkFeedRegistry, clRegistryLatestRoundDataSelector+args, statement.BlockNumber); err != nil {
+			// the registry reverts if there's no feed for the asset
+			return 0.0, "not-priced", err
+		}
+		if decimals, err = ethCall(conn, chainlinkFeedRegistry, clRegistryDecimalsSelector+args, statement.BlockNumber); err != nil {
+			return 0.0, "not-priced", err
+		}
+	}
+
+	if price, err = decodeRoundData(roundData, decimals); err != nil {
+		return 0.0, "not-priced", err
+	}
+
+	msg := fmt.Sprintf("Chainlink price for %s at block %d: %f", statement.AssetAddr.Hex(), statement.BlockNumber, price)
+	logger.TestLog(true, msg)
+
+	return price, s.Name(), nil
+}
+
+// decodeRoundData returns the answer from the return of latestRoundData (roundId, answer,
+// startedAt, updatedAt, answeredInRound) scaled by the feed's decimals.
+func decodeRoundData(roundData, decimals []byte) (base.Float, error) {
+	answer, err := signedWordAt(roundData, 1)
+	if err != nil {
+		return 0.0, err
+	} else if answer.Sign() <= 0 {
+		return 0.0, fmt.Errorf("invalid chainlink answer %s", answer.String())
+	}
+
+	dec, err := wordAt(decimals, 0)
+	if err != nil {
+		return 0.0, err
+	}
+
+	f, _ := answer.Float64()
+	return base.Float(f / math.Pow(10, float64(dec.Uint64()))), nil
+}
@@ -1,2 +1,4 @@
-// Package pricing calculates US dollar prices from Uniswap pairs or falls back onto Maker
+// Package pricing calculates US dollar prices for accounting statements from a per-chain, prioritized
+// list of price sources (stable coins, Maker, Uniswap V2 and V3, Chainlink, or a local price file). It
+// also converts those prices to other fiat currencies using a local table of daily exchange rates.
 package pricing
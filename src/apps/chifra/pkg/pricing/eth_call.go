@@ -0,0 +1,78 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// The newer price sources call contracts whose ABIs may not be in the user's cache, so they
+// encode their calls (and decode the results) directly rather than through the call package.
+
+var errEmptyReturn = errors.New("empty return from eth_call (is the contract deployed?)")
+
+// ethCall calls the given contract at the given block and returns the raw result
+func ethCall(conn *rpc.Connection, to base.Address, data string, bn base.Blknum) ([]byte, error) {
+	params := query.Params{
+		map[string]any{
+			"to":   to.Hex(),
+			"data": data,
+		},
+		fmt.Sprintf("0x%x", bn),
+	}
+
+	result, err := query.Query[string](conn.Chain, "eth_call", params)
+	if err != nil {
+		return nil, err
+	} else if result == nil || *result == "0x" || len(*result) == 0 {
+		return nil, errEmptyReturn
+	}
+
+	return hexutil.Decode(*result)
+}
+
+// encodeAddress returns the address as a hex ABI word (without the leading 0x)
+func encodeAddress(addr base.Address) string {
+	return fmt.Sprintf("%064s", strings.TrimPrefix(addr.Hex(), "0x"))
+}
+
+// encodeUint returns the value as a hex ABI word (without the leading 0x)
+func encodeUint(val uint64) string {
+	return fmt.Sprintf("%064x", val)
+}
+
+// wordAt returns the i'th 32-byte word of the data as an unsigned integer
+func wordAt(data []byte, i int) (*big.Int, error) {
+	start := i * 32
+	if len(data) < start+32 {
+		return nil, fmt.Errorf("return data too short (%d bytes) to read word %d", len(data), i)
+	}
+	return new(big.Int).SetBytes(data[start : start+32]), nil
+}
+
+// signedWordAt returns the i'th 32-byte word of the data as a two's complement signed integer
+func signedWordAt(data []byte, i int) (*big.Int, error) {
+	ret, err := wordAt(data, i)
+	if err != nil {
+		return nil, err
+	}
+	if ret.Bit(255) == 1 {
+		ret.Sub(ret, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return ret, nil
+}
+
+// addressAt returns the i'th 32-byte word of the data as an address
+func addressAt(data []byte, i int) (base.Address, error) {
+	word, err := wordAt(data, i)
+	if err != nil {
+		return base.ZeroAddr, err
+	}
+	return base.BytesToAddress(word.Bytes()), nil
+}
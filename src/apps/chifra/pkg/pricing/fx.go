@@ -0,0 +1,149 @@
+package pricing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+)
+
+// Prices are reported in USD. To report them in another fiat currency, we read daily exchange
+// rates from fxRates.csv in the config folder. The file has a header row naming the `date`,
+// `currency` and `rate` columns. The rate is the number of units of the currency per US dollar
+// on that (UTC) day. If a day is missing, the most recent rate in the prior week is used.
+
+const (
+	fxFileName     = "fxRates.csv"
+	secondsPerDay  = 24 * 60 * 60
+	maxFxStaleDays = 7
+)
+
+type fxPoint struct {
+	day  int64
+	rate base.Float
+}
+
+// fxTable holds the daily rates keyed by uppercase currency code sorted by day
+type fxTable map[string][]fxPoint
+
+var fxTableMutex sync.Mutex
+var fxTableCache fxTable
+
+// loadFxTable reads and parses the FX rate file, or returns it from memory if already loaded
+func loadFxTable() (fxTable, error) {
+	fxTableMutex.Lock()
+	defer fxTableMutex.Unlock()
+
+	if fxTableCache != nil {
+		return fxTableCache, nil
+	}
+
+	path := filepath.Join(config.PathToRootConfig(), fxFileName)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("the --currency option requires %s: %w", path, err)
+	}
+	defer f.Close()
+
+	table, err := readFxCsv(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading fx file %s: %w", path, err)
+	}
+	fxTableCache = table
+	return table, nil
+}
+
+// readFxCsv reads daily rates from a CSV file whose header names the date, currency and rate
+// columns (in any order).
+func readFxCsv(r io.Reader) (fxTable, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	table := fxTable{}
+	if len(rows) == 0 {
+		return table, nil
+	}
+
+	cols := map[string]int{}
+	for i, name := range rows[0] {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "currency", "rate"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	for line, row := range rows[1:] {
+		ts, err := parsePriceTimestamp(row[cols["date"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(row[cols["rate"]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		} else if rate <= 0 {
+			return nil, fmt.Errorf("line %d: invalid rate %f", line+2, rate)
+		}
+		currency := strings.ToUpper(strings.TrimSpace(row[cols["currency"]]))
+		table[currency] = append(table[currency], fxPoint{day: int64(ts) / secondsPerDay, rate: base.Float(rate)})
+	}
+
+	for _, points := range table {
+		sort.Slice(points, func(i, j int) bool {
+			return points[i].day < points[j].day
+		})
+	}
+	return table, nil
+}
+
+// rate returns the rate for the currency on the day of the timestamp (or the most recent prior
+// rate no more than a week old)
+func (t fxTable) rate(currency string, ts base.Timestamp) (base.Float, error) {
+	points, ok := t[strings.ToUpper(currency)]
+	if !ok {
+		return 0.0, fmt.Errorf("no fx rates for currency %s", currency)
+	}
+
+	day := int64(ts) / secondsPerDay
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].day > day
+	})
+	if i == 0 || day-points[i-1].day > maxFxStaleDays {
+		return 0.0, fmt.Errorf("no %s fx rate on or shortly before %s", currency, base.FormattedDate(ts))
+	}
+	return points[i-1].rate, nil
+}
+
+// HasFxCurrency returns nil if the FX rate file exists and carries rates for the currency
+func HasFxCurrency(currency string) error {
+	table, err := loadFxTable()
+	if err != nil {
+		return err
+	}
+	if _, ok := table[strings.ToUpper(currency)]; !ok {
+		return fmt.Errorf("%s does not contain rates for %s", fxFileName, currency)
+	}
+	return nil
+}
+
+// FxRate returns the number of units of the currency per US dollar on the day of the timestamp
+func FxRate(currency string, ts base.Timestamp) (base.Float, error) {
+	table, err := loadFxTable()
+	if err != nil {
+		return 0.0, err
+	}
+	return table.rate(currency, ts)
+}
@@ -17,6 +17,21 @@ var (
 	makerDeployment = base.Blknum(3684349)
 )
 
+// makerSource prices ETH using the Maker medianizer. It is only used for blocks prior to the
+// deployment of Uniswap V2.
+type makerSource struct{}
+
+func (s makerSource) Name() string {
+	return "maker"
+}
+
+func (s makerSource) PriceUsd(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error) {
+	if !statement.IsEth() || statement.BlockNumber > uniswapFactoryV2_deployed {
+		return 0.0, "", nil
+	}
+	return priceUsdMaker(conn, statement)
+}
+
 func priceUsdMaker(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error) {
 	if statement.BlockNumber <= makerDeployment {
 		msg := fmt.Sprintf("Block %d is prior to deployment (%d) of Maker. No fallback pricing method", statement.BlockNumber, makerDeployment)
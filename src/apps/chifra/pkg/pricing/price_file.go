@@ -0,0 +1,203 @@
+package pricing
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// fileSource prices assets from a local price file named by the chain's `priceFile` setting. The
+// file may be .json (an array of records) or .csv (with a header row). Each record carries an
+// `asset` (an address or a symbol), a `timestamp` (a Unix timestamp or a date such as 2017-01-01
+// or 2017-01-01T12:00:00) and a `price` in USD. An asset is priced using its most recent record
+// at or before the statement's timestamp. Relative paths are relative to the config folder.
+type fileSource struct{}
+
+func (s fileSource) Name() string {
+	return "file"
+}
+
+func (s fileSource) PriceUsd(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error) {
+	path := config.GetPricing(conn.Chain).PriceFile
+	if len(path) == 0 {
+		return 0.0, "", nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(config.PathToRootConfig(), path)
+	}
+
+	table, err := loadPriceTable(path)
+	if err != nil {
+		return 0.0, "not-priced", err
+	}
+
+	if price, ok := table.lookup(statement.AssetAddr, statement.AssetSymbol, statement.Timestamp); ok {
+		return price, s.Name(), nil
+	}
+	return 0.0, "", nil
+}
+
+// priceRecord is a single row of a price file
+type priceRecord struct {
+	Asset     string     `json:"asset"`
+	Timestamp any        `json:"timestamp"`
+	Price     base.Float `json:"price"`
+}
+
+type pricePoint struct {
+	ts    base.Timestamp
+	price base.Float
+}
+
+// priceTable holds the prices from a price file keyed by lowercase address or uppercase symbol.
+// The points for each asset are sorted by timestamp.
+type priceTable map[string][]pricePoint
+
+var priceTablesMutex sync.Mutex
+var priceTables = map[string]priceTable{}
+
+// loadPriceTable reads and parses the price file, or returns it from memory if already loaded
+func loadPriceTable(path string) (priceTable, error) {
+	priceTablesMutex.Lock()
+	defer priceTablesMutex.Unlock()
+
+	if table, ok := priceTables[path]; ok {
+		return table, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []priceRecord
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = json.NewDecoder(f).Decode(&records)
+	} else {
+		records, err = readPriceCsv(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading price file %s: %w", path, err)
+	}
+
+	table, err := newPriceTable(records)
+	if err != nil {
+		return nil, fmt.Errorf("reading price file %s: %w", path, err)
+	}
+	priceTables[path] = table
+	return table, nil
+}
+
+// readPriceCsv reads price records from a CSV file whose header names the asset, timestamp
+// and price columns (in any order).
+func readPriceCsv(r io.Reader) ([]priceRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	} else if len(rows) == 0 {
+		return []priceRecord{}, nil
+	}
+
+	cols := map[string]int{}
+	for i, name := range rows[0] {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"asset", "timestamp", "price"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	records := make([]priceRecord, 0, len(rows)-1)
+	for line, row := range rows[1:] {
+		price, err := strconv.ParseFloat(strings.TrimSpace(row[cols["price"]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+		records = append(records, priceRecord{
+			Asset:     row[cols["asset"]],
+			Timestamp: row[cols["timestamp"]],
+			Price:     base.Float(price),
+		})
+	}
+	return records, nil
+}
+
+func newPriceTable(records []priceRecord) (priceTable, error) {
+	table := priceTable{}
+	for i, rec := range records {
+		ts, err := parsePriceTimestamp(rec.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		key := assetKey(rec.Asset)
+		table[key] = append(table[key], pricePoint{ts: ts, price: rec.Price})
+	}
+
+	for _, points := range table {
+		sort.Slice(points, func(i, j int) bool {
+			return points[i].ts < points[j].ts
+		})
+	}
+	return table, nil
+}
+
+// lookup returns the most recent price at or before ts for the asset (by address, then by symbol)
+func (t priceTable) lookup(addr base.Address, symbol string, ts base.Timestamp) (base.Float, bool) {
+	for _, key := range []string{assetKey(addr.Hex()), assetKey(symbol)} {
+		points := t[key]
+		i := sort.Search(len(points), func(i int) bool {
+			return points[i].ts > ts
+		})
+		if i > 0 {
+			return points[i-1].price, true
+		}
+	}
+	return 0.0, false
+}
+
+func assetKey(asset string) string {
+	asset = strings.TrimSpace(asset)
+	if strings.HasPrefix(asset, "0x") {
+		return strings.ToLower(asset)
+	}
+	return strings.ToUpper(asset)
+}
+
+var priceDateLayouts = []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// parsePriceTimestamp accepts a Unix timestamp (as a number or a string) or a UTC date
+func parsePriceTimestamp(value any) (base.Timestamp, error) {
+	switch v := value.(type) {
+	case float64:
+		return base.Timestamp(v), nil
+	case string:
+		v = strings.TrimSpace(v)
+		if ts, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return base.Timestamp(ts), nil
+		}
+		for _, layout := range priceDateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return base.Timestamp(t.Unix()), nil
+			}
+		}
+		return 0, fmt.Errorf("invalid timestamp %q", v)
+	}
+	return 0, fmt.Errorf("invalid timestamp %v", value)
+}
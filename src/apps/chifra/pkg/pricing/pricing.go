@@ -1,9 +1,10 @@
 package pricing
 
 import (
-	"fmt"
+	"sync"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
@@ -11,26 +12,71 @@ import (
 
 // TODO: Much of this reporting could be removed as it's only used for debugging
 
-// PriceUsd returns the price of the asset in USD
+// PriceUsd returns the price of the asset in USD. The chain's configured price sources are
+// consulted in priority order and the first source to price the asset wins. The returned source
+// is recorded in the statement's PriceSource field.
 func PriceUsd(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error) {
-	if statement.IsStableCoin() {
-		r := priceDebugger{
-			address: statement.AssetAddr,
-			symbol:  statement.AssetSymbol,
-		}
-		r.report("stable-coin")
-		return 1.0, "stable-coin", nil
+	names := config.GetPricing(conn.Chain).Sources
+	sources, err := sourcesFor(names)
+	if err != nil {
+		warnOnce(names, err)
+	}
+	return priceFromSources(sources, conn, statement)
+}
+
+var warnedMutex sync.Mutex
+var warned = map[string]bool{}
+
+// warnOnce reports a configuration problem the first time it's seen (not once per statement)
+func warnOnce(key string, err error) {
+	warnedMutex.Lock()
+	defer warnedMutex.Unlock()
+	if !warned[key] {
+		warned[key] = true
+		logger.Warn(err)
 	}
+}
 
-	if statement.BlockNumber <= uniswapFactoryV2_deployed {
-		if statement.IsEth() {
-			return priceUsdMaker(conn, statement)
-		} else {
-			msg := fmt.Sprintf("Block %d is prior to deployment (%d) of Uniswap V2. No other source for tokens prior to UniSwap", statement.BlockNumber, uniswapFactoryV2_deployed)
-			logger.TestLog(true, msg)
-			return 0.0, "token-not-priced-pre-uni", nil
+// priceFromSources returns the first price found by the given sources. If no source can price
+// the asset, the reason given by the first source that tried (and its error) is returned.
+func priceFromSources(sources []PriceSource, conn *rpc.Connection, statement *types.Statement) (base.Float, string, error) {
+	failedSource := ""
+	var failedErr error
+	for _, src := range sources {
+		price, source, err := src.PriceUsd(conn, statement)
+		if err == nil && price != 0 {
+			return price, source, nil
 		}
+		if len(failedSource) == 0 && (len(source) > 0 || err != nil) {
+			failedSource, failedErr = source, err
+			if len(failedSource) == 0 {
+				failedSource = "not-priced"
+			}
+		}
+	}
+
+	if len(failedSource) == 0 {
+		failedSource = "not-priced"
+	}
+	return 0.0, failedSource, failedErr
+}
+
+// stableCoinSource prices the well-known stable coins at one dollar
+type stableCoinSource struct{}
+
+func (s stableCoinSource) Name() string {
+	return "stable-coin"
+}
+
+func (s stableCoinSource) PriceUsd(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error) {
+	if !statement.IsStableCoin() {
+		return 0.0, "", nil
 	}
 
-	return priceUsdUniswap(conn, statement)
+	r := priceDebugger{
+		address: statement.AssetAddr,
+		symbol:  statement.AssetSymbol,
+	}
+	r.report("stable-coin")
+	return 1.0, s.Name(), nil
 }
@@ -0,0 +1,209 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// fixedSource is a stand-in price source that always returns the same answer
+type fixedSource struct {
+	name   string
+	price  base.Float
+	source string
+	err    error
+}
+
+func (s fixedSource) Name() string {
+	return s.name
+}
+
+func (s fixedSource) PriceUsd(conn *rpc.Connection, statement *types.Statement) (base.Float, string, error) {
+	return s.price, s.source, s.err
+}
+
+func TestPriceFromSources(t *testing.T) {
+	notApplicable := fixedSource{name: "na"}
+	failing := fixedSource{name: "failing", source: "not-priced", err: errors.New("no pair")}
+	reasoned := fixedSource{name: "reasoned", source: "token-not-priced-pre-uni"}
+	good := fixedSource{name: "good", price: 12.5, source: "good"}
+	statement := &types.Statement{}
+
+	tests := []struct {
+		sources []PriceSource
+		price   base.Float
+		source  string
+		isErr   bool
+	}{
+		{[]PriceSource{notApplicable, good}, 12.5, "good", false},
+		{[]PriceSource{failing, good}, 12.5, "good", false},
+		{[]PriceSource{reasoned, failing}, 0.0, "token-not-priced-pre-uni", false},
+		{[]PriceSource{notApplicable, failing, reasoned}, 0.0, "not-priced", true},
+		{[]PriceSource{notApplicable}, 0.0, "not-priced", false},
+		{[]PriceSource{}, 0.0, "not-priced", false},
+	}
+
+	for i, test := range tests {
+		price, source, err := priceFromSources(test.sources, nil, statement)
+		if price != test.price || source != test.source || (err != nil) != test.isErr {
+			t.Error("test", i, "got", price, source, err)
+		}
+	}
+}
+
+func TestSourcesFor(t *testing.T) {
+	sources, err := sourcesFor("")
+	if err != nil || len(sources) != len(defaultSources) {
+		t.Fatal("wrong default sources", len(sources), err)
+	}
+	for i, src := range sources {
+		if src.Name() != defaultSources[i] {
+			t.Error("wrong default source", i, src.Name())
+		}
+	}
+
+	sources, err = sourcesFor("chainlink, uniswap-v3,bogus,file")
+	if err == nil || !strings.Contains(err.Error(), "bogus") {
+		t.Error("expected an error naming the unknown source", err)
+	}
+	names := []string{}
+	for _, src := range sources {
+		names = append(names, src.Name())
+	}
+	if strings.Join(names, ",") != "chainlink,uniswap-v3,file" {
+		t.Error("wrong sources", names)
+	}
+}
+
+func TestPriceTable(t *testing.T) {
+	csvData := `asset,timestamp,price
+0x0000000000000000000000000000000000000001,2017-01-01,10
+0x0000000000000000000000000000000000000001,1500000000,20
+GNO, 2016-06-01T00:00:00 , 3.5
+`
+	records, err := readPriceCsv(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := newPriceTable(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := base.HexToAddress("0x0000000000000000000000000000000000000001")
+	jan2017 := base.Timestamp(1483228800)
+	tests := []struct {
+		addr   base.Address
+		symbol string
+		ts     base.Timestamp
+		price  base.Float
+		found  bool
+	}{
+		{addr, "", jan2017 - 1, 0, false},
+		{addr, "", jan2017, 10, true},
+		{addr, "", 1500000000 - 1, 10, true},
+		{addr, "", 1600000000, 20, true},
+		{base.ZeroAddr, "gno", jan2017, 3.5, true},
+		{base.ZeroAddr, "OTHER", jan2017, 0, false},
+	}
+	for i, test := range tests {
+		price, found := table.lookup(test.addr, test.symbol, test.ts)
+		if price != test.price || found != test.found {
+			t.Error("test", i, "got", price, found)
+		}
+	}
+
+	if _, err := readPriceCsv(strings.NewReader("asset,price\nETH,1\n")); err == nil {
+		t.Error("expected an error for a missing column")
+	}
+}
+
+// observeReturn builds the return data of observe([period, 0]) with the given tick cumulatives
+func observeReturn(tickCumulatives ...int64) []byte {
+	words := []*big.Int{big.NewInt(0x40), big.NewInt(0x40 + 0x20*int64(len(tickCumulatives)+1)), big.NewInt(int64(len(tickCumulatives)))}
+	for _, tc := range tickCumulatives {
+		words = append(words, big.NewInt(tc))
+	}
+	ret := []byte{}
+	for _, w := range words {
+		if w.Sign() < 0 {
+			w = new(big.Int).Add(w, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		ret = append(ret, w.FillBytes(make([]byte, 32))...)
+	}
+	return ret
+}
+
+func TestUniswapV3Math(t *testing.T) {
+	// 1800 seconds at tick 201000 (the USDC / WETH pool with ETH at about $1,867)
+	start := int64(-16777216)
+	tick, err := averageTick(observeReturn(start, start+201000*1800), 1800)
+	if err != nil {
+		t.Fatal(err)
+	} else if tick != 201000 {
+		t.Fatal("wrong tick", tick)
+	}
+
+	price := tickToPrice(tick, wethAddress, 18, usdcAddress, 6)
+	if math.Abs(float64(price)-1866.9) > 0.1 {
+		t.Error("wrong eth price", price)
+	}
+
+	// An average that is not a whole tick rounds towards negative infinity
+	if tick, _ = averageTick(observeReturn(start, start-1), 1800); tick != -1 {
+		t.Error("wrong rounding", tick)
+	}
+
+	if _, err = averageTick(observeReturn(start), 1800); err == nil {
+		t.Error("expected an error for a single observation")
+	}
+}
+
+func TestFxTable(t *testing.T) {
+	csvData := `date,currency,rate
+2017-01-02,EUR,0.95
+2017-01-01,eur,0.96
+2017-01-01,CHF,1.02
+`
+	table, err := readFxCsv(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jan1 := base.Timestamp(1483228800)
+	day := base.Timestamp(secondsPerDay)
+	tests := []struct {
+		currency string
+		ts       base.Timestamp
+		rate     base.Float
+		isErr    bool
+	}{
+		{"EUR", jan1 - 1, 0, true},
+		{"EUR", jan1, 0.96, false},
+		{"EUR", jan1 + day - 1, 0.96, false},
+		{"eur", jan1 + day + 100, 0.95, false},
+		{"EUR", jan1 + 8*day, 0.95, false},
+		{"EUR", jan1 + 9*day, 0, true},
+		{"CHF", jan1 + 3*day, 1.02, false},
+		{"GBP", jan1, 0, true},
+	}
+	for i, test := range tests {
+		rate, err := table.rate(test.currency, test.ts)
+		if rate != test.rate || (err != nil) != test.isErr {
+			t.Error("test", i, "got", rate, err)
+		}
+	}
+
+	if _, err := readFxCsv(strings.NewReader("date,rate\n2017-01-01,1\n")); err == nil {
+		t.Error("expected an error for a missing column")
+	}
+	if _, err := readFxCsv(strings.NewReader("date,currency,rate\n2017-01-01,EUR,0\n")); err == nil {
+		t.Error("expected an error for a zero rate")
+	}
+}
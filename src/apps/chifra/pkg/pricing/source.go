@@ -0,0 +1,73 @@
+package pricing
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// PriceSource is a source of US dollar prices for accounting statements. PriceUsd returns the
+// price of the statement's asset as of the statement's block along with the name to record in
+// the statement's PriceSource field. A source that does not apply to the asset (or to the block)
+// returns an empty source. A source that applies but fails returns a zero price, a reason (such
+// as `not-priced`), and possibly an error. In both cases, the next source is consulted.
+type PriceSource interface {
+	Name() string
+	PriceUsd(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error)
+}
+
+// defaultSources are the sources used (in this order) if a chain does not configure its own.
+var defaultSources = []string{"stable-coin", "maker", "uniswap"}
+
+var registryMutex sync.Mutex
+var registry = map[string]PriceSource{}
+
+func init() {
+	RegisterSource(stableCoinSource{})
+	RegisterSource(makerSource{})
+	RegisterSource(uniswapV2Source{})
+	RegisterSource(uniswapV3Source{})
+	RegisterSource(chainlinkSource{})
+	RegisterSource(fileSource{})
+}
+
+// RegisterSource makes a price source available by name to the per-chain `sources` setting. A
+// source registered with an existing name replaces the existing source.
+func RegisterSource(src PriceSource) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	registry[src.Name()] = src
+}
+
+// sourcesFor returns the registered price sources named in the comma separated list in the
+// order given. If the list is empty, the default sources are returned. Unknown names are
+// reported and skipped.
+func sourcesFor(names string) ([]PriceSource, error) {
+	list := defaultSources
+	if len(strings.TrimSpace(names)) > 0 {
+		list = strings.Split(names, ",")
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	ret := make([]PriceSource, 0, len(list))
+	unknown := []string{}
+	for _, name := range list {
+		name = strings.TrimSpace(name)
+		if src, ok := registry[name]; ok {
+			ret = append(ret, src)
+		} else if len(name) > 0 {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return ret, fmt.Errorf("unknown price source(s): %s", strings.Join(unknown, ", "))
+	}
+	return ret, nil
+}
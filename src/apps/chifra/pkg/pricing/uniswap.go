@@ -7,6 +7,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/articulate"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/call"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
@@ -18,6 +19,26 @@ var (
 	uniswapFactoryV2_deployed = base.Blknum(10000835) // why query for this immutable value each time we need it?
 )
 
+// uniswapV2Source prices assets using the reserves of Uniswap V2 pairs
+type uniswapV2Source struct{}
+
+func (s uniswapV2Source) Name() string {
+	return "uniswap"
+}
+
+func (s uniswapV2Source) PriceUsd(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error) {
+	if statement.BlockNumber <= uniswapFactoryV2_deployed {
+		if statement.IsEth() {
+			// Maker prices ETH prior to Uniswap V2
+			return 0.0, "", nil
+		}
+		msg := fmt.Sprintf("Block %d is prior to deployment (%d) of Uniswap V2. No other source for tokens prior to UniSwap", statement.BlockNumber, uniswapFactoryV2_deployed)
+		logger.TestLog(true, msg)
+		return 0.0, "token-not-priced-pre-uni", nil
+	}
+	return priceUsdUniswap(conn, statement)
+}
+
 // priceUsdUniswap returns the price of the given asset in USD as of the given block number.
 func priceUsdUniswap(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error) {
 	multiplier := base.Float(1.0)
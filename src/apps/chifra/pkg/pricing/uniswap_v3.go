@@ -0,0 +1,160 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+var (
+	usdcAddress               = base.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48") // USDC
+	uniswapFactoryV3          = base.HexToAddress("0x1f98431c8ad98523631ae4a59f267346ea31f984")
+	uniswapFactoryV3_deployed = base.Blknum(12369621)
+	uniswapV3FeeTiers         = []uint64{3000, 500, 10000, 100}
+	defaultTwapPeriod         = uint64(1800)
+)
+
+const (
+	v3GetPoolSelector = "0x1698ee82" // getPool(address,address,uint24)
+	v3ObserveSelector = "0x883bdbfd" // observe(uint32[])
+)
+
+// uniswapV3Source prices assets using the time-weighted average price (TWAP) reported by Uniswap
+// V3 pools. ETH is priced against USDC. Tokens are priced against WETH, then converted to USD.
+type uniswapV3Source struct{}
+
+func (s uniswapV3Source) Name() string {
+	return "uniswap-v3"
+}
+
+func (s uniswapV3Source) PriceUsd(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error) {
+	if statement.BlockNumber <= uniswapFactoryV3_deployed {
+		return 0.0, "", nil
+	}
+
+	period := config.GetPricing(conn.Chain).TwapPeriod
+	if period == 0 {
+		period = defaultTwapPeriod
+	}
+
+	ethPrice, err := twapPrice(conn, wethAddress, 18, usdcAddress, 6, statement.BlockNumber, period)
+	if err != nil {
+		return 0.0, "not-priced", err
+	}
+	price = ethPrice
+
+	if !statement.IsEth() && statement.AssetAddr != wethAddress {
+		decimals := uint64(statement.Decimals)
+		if decimals == 0 {
+			decimals = 18
+		}
+		tokenPrice, err := twapPrice(conn, statement.AssetAddr, decimals, wethAddress, 18, statement.BlockNumber, period)
+		if err != nil {
+			return 0.0, "not-priced", err
+		}
+		price = tokenPrice * ethPrice
+	}
+
+	msg := fmt.Sprintf("Uniswap V3 TWAP (%d seconds) for %s at block %d: %f", period, statement.AssetAddr.Hex(), statement.BlockNumber, price)
+	logger.TestLog(true, msg)
+
+	return price, s.Name(), nil
+}
+
+// twapPrice returns the time-weighted average price of the asset in units of the quote token
+// as of the given block using the first fee tier that has a pool with enough history.
+func twapPrice(conn *rpc.Connection, asset base.Address, assetDecimals uint64, quote base.Address, quoteDecimals uint64, bn base.Blknum, period uint64) (base.Float, error) {
+	var lastErr error = fmt.Errorf("no uniswap v3 pool for %s and %s", asset.Hex(), quote.Hex())
+	for _, fee := range uniswapV3FeeTiers {
+		data := v3GetPoolSelector + encodeAddress(asset) + encodeAddress(quote) + encodeUint(fee)
+		ret, err := ethCall(conn, uniswapFactoryV3, data, bn)
+		if err != nil {
+			return 0.0, err
+		}
+		pool, err := addressAt(ret, 0)
+		if err != nil {
+			return 0.0, err
+		} else if pool.IsZero() {
+			continue
+		}
+
+		// observe([period, 0]) returns the tick accumulators for both points in time
+		data = v3ObserveSelector + encodeUint(0x20) + encodeUint(2) + encodeUint(period) + encodeUint(0)
+		ret, err = ethCall(conn, pool, data, bn)
+		if err != nil {
+			// most likely the pool does not have enough history for the period
+			lastErr = err
+			continue
+		}
+
+		tick, err := averageTick(ret, period)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		return tickToPrice(tick, asset, assetDecimals, quote, quoteDecimals), nil
+	}
+
+	return 0.0, lastErr
+}
+
+// averageTick decodes the return of observe([period, 0]) and returns the average tick over the period
+func averageTick(data []byte, period uint64) (int64, error) {
+	offset, err := wordAt(data, 0)
+	if err != nil {
+		return 0, err
+	}
+	start := int(offset.Uint64() / 32)
+	if n, err := wordAt(data, start); err != nil {
+		return 0, err
+	} else if n.Uint64() != 2 {
+		return 0, fmt.Errorf("expected two tick cumulatives, got %d", n.Uint64())
+	}
+
+	tickCumulative0, err := signedWordAt(data, start+1)
+	if err != nil {
+		return 0, err
+	}
+	tickCumulative1, err := signedWordAt(data, start+2)
+	if err != nil {
+		return 0, err
+	}
+
+	if period == 0 {
+		return 0, errors.New("twap period may not be zero")
+	}
+
+	// Euclidean division rounds towards negative infinity (for a positive period) as does Uniswap's OracleLibrary
+	delta := new(big.Int).Sub(tickCumulative1, tickCumulative0)
+	tick := new(big.Int)
+	tick.DivMod(delta, new(big.Int).SetUint64(period), new(big.Int))
+	return tick.Int64(), nil
+}
+
+// tickToPrice converts a tick to the price of the asset in units of the quote token. Uniswap
+// V3 quotes the price of token0 (the lower address) in units of token1.
+func tickToPrice(tick int64, asset base.Address, assetDecimals uint64, quote base.Address, quoteDecimals uint64) base.Float {
+	var dec0, dec1 uint64
+	assetIsToken0 := asset.Hex() < quote.Hex()
+	if assetIsToken0 {
+		dec0, dec1 = assetDecimals, quoteDecimals
+	} else {
+		dec0, dec1 = quoteDecimals, assetDecimals
+	}
+
+	price0In1 := math.Pow(1.0001, float64(tick)) * math.Pow(10, float64(dec0)-float64(dec1))
+	if assetIsToken0 {
+		return base.Float(price0In1)
+	} else if price0In1 == 0 {
+		return 0.0
+	}
+	return base.Float(1 / price0In1)
+}
@@ -0,0 +1,30 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package progress
+
+import "context"
+
+// Reporter receives the progress of a command and the errors it reports while it streams its
+// output (for example, a server job running one of the server's routes)
+type Reporter interface {
+	ReportProgress(msg string)
+	ReportError(err error)
+}
+
+type reporterKey struct{}
+
+// WithReporter returns a copy of ctx carrying the reporter
+func WithReporter(ctx context.Context, reporter Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, reporter)
+}
+
+// ReporterFrom returns the reporter carried by ctx or nil if there is none
+func ReporterFrom(ctx context.Context) Reporter {
+	if ctx == nil {
+		return nil
+	}
+	reporter, _ := ctx.Value(reporterKey{}).(Reporter)
+	return reporter
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
 	"github.com/ethereum/go-ethereum/ethclient"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
 )
 
 // GetClientVersion returns the version of the client
@@ -93,7 +94,14 @@ func (conn *Connection) getClient() (*ethclient.Client, error) {
 	defer clientMutex.Unlock()
 
 	if perProviderClientMap[provider] == nil {
-		ec, err := ethclient.Dial(provider)
+		// Share the query package's http client so that the provider pool and recording and
+		// replaying cover these calls too
+		query.RegisterChain(conn.Chain)
+		rc, err := gethrpc.DialOptions(context.Background(), provider, gethrpc.WithHTTPClient(query.HttpClient()))
+		var ec *ethclient.Client
+		if err == nil {
+			ec = ethclient.NewClient(rc)
+		}
 		if err != nil || ec == nil {
 			logger.Error("Missdial("+provider+"):", err)
 			logger.Fatal("")
@@ -0,0 +1,33 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package rpc
+
+import (
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// setCodeTxType is the type of an EIP-7702 (set code) transaction, which carries an authorization list
+const setCodeTxType = "0x4"
+
+// MayHaveAuthorizations returns false if the block is known to precede Prague (and so cannot hold
+// EIP-7702 authorization lists). On chains for which we do not know the Prague block, every block
+// may hold them, so their transactions must be checked.
+func (conn *Connection) MayHaveAuthorizations(bn base.Blknum) bool {
+	prague := base.KnownBlock(conn.Chain, base.Prague)
+	return prague == 0 || bn >= prague
+}
+
+// GetAuthorizations returns the transactions in a block (fetched with its transactions) that carry
+// an EIP-7702 authorization list. The authorities and their delegates do not appear in the traces.
+func GetAuthorizations(block *types.Block) []types.Transaction {
+	ret := make([]types.Transaction, 0)
+	for _, tx := range block.Transactions {
+		if tx.TransactionType == setCodeTxType || len(tx.AuthorizationList) > 0 {
+			ret = append(ret, tx)
+		}
+	}
+	return ret
+}
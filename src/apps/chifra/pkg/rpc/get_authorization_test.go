@@ -0,0 +1,33 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestGetAuthorizations(t *testing.T) {
+	mainnet := &Connection{Chain: "mainnet"}
+	if mainnet.MayHaveAuthorizations(base.KnownBlock("mainnet", base.Prague) - 1) {
+		t.Error("a block before Prague cannot hold authorizations")
+	}
+	if !mainnet.MayHaveAuthorizations(base.KnownBlock("mainnet", base.Prague)) {
+		t.Error("a block after Prague may hold authorizations")
+	}
+	// the Prague block of this chain is not known, so every block is checked
+	if !(&Connection{Chain: "gnosis"}).MayHaveAuthorizations(1) {
+		t.Error("every block of a chain whose Prague block is unknown may hold authorizations")
+	}
+
+	block := &types.Block{
+		Transactions: []types.Transaction{
+			{TransactionIndex: 0, TransactionType: "0x2"},
+			{TransactionIndex: 1, TransactionType: "0x4", AuthorizationList: []types.Authorization{{Address: base.HexToAddress("0x1234")}}},
+			{TransactionIndex: 2},
+		},
+	}
+	if auths := GetAuthorizations(block); len(auths) != 1 || auths[0].TransactionIndex != 1 {
+		t.Errorf("expected the set code transaction, got %v", auths)
+	}
+}
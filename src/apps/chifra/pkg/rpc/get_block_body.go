@@ -39,8 +39,10 @@ func (conn *Connection) GetBlockBodyByNumber(bn base.Blknum) (types.Block, error
 				lightToBody := func(block *types.LightBlock) *types.Block {
 					var ret types.Block
 					ret.BaseFeePerGas = block.BaseFeePerGas
+					ret.BlobGasUsed = block.BlobGasUsed
 					ret.BlockNumber = block.BlockNumber
 					ret.Difficulty = block.Difficulty
+					ret.ExcessBlobGas = block.ExcessBlobGas
 					ret.GasLimit = block.GasLimit
 					ret.GasUsed = block.GasUsed
 					ret.Hash = block.Hash
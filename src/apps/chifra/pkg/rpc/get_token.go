@@ -26,6 +26,8 @@ const tokenStateDecimals tokenStateSelector = "0x313ce567"
 const tokenStateSymbol tokenStateSelector = "0x95d89b41"
 const tokenStateName tokenStateSelector = "0x06fdde03"
 const tokenStateBalanceOf tokenStateSelector = "0x70a08231"
+const tokenStateOwnerOf tokenStateSelector = "0x6352211e"
+const tokenStateBalanceOfId tokenStateSelector = "0x00fdd58e"
 
 // GetTokenState returns token state for given block. `hexBlockNo` can be "latest" or "" for the latest
 // block or decimal number or hex number with 0x prefix. (search: FromRpc)
@@ -176,3 +178,47 @@ func (conn *Connection) GetBalanceAtToken(token, holder base.Address, hexBlockNo
 
 	return base.HexToWei(*output["balance"]), nil
 }
+
+// GetBalanceAtNft returns the holder's balance of a single non-fungible token at the given block. For
+// an ERC-721 token, the balance is one if the holder owns the token and zero otherwise (including if the
+// token does not exist). For an ERC-1155 token, it is the holder's balance of the given token id.
+// `hexBlockNo` is as for GetBalanceAtToken. A failed call is returned as an error, not a zero balance.
+func (conn *Connection) GetBalanceAtNft(token, holder base.Address, tokenId *base.Wei, isErc1155 bool, hexBlockNo string) (*base.Wei, error) {
+	if hexBlockNo != "" && hexBlockNo != "latest" && !strings.HasPrefix(hexBlockNo, "0x") {
+		hexBlockNo = fmt.Sprintf("0x%x", base.MustParseUint64(hexBlockNo))
+	}
+
+	data := tokenStateOwnerOf + fmt.Sprintf("%064x", tokenId.BigInt())
+	if isErc1155 {
+		data = tokenStateBalanceOfId + holder.Pad32() + fmt.Sprintf("%064x", tokenId.BigInt())
+	}
+
+	params := query.Params{
+		map[string]any{
+			"to":   token.Hex(),
+			"data": data,
+		},
+		hexBlockNo,
+	}
+
+	ret, err := query.Query[string](conn.Chain, "eth_call", params)
+	if err != nil {
+		if !isErc1155 && strings.Contains(err.Error(), "execution reverted") {
+			// ownerOf reverts for tokens that do not (yet or any longer) exist
+			return base.NewWei(0), nil
+		}
+		return nil, err
+	}
+
+	if isErc1155 {
+		return base.HexToWei(*ret), nil
+	} else if len(*ret) < 42 {
+		return base.NewWei(0), nil
+	}
+
+	owner := base.HexToAddress("0x" + (*ret)[len(*ret)-40:])
+	if owner == holder {
+		return base.NewWei(1), nil
+	}
+	return base.NewWei(0), nil
+}
@@ -1,6 +1,3 @@
-//go:build integration
-// +build integration
-
 // Copyright 2023 The TrueBlocks Authors. All rights reserved.
 // Use of this source code is governed by a license that can
 // be found in the LICENSE file.
@@ -26,6 +23,7 @@ var nonStandard2 = base.HexToAddress("0x461733c17b0755ca5649b6db08b3e213fcf22546
 
 func TestGetState_Erc20(t *testing.T) {
 	blockNumber := "0xd59f80" // 14000000
+	useFixtures(t)
 	chain := utils.GetTestChain()
 	conn := TempConnection(chain)
 
@@ -62,6 +60,7 @@ func TestGetState_Erc20(t *testing.T) {
 
 func TestGetState_Erc721(t *testing.T) {
 	blockNumber := "0xd59f80" // 14000000
+	useFixtures(t)
 	chain := utils.GetTestChain()
 	conn := TempConnection(chain)
 
@@ -98,6 +97,7 @@ func TestGetState_Erc721(t *testing.T) {
 
 func TestGetState_NonStandard(t *testing.T) {
 	blockNumber := "17000000"
+	useFixtures(t)
 	chain := utils.GetTestChain()
 	conn := TempConnection(chain)
 
@@ -0,0 +1,262 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package rpc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// Nodes such as Geth do not expose the Parity-style trace_ namespace. For those nodes, we ask for
+// the output of the built-in callTracer (through the debug_ namespace) and convert each tree of call
+// frames into the flat list of traces (with traceAddress giving each trace's place in the tree) that
+// trace_block would have returned. The callTracer does not report block rewards, so we add those
+// traces from the block's miner and uncles.
+
+// callFrame is a single frame of the output of the callTracer
+type callFrame struct {
+	Type    string       `json:"type"`
+	From    base.Address `json:"from"`
+	To      base.Address `json:"to"`
+	Value   base.Wei     `json:"value"`
+	Gas     base.Gas     `json:"gas"`
+	GasUsed base.Gas     `json:"gasUsed"`
+	Input   string       `json:"input"`
+	Output  string       `json:"output"`
+	Error   string       `json:"error"`
+	Calls   []callFrame  `json:"calls"`
+}
+
+// callTracerResult is a single item in the output of debug_traceBlockByNumber
+type callTracerResult struct {
+	TxHash base.Hash  `json:"txHash"`
+	Result *callFrame `json:"result"`
+	Error  string     `json:"error"`
+}
+
+var callTracerConfig = map[string]any{"tracer": "callTracer"}
+
+var callTracerMutex sync.Mutex
+var callTracerChains = map[string]bool{}
+
+// usesCallTracer returns true if we've learned that the chain's node requires the callTracer
+func (conn *Connection) usesCallTracer() bool {
+	callTracerMutex.Lock()
+	defer callTracerMutex.Unlock()
+	return callTracerChains[conn.Chain]
+}
+
+func (conn *Connection) setCallTracer() {
+	callTracerMutex.Lock()
+	defer callTracerMutex.Unlock()
+	if !callTracerChains[conn.Chain] {
+		logger.Info("The node does not support trace_ methods. Using debug_ methods with the callTracer.")
+	}
+	callTracerChains[conn.Chain] = true
+}
+
+// isMethodNotFound returns true if the node reported that it does not support the method. Errors
+// reported by the node start with their JSON-RPC code (see package query).
+func isMethodNotFound(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "-32601:")
+}
+
+// traceBlock returns the traces in a block using trace_block or, if the node does not support it,
+// the callTracer
+func (conn *Connection) traceBlock(bn base.Blknum) (*[]types.Trace, error) {
+	if !conn.usesCallTracer() {
+		method := "trace_block"
+		params := query.Params{fmt.Sprintf("0x%x", bn)}
+		traces, err := query.Query[[]types.Trace](conn.Chain, method, params)
+		if !isMethodNotFound(err) {
+			return traces, err
+		}
+		conn.setCallTracer()
+	}
+
+	header, err := conn.GetBlockHeaderByNumber(bn)
+	if err != nil {
+		return nil, err
+	}
+
+	method := "debug_traceBlockByNumber"
+	params := query.Params{fmt.Sprintf("0x%x", bn), callTracerConfig}
+	results, err := query.Query[[]callTracerResult](conn.Chain, method, params)
+	if err != nil {
+		return nil, err
+	} else if results == nil {
+		return nil, nil
+	}
+
+	traces := []types.Trace{}
+	for i, res := range *results {
+		if res.Result == nil {
+			return nil, fmt.Errorf("callTracer failed for transaction %d in block %d: %s", i, bn, res.Error)
+		}
+		txHash := res.TxHash
+		if txHash.IsZero() && i < len(header.Transactions) {
+			txHash = base.HexToHash(header.Transactions[i])
+		}
+		template := types.Trace{
+			BlockHash:           header.Hash,
+			BlockNumber:         bn,
+			TransactionHash:     txHash,
+			TransactionPosition: base.Txnum(i),
+		}
+		traces = append(traces, flattenCallFrame(res.Result, template)...)
+	}
+
+	rewards, err := conn.getRewardTraces(&header)
+	if err != nil {
+		return nil, err
+	}
+	traces = append(traces, rewards...)
+	return &traces, nil
+}
+
+// getRewardTraces returns the reward traces trace_block reports for a block: one for the block's
+// miner (whose reward includes 1/32 of the block reward for each uncle) and one for the miner of
+// each uncle (who receives between 7/8 and 2/8 of the block reward, depending on the uncle's age).
+func (conn *Connection) getRewardTraces(header *types.LightBlock) ([]types.Trace, error) {
+	blockReward := conn.getBlockReward(header.BlockNumber)
+	if blockReward.IsZero() {
+		return []types.Trace{}, nil
+	}
+
+	uncles, err := conn.GetUncleBodiesByNumber(header.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	reward := func(author base.Address, rewardType string, value *base.Wei) types.Trace {
+		return types.Trace{
+			BlockHash:    header.Hash,
+			BlockNumber:  header.BlockNumber,
+			TraceAddress: []uint64{},
+			TraceType:    "reward",
+			Action: &types.TraceAction{
+				Author:     author,
+				RewardType: rewardType,
+				Value:      *value,
+			},
+		}
+	}
+
+	minerReward := new(base.Wei).Mul(blockReward, base.NewWei(int64(32+len(uncles))))
+	minerReward.Div(minerReward, base.NewWei(32))
+	traces := []types.Trace{reward(header.Miner, "block", minerReward)}
+	for _, uncle := range uncles {
+		if uncle.BlockNumber+8 <= header.BlockNumber {
+			continue
+		}
+		uncleReward := new(base.Wei).Mul(blockReward, base.NewWei(int64(uncle.BlockNumber+8-header.BlockNumber)))
+		uncleReward.Div(uncleReward, base.NewWei(8))
+		traces = append(traces, reward(uncle.Miner, "uncle", uncleReward))
+	}
+	return traces, nil
+}
+
+// traceTransaction returns the traces in a transaction using trace_transaction or, if the node does
+// not support it, the callTracer
+func (conn *Connection) traceTransaction(txHash string, transaction *types.Transaction) (*[]types.Trace, error) {
+	if !conn.usesCallTracer() {
+		method := "trace_transaction"
+		params := query.Params{txHash}
+		traces, err := query.Query[[]types.Trace](conn.Chain, method, params)
+		if !isMethodNotFound(err) {
+			return traces, err
+		}
+		conn.setCallTracer()
+	}
+
+	if transaction == nil {
+		var err error
+		if transaction, err = conn.getTransactionFromRpc(notAHash, base.HexToHash(txHash), base.NOPOSN, base.NOPOSN); err != nil {
+			return nil, err
+		}
+	}
+
+	method := "debug_traceTransaction"
+	params := query.Params{txHash, callTracerConfig}
+	frame, err := query.Query[callFrame](conn.Chain, method, params)
+	if err != nil || frame == nil {
+		return nil, err
+	}
+
+	template := types.Trace{
+		BlockHash:           transaction.BlockHash,
+		BlockNumber:         transaction.BlockNumber,
+		TransactionHash:     base.HexToHash(txHash),
+		TransactionPosition: transaction.TransactionIndex,
+	}
+	traces := flattenCallFrame(frame, template)
+	return &traces, nil
+}
+
+// flattenCallFrame converts a tree of call frames into a list of traces in depth-first order. Each
+// trace is a copy of template (which carries the block and transaction fields) with its action,
+// result, subtraces, and traceAddress filled in as trace_transaction would have.
+func flattenCallFrame(root *callFrame, template types.Trace) []types.Trace {
+	traces := []types.Trace{}
+	var walk func(frame *callFrame, address []uint64)
+	walk = func(frame *callFrame, address []uint64) {
+		trace := template
+		trace.TraceAddress = address
+		trace.Subtraces = uint64(len(frame.Calls))
+		trace.Action = &types.TraceAction{}
+		trace.Result = &types.TraceResult{}
+		trace.Error = frame.Error
+		if frame.Error == "execution reverted" {
+			// The Parity name for the same thing
+			trace.Error = "Reverted"
+		}
+
+		switch callType := strings.ToLower(frame.Type); callType {
+		case "create", "create2":
+			trace.TraceType = "create"
+			trace.Action.From = frame.From
+			trace.Action.Value = frame.Value
+			trace.Action.Gas = frame.Gas
+			trace.Action.Init = frame.Input
+			if len(frame.Error) == 0 {
+				trace.Result.Address = frame.To
+				trace.Result.Code = frame.Output
+				trace.Result.GasUsed = frame.GasUsed
+			}
+		case "selfdestruct":
+			trace.TraceType = "suicide"
+			trace.Action.Address = frame.From
+			trace.Action.RefundAddress = frame.To
+			trace.Action.Balance = frame.Value
+		default:
+			trace.TraceType = "call"
+			trace.Action.CallType = callType
+			trace.Action.From = frame.From
+			trace.Action.To = frame.To
+			trace.Action.Value = frame.Value
+			trace.Action.Gas = frame.Gas
+			trace.Action.Input = frame.Input
+			if len(frame.Error) == 0 {
+				trace.Result.GasUsed = frame.GasUsed
+				trace.Result.Output = frame.Output
+			}
+		}
+		traces = append(traces, trace)
+
+		for i := range frame.Calls {
+			child := make([]uint64, len(address), len(address)+1)
+			copy(child, address)
+			walk(&frame.Calls[i], append(child, uint64(i)))
+		}
+	}
+	walk(root, []uint64{})
+	return traces
+}
@@ -0,0 +1,187 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
+)
+
+// A transaction that calls a contract which makes a (reverted) static call and then creates a contract
+// which self-destructs
+const callTracerOutput = `{
+	"type": "CALL",
+	"from": "0x00000000000000000000000000000000000000aa",
+	"to": "0x00000000000000000000000000000000000000bb",
+	"value": "0xde0b6b3a7640000",
+	"gas": "0x5208",
+	"gasUsed": "0x5000",
+	"input": "0x12345678",
+	"output": "0x",
+	"calls": [
+		{
+			"type": "STATICCALL",
+			"from": "0x00000000000000000000000000000000000000bb",
+			"to": "0x00000000000000000000000000000000000000cc",
+			"gas": "0x100",
+			"gasUsed": "0x100",
+			"input": "0x",
+			"error": "execution reverted"
+		},
+		{
+			"type": "CREATE2",
+			"from": "0x00000000000000000000000000000000000000bb",
+			"to": "0x00000000000000000000000000000000000000dd",
+			"value": "0x0",
+			"gas": "0x200",
+			"gasUsed": "0x150",
+			"input": "0x6080",
+			"output": "0x6000",
+			"calls": [
+				{
+					"type": "SELFDESTRUCT",
+					"from": "0x00000000000000000000000000000000000000dd",
+					"to": "0x00000000000000000000000000000000000000aa",
+					"value": "0x1"
+				}
+			]
+		}
+	]
+}`
+
+func TestFlattenCallFrame(t *testing.T) {
+	var frame callFrame
+	if err := json.Unmarshal([]byte(callTracerOutput), &frame); err != nil {
+		t.Fatal(err)
+	}
+
+	template := types.Trace{BlockNumber: 100, TransactionPosition: 3}
+	traces := flattenCallFrame(&frame, template)
+	if len(traces) != 4 {
+		t.Fatal("wrong number of traces", len(traces))
+	}
+
+	expected := []struct {
+		traceType    string
+		callType     string
+		traceAddress string
+		subtraces    uint64
+		err          string
+	}{
+		{"call", "call", "[]", 2, ""},
+		{"call", "staticcall", "[0]", 0, "Reverted"},
+		{"create", "", "[1]", 1, ""},
+		{"suicide", "", "[1 0]", 0, ""},
+	}
+	for i, e := range expected {
+		tr := traces[i]
+		if tr.TraceType != e.traceType || tr.Action.CallType != e.callType || fmt.Sprint(tr.TraceAddress) != e.traceAddress ||
+			tr.Subtraces != e.subtraces || tr.Error != e.err || tr.BlockNumber != 100 || tr.TransactionPosition != 3 {
+			t.Error("wrong trace", i, tr.TraceType, tr.Action.CallType, tr.TraceAddress, tr.Subtraces, tr.Error)
+		}
+	}
+
+	if traces[0].Action.Value.Text(10) != "1000000000000000000" || traces[0].Result.GasUsed != 0x5000 || traces[0].Action.Input != "0x12345678" {
+		t.Error("wrong call", traces[0].Action, traces[0].Result)
+	}
+	if traces[1].Result.GasUsed != 0 {
+		t.Error("a reverted trace should have an empty result", traces[1].Result)
+	}
+	if traces[2].Result.Address != base.HexToAddress("0xdd") || traces[2].Result.Code != "0x6000" || traces[2].Action.Init != "0x6080" {
+		t.Error("wrong create", traces[2].Action, traces[2].Result)
+	}
+	if traces[3].Action.Address != base.HexToAddress("0xdd") || traces[3].Action.RefundAddress != base.HexToAddress("0xaa") || traces[3].Action.Balance.Text(10) != "1" {
+		t.Error("wrong self-destruct", traces[3].Action)
+	}
+}
+
+func TestIsMethodNotFound(t *testing.T) {
+	tests := map[string]bool{
+		"-32601: the method trace_block does not exist/is not available": true,
+		"-32601: Method not found":                                       true,
+		"-32000: header not found":                                       false,
+		"405 Method Not Allowed: 405":                                    false,
+		"trace_block is not supported":                                   false,
+		"-32000: execution not supported for this block":                 false,
+	}
+	for msg, expected := range tests {
+		if got := isMethodNotFound(errors.New(msg)); got != expected {
+			t.Error(msg, "got", got, "expected", expected)
+		}
+	}
+	if isMethodNotFound(nil) {
+		t.Error("nil is not an error")
+	}
+}
+
+// uncleNode answers the requests for a block's uncles as a node would for a block with one uncle
+type uncleNode struct{}
+
+func (uncleNode) RoundTrip(req *http.Request) (*http.Response, error) {
+	var request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	body, _ := io.ReadAll(req.Body)
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, err
+	}
+
+	response := map[string]any{"jsonrpc": "2.0", "id": request.ID}
+	switch request.Method {
+	case "eth_getUncleCountByBlockNumber":
+		response["result"] = "0x1"
+	case "eth_getUncleByBlockNumberAndIndex":
+		response["result"] = map[string]any{"number": "0x3d08fe", "miner": "0x00000000000000000000000000000000000000cc"}
+	default:
+		response["error"] = map[string]any{"code": -32601, "message": "method not found"}
+	}
+	out, _ := json.Marshal(response)
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(out)), Request: req}, nil
+}
+
+func TestGetRewardTraces(t *testing.T) {
+	query.SetTransport(uncleNode{})
+	defer query.SetTransport(nil)
+
+	conn := TempConnection(utils.GetTestChain())
+	header := types.LightBlock{BlockNumber: 4000000, Miner: base.HexToAddress("0xbb")}
+	traces, err := conn.getRewardTraces(&header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 2 {
+		t.Fatal("wrong number of reward traces", len(traces))
+	}
+
+	// Before Byzantium, the block reward is five ether. The miner earns 1/32 of that for including
+	// the uncle, whose miner earns 6/8 of it as the uncle is two blocks old.
+	expected := []struct {
+		author     string
+		rewardType string
+		value      string
+	}{
+		{"0x00000000000000000000000000000000000000bb", "block", "5156250000000000000"},
+		{"0x00000000000000000000000000000000000000cc", "uncle", "3750000000000000000"},
+	}
+	for i, e := range expected {
+		tr := traces[i]
+		if tr.TraceType != "reward" || tr.Action.Author.Hex() != e.author || tr.Action.RewardType != e.rewardType || tr.Action.Value.Text(10) != e.value {
+			t.Error("wrong reward", i, tr.TraceType, tr.Action.Author, tr.Action.RewardType, tr.Action.Value.Text(10))
+		}
+	}
+
+	// After the merge, there are no rewards
+	header.BlockNumber = 20000000
+	if traces, err = conn.getRewardTraces(&header); err != nil || len(traces) != 0 {
+		t.Error("expected no rewards after the merge", traces, err)
+	}
+}
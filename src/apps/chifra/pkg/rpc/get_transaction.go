@@ -63,7 +63,10 @@ func (conn *Connection) GetTransactionByAppearance(app *types.Appearance, fetchT
 	bn := base.Blknum(theApp.BlockNumber)
 	txid := base.Txnum(theApp.TransactionIndex)
 
-	if conn.StoreReadable() {
+	// Withdrawals are not cached. The cache is keyed by block and txid only, but each address that
+	// receives a withdrawal in a block gets its own pseudo-transaction. This also ignores entries
+	// written by earlier versions, which carried a zero value.
+	if conn.StoreReadable() && txid != types.WithdrawalAmt {
 		// walk.Cache_Transactions
 		tx := &types.Transaction{
 			BlockNumber:      bn,
@@ -121,10 +124,6 @@ func (conn *Connection) GetTransactionByAppearance(app *types.Appearance, fetchT
 			return nil, err
 		} else {
 			tx.Timestamp = blockTs
-			isFinal := base.IsFinal(conn.LatestBlockTimestamp, blockTs)
-			if isFinal && conn.StoreWritable() && conn.EnabledMap[walk.Cache_Transactions] {
-				_ = conn.Store.Write(tx, nil)
-			}
 			return tx, nil
 		}
 	}
@@ -241,12 +240,23 @@ func (conn *Connection) GetTransactionRewardByTypeAndApp(rt base.Txnum, theApp *
 		return nil, err
 	} else {
 		if rt == types.WithdrawalAmt {
+			// An address may receive more than one withdrawal (partial or full) in a block. The
+			// amounts are reported in gwei.
+			total := base.NewWei(0)
+			for _, withdrawal := range block.Withdrawals {
+				if withdrawal.Address == theApp.Address {
+					total = total.Add(total, &withdrawal.Amount)
+				}
+			}
+			total = total.Mul(total, base.NewWei(1000000000))
 			tx := &types.Transaction{
 				BlockNumber:      base.Blknum(theApp.BlockNumber),
 				TransactionIndex: base.Txnum(theApp.TransactionIndex),
+				BlockHash:        block.Hash,
 				Timestamp:        block.Timestamp,
 				From:             base.WithdrawalSender,
 				To:               theApp.Address,
+				Value:            *total,
 			}
 			return tx, nil
 		}
@@ -40,9 +40,10 @@ func (conn *Connection) IsNodeArchive() bool {
 	return bal.Cmp(&largest.Balance) == 0
 }
 
-// IsNodeTracing returns true if the node exposes the `block_trace` RPC endpoint.
-// It queries block 1 or a user supplied block (which we presume exists). The function
-// returns false if block_trace returns an error or doesn't exist.
+// IsNodeTracing returns true if the node exposes the `trace_block` RPC endpoint or, failing
+// that, `debug_traceBlockByNumber` with the callTracer. It queries block 1 or a user supplied
+// block (which we presume exists). The function returns false if both return an error or
+// don't exist.
 func (conn *Connection) IsNodeTracing() (error, bool) {
 	firstTrace := base.Max(1, base.KnownBlock(conn.Chain, base.FirstTrace))
 	varName := "TB_" + strings.ToUpper(conn.Chain) + "_FIRSTTRACE"
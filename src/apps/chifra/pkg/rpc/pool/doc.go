@@ -0,0 +1,9 @@
+// Package pool spreads a chain's RPC requests across one or more providers.
+//
+// Each chain's pool is built from the rpcProviders list in its configuration (or from rpcProvider alone
+// if there is no list). Requests are sent to a healthy endpoint chosen at random in proportion to its
+// weight and its recent success rate, no faster than the endpoint's maxRps. A request that fails with
+// a 429 or a 5xx status is retried with exponential backoff on another endpoint (if there is one). A
+// network error is retried only if there is another endpoint to try. An endpoint that fails repeatedly
+// is ejected from the pool for a while and then given another chance.
+package pool
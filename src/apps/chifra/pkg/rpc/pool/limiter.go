@@ -0,0 +1,74 @@
+package pool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket allowing rate requests per second with bursts of up to one second's worth
+type limiter struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+	mutex  sync.Mutex
+	now    func() time.Time
+}
+
+func newLimiter(rate float64) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	burst := rate
+	if burst < 1 {
+		burst = 1
+	}
+	return &limiter{rate: rate, tokens: burst, now: time.Now}
+}
+
+// reserve takes a token and returns how long the caller must wait before using it
+func (l *limiter) reserve() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		burst := l.rate
+		if burst < 1 {
+			burst = 1
+		}
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// wait blocks until the caller may send a request or the context is done. A nil limiter never waits.
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	return sleep(ctx, l.reserve())
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
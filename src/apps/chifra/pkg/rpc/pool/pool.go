@@ -0,0 +1,323 @@
+package pool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+)
+
+const (
+	// alpha is the weight of the latest result in an endpoint's health score
+	alpha = 0.2
+	// minScore keeps an endpoint with a poor (but not ejected) record in the rotation
+	minScore = 0.05
+)
+
+// endpoint is a single member of a pool. Its mutable fields are guarded by the pool's mutex.
+type endpoint struct {
+	url          *url.URL
+	weight       float64
+	limiter      *limiter
+	failures     uint64
+	ejectedUntil time.Time
+	score        float64
+}
+
+// Status reports the health of one endpoint in a pool
+type Status struct {
+	Url      string
+	Healthy  bool
+	Score    float64
+	Failures uint64
+}
+
+// Pool is an http.RoundTripper that sends each request to one of several equivalent endpoints
+type Pool struct {
+	endpoints []*endpoint
+	policy    configtypes.RpcPolicy
+	next      http.RoundTripper
+	mutex     sync.Mutex
+	rng       *rand.Rand
+	now       func() time.Time
+	sleep     func(context.Context, time.Duration) error
+}
+
+// New returns a pool of the given endpoints which sends its requests with next (or with
+// http.DefaultTransport if next is nil)
+func New(endpoints []configtypes.RpcEndpoint, policy configtypes.RpcPolicy, next http.RoundTripper) (*Pool, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("a pool requires at least one endpoint")
+	}
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	p := &Pool{
+		policy: withDefaults(policy),
+		next:   next,
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:    time.Now,
+		sleep:  sleep,
+	}
+
+	for _, e := range endpoints {
+		u, err := url.Parse(e.Url)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rpc endpoint %s: %w", e.Url, err)
+		}
+		weight := float64(e.Weight)
+		if weight == 0 {
+			weight = 1
+		}
+		p.endpoints = append(p.endpoints, &endpoint{
+			url:     u,
+			weight:  weight,
+			limiter: newLimiter(e.MaxRps),
+			score:   1.0,
+		})
+	}
+
+	return p, nil
+}
+
+func withDefaults(policy configtypes.RpcPolicy) configtypes.RpcPolicy {
+	if policy.MaxRetries == 0 {
+		policy.MaxRetries = 3
+	}
+	if policy.BackoffMs == 0 {
+		policy.BackoffMs = 250
+	}
+	if policy.MaxBackoffMs == 0 {
+		policy.MaxBackoffMs = 8000
+	}
+	if policy.EjectAfter == 0 {
+		policy.EjectAfter = 3
+	}
+	if policy.EjectSecs == 0 {
+		policy.EjectSecs = 60
+	}
+	return policy
+}
+
+// RoundTrip implements http.RoundTripper. The request is sent to the chosen endpoint regardless of
+// its own URL. Network errors are retried only if the pool has more than one endpoint.
+func (p *Pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	ctx := req.Context()
+	var lastErr error
+	var last *endpoint
+	var retryAfter time.Duration
+	attempts := p.policy.MaxRetries + 1
+	for attempt := uint64(0); attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := p.sleep(ctx, p.backoff(attempt, retryAfter)); err != nil {
+				return nil, err
+			}
+		}
+
+		ep := p.choose(last)
+		last = ep
+		if err := ep.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		out := req.Clone(ctx)
+		out.URL = cloneUrl(ep.url)
+		out.Host = ""
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.ContentLength = int64(len(body))
+
+		resp, err := p.next.RoundTrip(out)
+		if err == nil && !retriable(resp.StatusCode) {
+			p.succeeded(ep)
+			return resp, nil
+		}
+
+		if err != nil && len(p.endpoints) == 1 {
+			// There is nowhere else to go, so report an unreachable node immediately
+			return nil, err
+		}
+
+		p.failed(ep)
+		if err != nil {
+			lastErr = err
+			retryAfter = 0
+		} else {
+			// The caller sees the last failed response if there are no retries left
+			if attempt == attempts-1 {
+				return resp, nil
+			}
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			lastErr = fmt.Errorf("%s: %d", resp.Status, resp.StatusCode)
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, lastErr
+}
+
+// Status returns the health of each endpoint in the pool
+func (p *Pool) Status() []Status {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	ret := make([]Status, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		ret = append(ret, Status{
+			Url:      ep.url.String(),
+			Healthy:  !now.Before(ep.ejectedUntil),
+			Score:    ep.score,
+			Failures: ep.failures,
+		})
+	}
+	return ret
+}
+
+// choose picks a healthy endpoint at random in proportion to its weight and health score, avoiding
+// the endpoint that just failed if there is an alternative. If every endpoint is ejected, it picks
+// the one due back soonest.
+func (p *Pool) choose(avoid *endpoint) *endpoint {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	healthy := make([]*endpoint, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		if !now.Before(ep.ejectedUntil) {
+			healthy = append(healthy, ep)
+		}
+	}
+
+	if len(healthy) == 0 {
+		soonest := p.endpoints[0]
+		for _, ep := range p.endpoints[1:] {
+			if ep.ejectedUntil.Before(soonest.ejectedUntil) {
+				soonest = ep
+			}
+		}
+		return soonest
+	}
+
+	candidates := healthy
+	if len(healthy) > 1 && avoid != nil {
+		candidates = make([]*endpoint, 0, len(healthy))
+		for _, ep := range healthy {
+			if ep != avoid {
+				candidates = append(candidates, ep)
+			}
+		}
+	}
+
+	total := 0.0
+	for _, ep := range candidates {
+		total += ep.effectiveWeight()
+	}
+	r := p.rng.Float64() * total
+	for _, ep := range candidates {
+		if r -= ep.effectiveWeight(); r < 0 {
+			return ep
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+func (ep *endpoint) effectiveWeight() float64 {
+	if ep.score < minScore {
+		return ep.weight * minScore
+	}
+	return ep.weight * ep.score
+}
+
+func (p *Pool) succeeded(ep *endpoint) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	ep.failures = 0
+	ep.score = (1-alpha)*ep.score + alpha
+}
+
+// failed records a failure. After EjectAfter consecutive failures, the endpoint is ejected for
+// EjectSecs. When it returns, a single further failure ejects it again.
+func (p *Pool) failed(ep *endpoint) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	ep.failures++
+	ep.score = (1 - alpha) * ep.score
+	if ep.failures >= p.policy.EjectAfter {
+		ep.ejectedUntil = p.now().Add(time.Duration(p.policy.EjectSecs) * time.Second)
+		ep.failures = p.policy.EjectAfter - 1
+		if len(p.endpoints) > 1 {
+			logger.Warn("RPC endpoint", ep.url.Redacted(), "ejected for", p.policy.EjectSecs, "seconds")
+		}
+	}
+}
+
+// backoff returns the delay before the given retry. A Retry-After header from the server takes
+// precedence if it asks for longer, but no delay exceeds MaxBackoffMs (a server asking for more
+// has, in effect, failed the request, which is retried elsewhere if the pool has other endpoints).
+func (p *Pool) backoff(attempt uint64, retryAfter time.Duration) time.Duration {
+	d := time.Duration(p.policy.BackoffMs) * time.Millisecond
+	max := time.Duration(p.policy.MaxBackoffMs) * time.Millisecond
+	for i := uint64(1); i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if retryAfter > d {
+		d = retryAfter
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+func retriable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0
+	}
+	if secs, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+func cloneUrl(u *url.URL) *url.URL {
+	c := *u
+	if u.User != nil {
+		user := *u.User
+		c.User = &user
+	}
+	return &c
+}
@@ -0,0 +1,181 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+)
+
+// fakeNode answers requests by host with the given status (or error) and counts them
+type fakeNode struct {
+	statuses map[string][]int
+	calls    map[string]int
+}
+
+var errRefused = errors.New("connection refused")
+
+func (f *fakeNode) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	n := f.calls[host]
+	f.calls[host]++
+	statuses := f.statuses[host]
+	status := statuses[len(statuses)-1]
+	if n < len(statuses) {
+		status = statuses[n]
+	}
+	if status == 0 {
+		return nil, errRefused
+	}
+	body, _ := io.ReadAll(req.Body)
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+	}, nil
+}
+
+func newTestPool(t *testing.T, node *fakeNode, endpoints []configtypes.RpcEndpoint, policy configtypes.RpcPolicy) (*Pool, *time.Time) {
+	p, err := New(endpoints, policy, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	p.now = func() time.Time { return now }
+	p.sleep = func(context.Context, time.Duration) error { return nil }
+	return p, &now
+}
+
+func send(p http.RoundTripper, url string) (*http.Response, error) {
+	req, _ := http.NewRequest("POST", url, strings.NewReader(`{"method":"eth_blockNumber"}`))
+	return p.RoundTrip(req)
+}
+
+func TestPoolFailover(t *testing.T) {
+	node := &fakeNode{
+		statuses: map[string][]int{"a": {http.StatusTooManyRequests}, "b": {http.StatusOK}},
+		calls:    map[string]int{},
+	}
+	endpoints := []configtypes.RpcEndpoint{{Url: "http://a", Weight: 100}, {Url: "http://b"}}
+	p, now := newTestPool(t, node, endpoints, configtypes.RpcPolicy{EjectAfter: 2, EjectSecs: 10})
+
+	for i := 0; i < 20; i++ {
+		resp, err := send(p, "http://unused")
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatal("request failed", i, resp, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != `{"method":"eth_blockNumber"}` {
+			t.Fatal("body not forwarded", string(body))
+		}
+	}
+
+	// a is heavily weighted, but after two 429s it is ejected and everything goes to b
+	if node.calls["a"] != 2 || node.calls["b"] != 20 {
+		t.Error("wrong calls", node.calls)
+	}
+	if status := p.Status(); status[0].Healthy || !status[1].Healthy {
+		t.Error("wrong status", status)
+	}
+
+	// a comes back after ten seconds and (since it is still failing) is ejected again after one more 429
+	*now = now.Add(11 * time.Second)
+	for i := 0; i < 20; i++ {
+		_, _ = send(p, "http://unused")
+	}
+	if node.calls["a"] != 3 {
+		t.Error("expected one more call to a, got", node.calls["a"])
+	}
+}
+
+func TestPoolSingleEndpoint(t *testing.T) {
+	node := &fakeNode{
+		statuses: map[string][]int{"a": {http.StatusTooManyRequests, http.StatusBadGateway, http.StatusOK}},
+		calls:    map[string]int{},
+	}
+	p, _ := newTestPool(t, node, []configtypes.RpcEndpoint{{Url: "http://a"}}, configtypes.RpcPolicy{})
+	resp, err := send(p, "http://a")
+	if err != nil || resp.StatusCode != http.StatusOK || node.calls["a"] != 3 {
+		t.Error("expected success on the third try", resp, err, node.calls)
+	}
+
+	// Out of retries, the caller sees the last response
+	node.statuses["a"] = []int{http.StatusTooManyRequests}
+	node.calls["a"] = 0
+	resp, err = send(p, "http://a")
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests || node.calls["a"] != 4 {
+		t.Error("expected the final 429", resp, err, node.calls)
+	}
+
+	// A node that is down is reported at once
+	node.statuses["a"] = []int{0}
+	node.calls["a"] = 0
+	if _, err = send(p, "http://a"); !errors.Is(err, errRefused) || node.calls["a"] != 1 {
+		t.Error("expected an immediate error", err, node.calls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p, _ := New([]configtypes.RpcEndpoint{{Url: "http://a"}}, configtypes.RpcPolicy{BackoffMs: 100, MaxBackoffMs: 500}, nil)
+	expected := []time.Duration{100, 200, 400, 500, 500}
+	for i, e := range expected {
+		if got := p.backoff(uint64(i+1), 0); got != e*time.Millisecond {
+			t.Error("attempt", i+1, "got", got, "expected", e*time.Millisecond)
+		}
+	}
+	if got := p.backoff(1, parseRetryAfter("2")); got != 500*time.Millisecond {
+		t.Error("Retry-After not capped", got)
+	}
+
+	p, _ = New([]configtypes.RpcEndpoint{{Url: "http://a"}}, configtypes.RpcPolicy{BackoffMs: 100}, nil)
+	if got := p.backoff(1, parseRetryAfter("2")); got != 2*time.Second {
+		t.Error("Retry-After not honored", got)
+	}
+	if got := p.backoff(1, parseRetryAfter("3600")); got != 8*time.Second {
+		t.Error("Retry-After not capped", got)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(2)
+	now := time.Unix(1700000000, 0)
+	l.now = func() time.Time { return now }
+
+	// A burst of two is allowed, after which requests are spaced half a second apart
+	expected := []time.Duration{0, 0, 500 * time.Millisecond, time.Second}
+	for i, e := range expected {
+		if got := l.reserve(); got != e {
+			t.Error("request", i, "got", got, "expected", e)
+		}
+	}
+
+	now = now.Add(10 * time.Second)
+	if got := l.reserve(); got != 0 {
+		t.Error("expected no wait after a pause, got", got)
+	}
+
+	if newLimiter(0) != nil {
+		t.Error("a zero rate should not limit")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	node := &fakeNode{
+		statuses: map[string][]int{"a": {http.StatusOK}, "b": {http.StatusOK}, "other": {http.StatusOK}},
+		calls:    map[string]int{},
+	}
+	tr := NewTransport(node)
+	tr.Register(configtypes.ChainGroup{Chain: "mainnet", RpcProvider: "http://a", RpcProviders: []configtypes.RpcEndpoint{{Url: "http://b"}}})
+
+	_, _ = send(tr, "http://a/")
+	_, _ = send(tr, "http://other")
+	if node.calls["a"] != 0 || node.calls["b"] != 1 || node.calls["other"] != 1 {
+		t.Error("wrong routing", node.calls)
+	}
+}
@@ -0,0 +1,81 @@
+package pool
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+)
+
+// Transport is an http.RoundTripper that routes requests addressed to a registered chain's
+// rpcProvider through that chain's pool. Any other request is passed to next unchanged.
+type Transport struct {
+	next  http.RoundTripper
+	pools map[string]*Pool
+	mutex sync.RWMutex
+}
+
+// NewTransport returns a Transport with no registered chains
+func NewTransport(next http.RoundTripper) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{
+		next:  next,
+		pools: make(map[string]*Pool),
+	}
+}
+
+// Register builds the chain's pool if it has not already been built. The pool holds the chain's
+// rpcProviders or, if there are none, its rpcProvider alone (which still gains retries and backoff).
+func (t *Transport) Register(ch configtypes.ChainGroup) {
+	key := poolKey(ch.RpcProvider)
+	if len(key) == 0 {
+		return
+	}
+
+	t.mutex.RLock()
+	_, exists := t.pools[key]
+	t.mutex.RUnlock()
+	if exists {
+		return
+	}
+
+	endpoints := ch.RpcProviders
+	if len(endpoints) == 0 {
+		endpoints = []configtypes.RpcEndpoint{{Url: ch.RpcProvider}}
+	}
+
+	p, err := New(endpoints, ch.RpcPolicy, t.next)
+	if err != nil {
+		logger.Warn("Could not build the rpc pool for", ch.Chain, err)
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if _, exists := t.pools[key]; !exists {
+		t.pools[key] = p
+	}
+}
+
+// Pool returns the pool for the given rpcProvider (or nil if there is none)
+func (t *Transport) Pool(rpcProvider string) *Pool {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.pools[poolKey(rpcProvider)]
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if p := t.Pool(req.URL.String()); p != nil {
+		return p.RoundTrip(req)
+	}
+	return t.next.RoundTrip(req)
+}
+
+func poolKey(url string) string {
+	return strings.TrimRight(url, "/")
+}
@@ -1,2 +1,6 @@
 // Package query provides access to the RPC server
+//
+// All requests share a single http client. If the TB_RPC_RECORD environment variable names a directory,
+// every request and its response is recorded there. If TB_RPC_REPLAY names such a directory, requests
+// are answered from it without touching the network. See package replay.
 package query
@@ -12,6 +12,9 @@ import (
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/debug"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/pool"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/replay"
 )
 
 // Params are used during calls to the RPC.
@@ -36,6 +39,32 @@ type eip1474Error struct {
 
 var rpcCounter uint32
 
+// pools routes requests for each registered chain through that chain's provider pool
+var pools = pool.NewTransport(http.DefaultTransport)
+
+// httpClient is used for all requests to the RPC. Its transport may be replaced (see SetTransport).
+var httpClient = &http.Client{Transport: pools}
+
+// SetTransport replaces the transport used for all requests to the RPC. A nil transport restores
+// the provider pools. This is how record and replay (see package replay) are installed.
+func SetTransport(transport http.RoundTripper) {
+	if transport == nil {
+		transport = pools
+	}
+	httpClient = &http.Client{Transport: transport}
+}
+
+// RegisterChain builds the chain's provider pool (see package pool) if it has not already been built.
+// Requests sent to the chain's rpcProvider are then spread across the pool.
+func RegisterChain(chain string) {
+	pools.Register(config.GetChain(chain))
+}
+
+// HttpClient returns the client used for all requests to the RPC
+func HttpClient() *http.Client {
+	return httpClient
+}
+
 type rpcPayload struct {
 	Jsonrpc string `json:"jsonrpc"`
 	Method  string `json:"method"`
@@ -52,6 +81,7 @@ type BatchPayload struct {
 
 // Query returns a single result for given method and params.
 func Query[T any](chain string, method string, params Params) (*T, error) {
+	RegisterChain(chain)
 	url := config.GetChain(chain).RpcProvider
 	return QueryUrl[T](url, method, params)
 }
@@ -84,8 +114,7 @@ func QueryWithHeaders[T any](url string, headers map[string]string, method strin
 				request.Header.Set(key, value)
 			}
 
-			client := &http.Client{}
-			if response, err := client.Do(request); err != nil {
+			if response, err := httpClient.Do(request); err != nil {
 				return nil, err
 			} else if response.StatusCode != 200 {
 				return nil, fmt.Errorf("%s: %d", response.Status, response.StatusCode)
@@ -124,6 +153,7 @@ func QueryBatchWithHeaders[T any](chain string, headers map[string]string, batch
 		payloads = append(payloads, *bpl.Payload)
 	}
 
+	RegisterChain(chain)
 	url := config.GetChain(chain).RpcProvider
 	payloadToSend := make([]rpcPayload, 0, len(payloads))
 
@@ -149,7 +179,7 @@ func QueryBatchWithHeaders[T any](chain string, headers map[string]string, batch
 
 	var result []rpcResponse[T]
 	body := bytes.NewReader(plBytes)
-	if response, err := http.Post(url, "application/json", body); err != nil {
+	if response, err := httpClient.Post(url, "application/json", body); err != nil {
 		return nil, err
 	} else {
 		defer response.Body.Close()
@@ -176,6 +206,14 @@ func init() {
 	//
 	// We change DefaultTransport as the whole codebase uses it.
 	http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost = runtime.GOMAXPROCS(0) * 4
+
+	// Record or replay the RPC if asked to do so by the environment
+	if transport, err := replay.TransportFromEnv(pools); err != nil {
+		logger.Fatal(err)
+	} else if transport != nil {
+		logger.Info("RPC", transport.Mode, "mode using fixtures in", transport.Dir)
+		SetTransport(transport)
+	}
 }
 
 type rpcDebug struct {
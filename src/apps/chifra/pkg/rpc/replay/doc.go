@@ -0,0 +1,7 @@
+// Package replay records JSON-RPC requests and their responses to a fixture directory and serves them
+// back deterministically, allowing code that needs an RPC endpoint to be tested without a network.
+//
+// A Transport may be installed in-process (see package query, which installs one if either the
+// TB_RPC_RECORD or the TB_RPC_REPLAY environment variable names a fixture directory) or placed behind
+// a local JSON-RPC server with NewHandler (as the rpcReplay tool in src/dev_tools does).
+package replay
@@ -0,0 +1,200 @@
+package replay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// call is a single JSON-RPC request stripped of its id and version, which do not affect the response
+type call struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// fixture is a recorded request and its response. For a batch, each response is stored in the order
+// of the requests with its id removed.
+type fixture struct {
+	Batch     bool              `json:"batch,omitempty"`
+	Requests  []call            `json:"requests"`
+	Responses []json.RawMessage `json:"responses"`
+}
+
+// parsedRequest is the body of an HTTP request to a JSON-RPC endpoint
+type parsedRequest struct {
+	batch bool
+	calls []call
+	ids   []json.RawMessage
+}
+
+func parseRequest(body []byte) (*parsedRequest, error) {
+	type rawCall struct {
+		call
+		ID json.RawMessage `json:"id"`
+	}
+
+	var raws []rawCall
+	ret := &parsedRequest{}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		ret.batch = true
+		if err := json.Unmarshal(trimmed, &raws); err != nil {
+			return nil, err
+		}
+	} else {
+		var raw rawCall
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, err
+		}
+		raws = append(raws, raw)
+	}
+
+	if len(raws) == 0 {
+		return nil, fmt.Errorf("empty batch")
+	}
+
+	for _, raw := range raws {
+		c := raw.call
+		if len(c.Params) > 0 {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, c.Params); err != nil {
+				return nil, err
+			}
+			c.Params = buf.Bytes()
+		}
+		ret.calls = append(ret.calls, c)
+		ret.ids = append(ret.ids, raw.ID)
+	}
+
+	return ret, nil
+}
+
+// path returns the file in which the request's fixture is stored. The file is named by a hash of the
+// request's methods and parameters and placed in a folder named for its (first) method.
+func (r *parsedRequest) path(dir string) string {
+	bytes, _ := json.Marshal(r.calls)
+	sum := sha256.Sum256(bytes)
+	folder := r.calls[0].Method
+	if r.batch {
+		folder = "batch_" + folder
+	}
+	return filepath.Join(dir, sanitize(folder), hex.EncodeToString(sum[:12])+".json")
+}
+
+// newFixture pairs the request with the response received from the upstream provider
+func (r *parsedRequest) newFixture(response []byte) (*fixture, error) {
+	f := &fixture{Batch: r.batch, Requests: r.calls}
+	if !r.batch {
+		stripped, _, err := stripId(response)
+		if err != nil {
+			return nil, err
+		}
+		f.Responses = []json.RawMessage{stripped}
+		return f, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(response, &items); err != nil {
+		return nil, err
+	}
+
+	// Servers may answer a batch in any order, so match each response to its request by id
+	byId := make(map[string]json.RawMessage, len(items))
+	for _, item := range items {
+		stripped, id, err := stripId(item)
+		if err != nil {
+			return nil, err
+		}
+		byId[string(id)] = stripped
+	}
+
+	for _, id := range r.ids {
+		item, ok := byId[string(id)]
+		if !ok {
+			return nil, fmt.Errorf("no response for request id %s", string(id))
+		}
+		f.Responses = append(f.Responses, item)
+	}
+	return f, nil
+}
+
+// response rebuilds the body of the recorded response using the ids of the current request
+func (f *fixture) response(r *parsedRequest) ([]byte, error) {
+	if len(f.Responses) != len(r.ids) {
+		return nil, fmt.Errorf("recorded %d responses for %d requests", len(f.Responses), len(r.ids))
+	}
+
+	items := make([]json.RawMessage, 0, len(f.Responses))
+	for i, item := range f.Responses {
+		withId, err := setId(item, r.ids[i])
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, withId)
+	}
+
+	if !f.Batch {
+		return items[0], nil
+	}
+	return json.Marshal(items)
+}
+
+func readFixture(path string) (*fixture, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(bytes, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &f, nil
+}
+
+func writeFixture(path string, f *fixture) error {
+	bytes, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(bytes, '\n'), 0644)
+}
+
+func stripId(item json.RawMessage) (json.RawMessage, json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(item, &fields); err != nil {
+		return nil, nil, err
+	}
+	id := fields["id"]
+	delete(fields, "id")
+	stripped, err := json.Marshal(fields)
+	return stripped, id, err
+}
+
+func setId(item, id json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(item, &fields); err != nil {
+		return nil, err
+	}
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	fields["id"] = id
+	return json.Marshal(fields)
+}
+
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '.' || r == ':' {
+			return '_'
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,143 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newUpstream returns a node that answers eth_blockNumber and eth_chainId (answering batches in
+// reverse order) and counts the requests it receives
+func newUpstream(t *testing.T, calls *int) *httptest.Server {
+	answer := func(req map[string]json.RawMessage) map[string]any {
+		var method string
+		_ = json.Unmarshal(req["method"], &method)
+		result := "0x1"
+		if method == "eth_blockNumber" {
+			result = "0x10"
+		}
+		return map[string]any{"jsonrpc": "2.0", "id": req["id"], "result": result}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		body, _ := io.ReadAll(r.Body)
+		if body[0] == '[' {
+			var reqs []map[string]json.RawMessage
+			if err := json.Unmarshal(body, &reqs); err != nil {
+				t.Fatal(err)
+			}
+			resps := []map[string]any{}
+			for i := len(reqs) - 1; i >= 0; i-- {
+				resps = append(resps, answer(reqs[i]))
+			}
+			_ = json.NewEncoder(w).Encode(resps)
+			return
+		}
+		var req map[string]json.RawMessage
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		_ = json.NewEncoder(w).Encode(answer(req))
+	}))
+}
+
+func post(t *testing.T, client *http.Client, url, body string) (string, error) {
+	resp, err := client.Post(url, "application/json", bytes.NewReader([]byte(body)))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	bytes, _ := io.ReadAll(resp.Body)
+	return string(bytes), nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	upstream := newUpstream(t, &calls)
+	defer upstream.Close()
+	dir := t.TempDir()
+
+	recorder, err := NewTransport(Record, dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: recorder}
+
+	single := `{"jsonrpc":"2.0","method":"eth_blockNumber","params":[],"id":1}`
+	batch := `[{"jsonrpc":"2.0","method":"eth_blockNumber","params":[],"id":1},{"jsonrpc":"2.0","method":"eth_chainId","params":[],"id":2}]`
+	if _, err := post(t, client, upstream.URL, single); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := post(t, client, upstream.URL, batch); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatal("expected two upstream calls, got", calls)
+	}
+
+	player, _ := NewTransport(Replay, dir, nil)
+	client = &http.Client{Transport: player}
+
+	// Whitespace in the parameters and the request id do not change the fixture used
+	got, err := post(t, client, "http://127.0.0.1:1", `{"jsonrpc":"2.0","method":"eth_blockNumber","params":[ ],"id":42}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != `{"id":42,"jsonrpc":"2.0","result":"0x10"}` {
+		t.Error("wrong single response", got)
+	}
+
+	// Batch responses are returned in request order
+	batch = `[{"jsonrpc":"2.0","method":"eth_blockNumber","params":[],"id":7},{"jsonrpc":"2.0","method":"eth_chainId","params":[],"id":8}]`
+	if got, err = post(t, client, "http://127.0.0.1:1", batch); err != nil {
+		t.Fatal(err)
+	}
+	if got != `[{"id":7,"jsonrpc":"2.0","result":"0x10"},{"id":8,"jsonrpc":"2.0","result":"0x1"}]` {
+		t.Error("wrong batch response", got)
+	}
+
+	if _, err = post(t, client, "http://127.0.0.1:1", `{"jsonrpc":"2.0","method":"eth_gasPrice","params":[],"id":1}`); !errors.Is(err, ErrNotRecorded) {
+		t.Error("expected ErrNotRecorded, got", err)
+	}
+
+	if calls != 2 {
+		t.Error("replay reached the upstream node", calls)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	calls := 0
+	upstream := newUpstream(t, &calls)
+	defer upstream.Close()
+	dir := t.TempDir()
+
+	recorder, _ := NewTransport(Record, dir, nil)
+	server := httptest.NewServer(NewHandler(recorder, upstream.URL))
+	if _, err := post(t, http.DefaultClient, server.URL, `{"jsonrpc":"2.0","method":"eth_chainId","params":[],"id":3}`); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	player, _ := NewTransport(Replay, dir, nil)
+	server = httptest.NewServer(NewHandler(player, ""))
+	defer server.Close()
+
+	got, err := post(t, http.DefaultClient, server.URL, `{"jsonrpc":"2.0","method":"eth_chainId","params":[],"id":4}`)
+	if err != nil || got != `{"id":4,"jsonrpc":"2.0","result":"0x1"}` {
+		t.Error("wrong response", got, err)
+	}
+
+	resp, err := http.Post(server.URL, "application/json", bytes.NewReader([]byte(`{"jsonrpc":"2.0","method":"eth_gasPrice","params":[],"id":1}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Error("expected not found for an unrecorded request, got", resp.StatusCode)
+	}
+}
@@ -0,0 +1,54 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// NewHandler returns an http.Handler that acts as a JSON-RPC endpoint. Each request is passed to the
+// transport. In record mode, the transport forwards it to upstreamUrl. In replay mode, upstreamUrl is
+// ignored. Point a chain's rpcProvider at a server running this handler to record or replay any
+// process, not just the current one.
+func NewHandler(t *Transport, upstreamUrl string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "JSON-RPC requests must be POSTed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		out, err := http.NewRequestWithContext(r.Context(), http.MethodPost, upstreamUrl, bytes.NewReader(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		out.Header.Set("Content-Type", "application/json")
+
+		resp, err := t.RoundTrip(out)
+		if err != nil {
+			status := http.StatusBadGateway
+			if errors.Is(err, ErrNotRecorded) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		defer resp.Body.Close()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	})
+}
+
+// ListenAndServe runs a JSON-RPC server on addr (for example, "localhost:8545") using NewHandler
+func ListenAndServe(addr string, t *Transport, upstreamUrl string) error {
+	return http.ListenAndServe(addr, NewHandler(t, upstreamUrl))
+}
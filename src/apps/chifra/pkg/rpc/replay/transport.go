@@ -0,0 +1,140 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// The modes of a Transport
+const (
+	Record = "record"
+	Replay = "replay"
+)
+
+// ErrNotRecorded is returned in replay mode for a request that has no fixture
+var ErrNotRecorded = errors.New("request was not recorded")
+
+// Transport is an http.RoundTripper for JSON-RPC requests. In record mode, it forwards each request
+// to the upstream transport and writes the request and response to the fixture directory. In replay
+// mode, it never touches the network. It answers from the fixture directory, with the response's ids
+// replaced by the ids of the request, and fails any request it has not seen.
+type Transport struct {
+	Mode     string
+	Dir      string
+	upstream http.RoundTripper
+	mutex    sync.Mutex
+}
+
+// NewTransport returns a Transport in the given mode storing its fixtures in dir. upstream is used
+// in record mode only. If it is nil, http.DefaultTransport is used.
+func NewTransport(mode, dir string, upstream http.RoundTripper) (*Transport, error) {
+	if mode != Record && mode != Replay {
+		return nil, fmt.Errorf("unknown replay mode %s", mode)
+	}
+	if len(dir) == 0 {
+		return nil, fmt.Errorf("a replay transport requires a fixture directory")
+	}
+	if upstream == nil {
+		upstream = http.DefaultTransport
+	}
+	return &Transport{Mode: mode, Dir: dir, upstream: upstream}, nil
+}
+
+// TransportFromEnv returns a Transport in record mode if TB_RPC_RECORD names a fixture directory or in
+// replay mode if TB_RPC_REPLAY does. It returns nil if neither is set. upstream is as for NewTransport.
+func TransportFromEnv(upstream http.RoundTripper) (*Transport, error) {
+	record, replay := os.Getenv("TB_RPC_RECORD"), os.Getenv("TB_RPC_REPLAY")
+	switch {
+	case len(record) > 0 && len(replay) > 0:
+		return nil, fmt.Errorf("TB_RPC_RECORD and TB_RPC_REPLAY may not both be set")
+	case len(record) > 0:
+		return NewTransport(Record, record, upstream)
+	case len(replay) > 0:
+		return NewTransport(Replay, replay, upstream)
+	}
+	return nil, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	parsed, err := parseRequest(body)
+	if err != nil {
+		return nil, fmt.Errorf("replay: could not parse request: %w", err)
+	}
+	path := parsed.path(t.Dir)
+
+	if t.Mode == Replay {
+		f, err := readFixture(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, parsed.calls[0].Method, string(parsed.calls[0].Params))
+			}
+			return nil, err
+		}
+		response, err := f.response(parsed)
+		if err != nil {
+			return nil, err
+		}
+		return newResponse(req, response), nil
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	resp, err := t.upstream.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	response, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(response))
+	resp.ContentLength = int64(len(response))
+
+	// Only successful exchanges are recorded. Errors reported by the node itself (in the body of a
+	// successful response) are recorded as they are part of the node's deterministic behaviour.
+	if resp.StatusCode == http.StatusOK {
+		f, err := parsed.newFixture(response)
+		if err != nil {
+			return nil, fmt.Errorf("replay: could not record response: %w", err)
+		}
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		if err := writeFixture(path, f); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+func newResponse(req *http.Request, body []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package types
+
+// EXISTING_CODE
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// EXISTING_CODE
+
+type Authorization struct {
+	Address base.Address `json:"address"`
+	ChainId base.Value   `json:"chainId"`
+	Nonce   base.Value   `json:"nonce"`
+	R       string       `json:"r"`
+	S       string       `json:"s"`
+	YParity base.Value   `json:"yParity"`
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+func (s Authorization) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
+
+func (s *Authorization) Model(chain, format string, verbose bool, extraOpts map[string]any) Model {
+	var model = map[string]any{}
+	var order = []string{}
+
+	// EXISTING_CODE
+	model = map[string]any{
+		"chainId": s.ChainId,
+		"address": s.Address,
+		"nonce":   s.Nonce,
+	}
+	order = []string{
+		"chainId",
+		"address",
+		"nonce",
+	}
+
+	if authority, err := s.Authority(); err == nil {
+		model["authority"] = authority
+		order = append(order, "authority")
+	}
+
+	if verbose {
+		model["yParity"] = s.YParity
+		model["r"] = s.R
+		model["s"] = s.S
+		order = append(order, []string{"yParity", "r", "s"}...)
+	}
+
+	items := []namer{
+		{addr: s.Address, name: "addressName"},
+	}
+	for _, item := range items {
+		if name, loaded, found := nameAddress(extraOpts, item.addr); found {
+			model[item.name] = name.Name
+			order = append(order, item.name)
+		} else if loaded && format != "json" {
+			model[item.name] = ""
+			order = append(order, item.name)
+		}
+	}
+	// EXISTING_CODE
+
+	return Model{
+		Data:  model,
+		Order: order,
+	}
+}
+
+func (s *Authorization) MarshalCache(writer io.Writer) (err error) {
+	// Address
+	if err = cache.WriteValue(writer, s.Address); err != nil {
+		return err
+	}
+
+	// ChainId
+	if err = cache.WriteValue(writer, s.ChainId); err != nil {
+		return err
+	}
+
+	// Nonce
+	if err = cache.WriteValue(writer, s.Nonce); err != nil {
+		return err
+	}
+
+	// R
+	if err = cache.WriteValue(writer, s.R); err != nil {
+		return err
+	}
+
+	// S
+	if err = cache.WriteValue(writer, s.S); err != nil {
+		return err
+	}
+
+	// YParity
+	if err = cache.WriteValue(writer, s.YParity); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Authorization) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
+	// Check for compatibility and return cache.ErrIncompatibleVersion to invalidate this item (see #3638)
+	// EXISTING_CODE
+	// EXISTING_CODE
+
+	// Address
+	if err = cache.ReadValue(reader, &s.Address, vers); err != nil {
+		return err
+	}
+
+	// ChainId
+	if err = cache.ReadValue(reader, &s.ChainId, vers); err != nil {
+		return err
+	}
+
+	// Nonce
+	if err = cache.ReadValue(reader, &s.Nonce, vers); err != nil {
+		return err
+	}
+
+	// R
+	if err = cache.ReadValue(reader, &s.R, vers); err != nil {
+		return err
+	}
+
+	// S
+	if err = cache.ReadValue(reader, &s.S, vers); err != nil {
+		return err
+	}
+
+	// YParity
+	if err = cache.ReadValue(reader, &s.YParity, vers); err != nil {
+		return err
+	}
+
+	s.FinishUnmarshal()
+
+	return nil
+}
+
+// FinishUnmarshal is used by the cache. It may be unused depending on auto-code-gen
+func (s *Authorization) FinishUnmarshal() {
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+// EXISTING_CODE
+// setCodeMagic prefixes the message signed by an authority (see EIP-7702)
+const setCodeMagic = 0x05
+
+var ErrInvalidAuthorization = errors.New("invalid authorization signature")
+
+// Authority recovers the address of the externally owned account that signed the authorization.
+// The signature is over keccak256(0x05 || rlp([chainId, address, nonce])).
+func (s *Authorization) Authority() (base.Address, error) {
+	r, okR := new(big.Int).SetString(strings.TrimPrefix(s.R, "0x"), 16)
+	ss, okS := new(big.Int).SetString(strings.TrimPrefix(s.S, "0x"), 16)
+	if !okR || !okS || s.YParity > 1 || !crypto.ValidateSignatureValues(byte(s.YParity), r, ss, true) {
+		return base.ZeroAddr, ErrInvalidAuthorization
+	}
+
+	payload, err := rlp.EncodeToBytes([]any{uint64(s.ChainId), s.Address.Address, uint64(s.Nonce)})
+	if err != nil {
+		return base.ZeroAddr, err
+	}
+	msg := crypto.Keccak256(append([]byte{setCodeMagic}, payload...))
+
+	sig := make([]byte, crypto.SignatureLength)
+	r.FillBytes(sig[0:32])
+	ss.FillBytes(sig[32:64])
+	sig[64] = byte(s.YParity)
+	pub, err := crypto.SigToPub(msg, sig)
+	if err != nil {
+		return base.ZeroAddr, err
+	}
+	return base.Address{Address: crypto.PubkeyToAddress(*pub)}, nil
+}
+
+// EXISTING_CODE
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestAuthority(t *testing.T) {
+	key, err := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := Authorization{
+		Address: base.HexToAddress("0x63c0c19a282a1b52b07dd5a65b58948a07dae32b"),
+		ChainId: 1,
+		Nonce:   7,
+	}
+	payload, _ := rlp.EncodeToBytes([]any{uint64(auth.ChainId), auth.Address.Address, uint64(auth.Nonce)})
+	sig, err := crypto.Sign(crypto.Keccak256(append([]byte{0x05}, payload...)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth.R = fmt.Sprintf("0x%x", sig[0:32])
+	auth.S = fmt.Sprintf("0x%x", sig[32:64])
+	auth.YParity = base.Value(sig[64])
+
+	expected := crypto.PubkeyToAddress(key.PublicKey)
+	if authority, err := auth.Authority(); err != nil || authority.Address != expected {
+		t.Fatal("wrong authority", authority, err, "expected", expected.Hex())
+	}
+
+	// A different nonce is a different message, so it recovers a different address
+	auth.Nonce = 8
+	if authority, err := auth.Authority(); err == nil && authority.Address == expected {
+		t.Fatal("authority should not match a modified authorization")
+	}
+
+	auth.YParity = 2
+	if _, err := auth.Authority(); err != ErrInvalidAuthorization {
+		t.Fatal("expected an invalid signature, got", err)
+	}
+}
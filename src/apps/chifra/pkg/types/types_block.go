@@ -25,8 +25,10 @@ import (
 
 type Block struct {
 	BaseFeePerGas base.Gas       `json:"baseFeePerGas"`
+	BlobGasUsed   base.Gas       `json:"blobGasUsed,omitempty"`
 	BlockNumber   base.Blknum    `json:"blockNumber"`
 	Difficulty    base.Value     `json:"difficulty"`
+	ExcessBlobGas base.Gas       `json:"excessBlobGas,omitempty"`
 	GasLimit      base.Gas       `json:"gasLimit"`
 	GasUsed       base.Gas       `json:"gasUsed"`
 	Hash          base.Hash      `json:"hash"`
@@ -107,6 +109,10 @@ func (s *Block) Model(chain, format string, verbose bool, extraOpts map[string]a
 		} else {
 			model["withdrawals"] = []Withdrawal{}
 		}
+		if s.BlockNumber >= base.KnownBlock(chain, base.Cancun) {
+			model["blobGasUsed"] = s.BlobGasUsed
+			model["excessBlobGas"] = s.ExcessBlobGas
+		}
 	} else {
 		model["transactionsCnt"] = len(s.Transactions)
 		order = append(order, "transactionsCnt")
@@ -151,6 +157,11 @@ func (s *Block) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// BlobGasUsed
+	if err = cache.WriteValue(writer, s.BlobGasUsed); err != nil {
+		return err
+	}
+
 	// BlockNumber
 	if err = cache.WriteValue(writer, s.BlockNumber); err != nil {
 		return err
@@ -161,6 +172,11 @@ func (s *Block) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// ExcessBlobGas
+	if err = cache.WriteValue(writer, s.ExcessBlobGas); err != nil {
+		return err
+	}
+
 	// GasLimit
 	if err = cache.WriteValue(writer, s.GasLimit); err != nil {
 		return err
@@ -237,6 +253,15 @@ func (s *Block) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		}
 	}
 
+	// BlobGasUsed
+	vBlobGasUsed := version.NewVersion("3.5.0")
+	if vers > vBlobGasUsed.Uint64() {
+		// BlobGasUsed
+		if err = cache.ReadValue(reader, &s.BlobGasUsed, vers); err != nil {
+			return err
+		}
+	}
+
 	// BlockNumber
 	if err = cache.ReadValue(reader, &s.BlockNumber, vers); err != nil {
 		return err
@@ -247,6 +272,15 @@ func (s *Block) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		return err
 	}
 
+	// ExcessBlobGas
+	vExcessBlobGas := version.NewVersion("3.5.0")
+	if vers > vExcessBlobGas.Uint64() {
+		// ExcessBlobGas
+		if err = cache.ReadValue(reader, &s.ExcessBlobGas, vers); err != nil {
+			return err
+		}
+	}
+
 	// GasLimit
 	if err = cache.ReadValue(reader, &s.GasLimit, vers); err != nil {
 		return err
@@ -0,0 +1,124 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package types
+
+// EXISTING_CODE
+import (
+	"encoding/json"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+)
+
+// EXISTING_CODE
+
+type Discrepancy struct {
+	AccountedFor     base.Address   `json:"accountedFor"`
+	AmountNet        base.Wei       `json:"amountNet"`
+	AssetAddr        base.Address   `json:"assetAddr"`
+	AssetSymbol      string         `json:"assetSymbol"`
+	BegBal           base.Wei       `json:"begBal"`
+	BlockNumber      base.Blknum    `json:"blockNumber"`
+	Classification   string         `json:"classification"`
+	CorrectingReason string         `json:"correctingReason,omitempty"`
+	Decimals         base.Value     `json:"decimals"`
+	DriftAmount      base.Wei       `json:"driftAmount"`
+	DriftBlock       base.Blknum    `json:"driftBlock"`
+	DriftHash        base.Hash      `json:"driftHash,omitempty"`
+	EndBal           base.Wei       `json:"endBal"`
+	LogIndex         base.Lognum    `json:"logIndex"`
+	PrevBal          base.Wei       `json:"prevBal"`
+	Probes           uint64         `json:"probes"`
+	Timestamp        base.Timestamp `json:"timestamp"`
+	TransactionHash  base.Hash      `json:"transactionHash"`
+	TransactionIndex base.Txnum     `json:"transactionIndex"`
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+func (s Discrepancy) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
+
+func (s *Discrepancy) Model(chain, format string, verbose bool, extraOpts map[string]any) Model {
+	var model = map[string]any{}
+	var order = []string{}
+
+	// EXISTING_CODE
+	model = map[string]any{
+		"accountedFor":     s.AccountedFor,
+		"assetAddr":        s.AssetAddr,
+		"assetSymbol":      s.AssetSymbol,
+		"decimals":         s.Decimals,
+		"blockNumber":      s.BlockNumber,
+		"transactionIndex": s.TransactionIndex,
+		"logIndex":         s.LogIndex,
+		"transactionHash":  s.TransactionHash,
+		"timestamp":        s.Timestamp,
+		"date":             s.Date(),
+		"prevBal":          s.PrevBal.Text(10),
+		"begBal":           s.BegBal.Text(10),
+		"endBal":           s.EndBal.Text(10),
+		"amountNet":        s.AmountNet.Text(10),
+		"driftBlock":       s.DriftBlock,
+		"driftAmount":      s.DriftAmount.Text(10),
+		"classification":   s.Classification,
+		"probes":           s.Probes,
+	}
+
+	order = []string{
+		"blockNumber",
+		"transactionIndex",
+		"logIndex",
+		"transactionHash",
+		"timestamp",
+		"date",
+		"accountedFor",
+		"assetAddr",
+		"assetSymbol",
+		"decimals",
+		"prevBal",
+		"begBal",
+		"endBal",
+		"amountNet",
+		"driftBlock",
+		"driftAmount",
+		"classification",
+		"probes",
+	}
+
+	if !s.DriftHash.IsZero() || format != "json" {
+		model["driftHash"] = s.DriftHash
+		order = append(order, "driftHash")
+	}
+
+	if len(s.CorrectingReason) > 0 || format != "json" {
+		model["correctingReason"] = s.CorrectingReason
+		order = append(order, "correctingReason")
+	}
+	// EXISTING_CODE
+
+	return Model{
+		Data:  model,
+		Order: order,
+	}
+}
+
+func (s *Discrepancy) Date() string {
+	return base.FormattedDate(s.Timestamp)
+}
+
+// FinishUnmarshal is used by the cache. It may be unused depending on auto-code-gen
+func (s *Discrepancy) FinishUnmarshal() {
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
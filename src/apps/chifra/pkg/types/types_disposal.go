@@ -0,0 +1,113 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package types
+
+// EXISTING_CODE
+import (
+	"encoding/json"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+)
+
+// EXISTING_CODE
+
+type Disposal struct {
+	AccountedFor      base.Address   `json:"accountedFor"`
+	AcquiredTimestamp base.Timestamp `json:"acquiredTimestamp"`
+	AssetAddr         base.Address   `json:"assetAddr"`
+	AssetSymbol       string         `json:"assetSymbol"`
+	BlockNumber       base.Blknum    `json:"blockNumber"`
+	CostBasis         base.Float     `json:"costBasis"`
+	Gain              base.Float     `json:"gain"`
+	LongTerm          bool           `json:"longTerm,omitempty"`
+	Method            string         `json:"method"`
+	Proceeds          base.Float     `json:"proceeds"`
+	Quantity          base.Float     `json:"quantity"`
+	Timestamp         base.Timestamp `json:"timestamp"`
+	TransactionHash   base.Hash      `json:"transactionHash"`
+	TransactionIndex  base.Txnum     `json:"transactionIndex"`
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+func (s Disposal) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
+
+func (s *Disposal) Model(chain, format string, verbose bool, extraOpts map[string]any) Model {
+	var model = map[string]any{}
+	var order = []string{}
+
+	// EXISTING_CODE
+	model = map[string]any{
+		"accountedFor":      s.AccountedFor,
+		"assetAddr":         s.AssetAddr,
+		"assetSymbol":       s.AssetSymbol,
+		"blockNumber":       s.BlockNumber,
+		"transactionIndex":  s.TransactionIndex,
+		"transactionHash":   s.TransactionHash,
+		"timestamp":         s.Timestamp,
+		"date":              s.Date(),
+		"acquiredTimestamp": s.AcquiredTimestamp,
+		"acquiredDate":      s.AcquiredDate(),
+		"method":            s.Method,
+		"quantity":          s.Quantity,
+		"proceeds":          s.Proceeds,
+		"costBasis":         s.CostBasis,
+		"gain":              s.Gain,
+	}
+
+	order = []string{
+		"blockNumber",
+		"transactionIndex",
+		"transactionHash",
+		"timestamp",
+		"date",
+		"accountedFor",
+		"assetAddr",
+		"assetSymbol",
+		"method",
+		"acquiredTimestamp",
+		"acquiredDate",
+		"quantity",
+		"proceeds",
+		"costBasis",
+		"gain",
+	}
+
+	if s.LongTerm || format != "json" {
+		model["longTerm"] = s.LongTerm
+		order = append(order, "longTerm")
+	}
+	// EXISTING_CODE
+
+	return Model{
+		Data:  model,
+		Order: order,
+	}
+}
+
+func (s *Disposal) Date() string {
+	return base.FormattedDate(s.Timestamp)
+}
+
+// FinishUnmarshal is used by the cache. It may be unused depending on auto-code-gen
+func (s *Disposal) FinishUnmarshal() {
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+// EXISTING_CODE
+
+func (s *Disposal) AcquiredDate() string {
+	return base.FormattedDate(s.AcquiredTimestamp)
+}
+
+// EXISTING_CODE
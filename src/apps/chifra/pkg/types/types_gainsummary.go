@@ -0,0 +1,109 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package types
+
+// EXISTING_CODE
+import (
+	"encoding/json"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+)
+
+// EXISTING_CODE
+
+type GainSummary struct {
+	AccountedFor   base.Address   `json:"accountedFor"`
+	Acquired       base.Float     `json:"acquired"`
+	AssetAddr      base.Address   `json:"assetAddr"`
+	AssetSymbol    string         `json:"assetSymbol"`
+	Balance        base.Float     `json:"balance"`
+	BlockNumber    base.Blknum    `json:"blockNumber"`
+	CostBasis      base.Float     `json:"costBasis"`
+	Disposed       base.Float     `json:"disposed"`
+	Method         string         `json:"method"`
+	OpenCostBasis  base.Float     `json:"openCostBasis"`
+	Period         string         `json:"period"`
+	Proceeds       base.Float     `json:"proceeds"`
+	RealizedGain   base.Float     `json:"realizedGain"`
+	SpotPrice      base.Float     `json:"spotPrice"`
+	Timestamp      base.Timestamp `json:"timestamp"`
+	UnrealizedGain base.Float     `json:"unrealizedGain"`
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+func (s GainSummary) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
+
+func (s *GainSummary) Model(chain, format string, verbose bool, extraOpts map[string]any) Model {
+	var model = map[string]any{}
+	var order = []string{}
+
+	// EXISTING_CODE
+	model = map[string]any{
+		"accountedFor":   s.AccountedFor,
+		"assetAddr":      s.AssetAddr,
+		"assetSymbol":    s.AssetSymbol,
+		"period":         s.Period,
+		"method":         s.Method,
+		"blockNumber":    s.BlockNumber,
+		"timestamp":      s.Timestamp,
+		"date":           s.Date(),
+		"acquired":       s.Acquired,
+		"disposed":       s.Disposed,
+		"proceeds":       s.Proceeds,
+		"costBasis":      s.CostBasis,
+		"realizedGain":   s.RealizedGain,
+		"balance":        s.Balance,
+		"openCostBasis":  s.OpenCostBasis,
+		"spotPrice":      s.SpotPrice,
+		"unrealizedGain": s.UnrealizedGain,
+	}
+
+	order = []string{
+		"period",
+		"accountedFor",
+		"assetAddr",
+		"assetSymbol",
+		"method",
+		"blockNumber",
+		"timestamp",
+		"date",
+		"acquired",
+		"disposed",
+		"proceeds",
+		"costBasis",
+		"realizedGain",
+		"balance",
+		"openCostBasis",
+		"spotPrice",
+		"unrealizedGain",
+	}
+	// EXISTING_CODE
+
+	return Model{
+		Data:  model,
+		Order: order,
+	}
+}
+
+func (s *GainSummary) Date() string {
+	return base.FormattedDate(s.Timestamp)
+}
+
+// FinishUnmarshal is used by the cache. It may be unused depending on auto-code-gen
+func (s *GainSummary) FinishUnmarshal() {
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
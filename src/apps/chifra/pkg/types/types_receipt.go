@@ -24,6 +24,8 @@ import (
 // EXISTING_CODE
 
 type Receipt struct {
+	BlobGasPrice      base.Gas     `json:"blobGasPrice,omitempty"`
+	BlobGasUsed       base.Gas     `json:"blobGasUsed,omitempty"`
 	BlockHash         base.Hash    `json:"blockHash,omitempty"`
 	BlockNumber       base.Blknum  `json:"blockNumber"`
 	ContractAddress   base.Address `json:"contractAddress,omitempty"`
@@ -100,6 +102,10 @@ func (s *Receipt) Model(chain, format string, verbose bool, extraOpts map[string
 		if !s.To.IsZero() {
 			model["to"] = s.To
 		}
+		if s.BlobGasUsed > 0 {
+			model["blobGasUsed"] = s.BlobGasUsed
+			model["blobGasPrice"] = s.BlobGasPrice
+		}
 
 	} else {
 		model["logsCnt"] = len(s.Logs)
@@ -163,6 +169,16 @@ func (s *ReceiptGroup) UnmarshalCache(vers uint64, reader io.Reader) (err error)
 }
 
 func (s *Receipt) MarshalCache(writer io.Writer) (err error) {
+	// BlobGasPrice
+	if err = cache.WriteValue(writer, s.BlobGasPrice); err != nil {
+		return err
+	}
+
+	// BlobGasUsed
+	if err = cache.WriteValue(writer, s.BlobGasUsed); err != nil {
+		return err
+	}
+
 	// BlockHash
 	if err = cache.WriteValue(writer, &s.BlockHash); err != nil {
 		return err
@@ -240,6 +256,24 @@ func (s *Receipt) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 	// EXISTING_CODE
 	// EXISTING_CODE
 
+	// BlobGasPrice
+	vBlobGasPrice := version.NewVersion("3.5.0")
+	if vers > vBlobGasPrice.Uint64() {
+		// BlobGasPrice
+		if err = cache.ReadValue(reader, &s.BlobGasPrice, vers); err != nil {
+			return err
+		}
+	}
+
+	// BlobGasUsed
+	vBlobGasUsed := version.NewVersion("3.5.0")
+	if vers > vBlobGasUsed.Uint64() {
+		// BlobGasUsed
+		if err = cache.ReadValue(reader, &s.BlobGasUsed, vers); err != nil {
+			return err
+		}
+	}
+
 	// BlockHash
 	if err = cache.ReadValue(reader, &s.BlockHash, vers); err != nil {
 		return err
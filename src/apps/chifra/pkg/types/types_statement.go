@@ -13,12 +13,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"path/filepath"
 	"strings"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/version"
 )
 
 // EXISTING_CODE
@@ -55,9 +57,15 @@ type Statement struct {
 	Timestamp           base.Timestamp `json:"timestamp"`
 	TransactionHash     base.Hash      `json:"transactionHash"`
 	TransactionIndex    base.Txnum     `json:"transactionIndex"`
+	WithdrawalIn        base.Wei       `json:"withdrawalIn,omitempty"`
 	// EXISTING_CODE
-	ReconType ReconType `json:"-"`
-	AssetType string    `json:"-"`
+	ReconType    ReconType  `json:"-"`
+	AssetType    string     `json:"-"`
+	FiatCurrency string     `json:"-"`
+	FxRate       base.Float `json:"-"`
+	TokenId      base.Wei   `json:"-"`
+	Entity       string     `json:"-"`
+	IntraEntity  bool       `json:"-"`
 	// EXISTING_CODE
 }
 
@@ -105,6 +113,7 @@ func (s *Statement) Model(chain, format string, verbose bool, extraOpts map[stri
 	model["minerNephewRewardIn"] = s.MinerNephewRewardIn.Text(10)
 	model["minerTxFeeIn"] = s.MinerTxFeeIn.Text(10)
 	model["minerUncleRewardIn"] = s.MinerUncleRewardIn.Text(10)
+	model["withdrawalIn"] = s.WithdrawalIn.Text(10)
 	model["correctingIn"] = s.CorrectingIn.Text(10)
 	model["prefundIn"] = s.PrefundIn.Text(10)
 	model["totalOut"] = s.TotalOut().Text(10)
@@ -127,11 +136,32 @@ func (s *Statement) Model(chain, format string, verbose bool, extraOpts map[stri
 		"assetAddr", "assetType", "assetSymbol", "decimals", "spotPrice", "priceSource", "accountedFor",
 		"sender", "recipient", "begBal", "amountNet", "endBal", "reconciliationType", "reconciled",
 		"totalIn", "amountIn", "internalIn", "selfDestructIn", "minerBaseRewardIn", "minerNephewRewardIn",
-		"minerTxFeeIn", "minerUncleRewardIn", "prefundIn", "totalOut", "amountOut", "internalOut",
+		"minerTxFeeIn", "minerUncleRewardIn", "withdrawalIn", "prefundIn", "totalOut", "amountOut", "internalOut",
 		"selfDestructOut", "gasOut", "totalOutLessGas", "prevBal", "begBalDiff",
 		"endBalDiff", "endBalCalc", "correctingReason",
 	}
 
+	if s.IsNft() {
+		model["tokenId"] = s.TokenId.Text(10)
+		order = append(order, "tokenId")
+	}
+
+	if s.Entity != "" {
+		model["entity"] = s.Entity
+		model["intraEntity"] = s.IntraEntity
+		order = append(order, []string{"entity", "intraEntity"}...)
+	}
+
+	if s.FiatCurrency != "" {
+		fiatSpotPrice := s.SpotPrice * s.FxRate
+		units := s.AmountNet().Float64() / math.Pow(10, float64(decimals))
+		model["fiatCurrency"] = s.FiatCurrency
+		model["fxRate"] = s.FxRate
+		model["fiatSpotPrice"] = fiatSpotPrice
+		model["fiatAmountNet"] = base.Float(units) * fiatSpotPrice
+		order = append(order, []string{"fiatCurrency", "fxRate", "fiatSpotPrice", "fiatAmountNet"}...)
+	}
+
 	asEther := extraOpts["ether"] == true
 	if asEther {
 		model["begBalEth"] = s.BegBal.ToEtherStr(decimals)
@@ -145,6 +175,7 @@ func (s *Statement) Model(chain, format string, verbose bool, extraOpts map[stri
 		model["minerNephewRewardInEth"] = s.MinerNephewRewardIn.ToEtherStr(decimals)
 		model["minerTxFeeInEth"] = s.MinerTxFeeIn.ToEtherStr(decimals)
 		model["minerUncleRewardInEth"] = s.MinerUncleRewardIn.ToEtherStr(decimals)
+		model["withdrawalInEth"] = s.WithdrawalIn.ToEtherStr(decimals)
 		model["correctingInEth"] = s.CorrectingIn.ToEtherStr(decimals)
 		model["prefundInEth"] = s.PrefundIn.ToEtherStr(decimals)
 		model["totalOutEth"] = s.TotalOut().ToEtherStr(decimals)
@@ -165,7 +196,7 @@ func (s *Statement) Model(chain, format string, verbose bool, extraOpts map[stri
 		order = append(order, []string{"begBalEth", "amountNetEth", "endBalEth",
 			"totalInEth", "amountInEth", "internalInEth", "selfDestructInEth",
 			"minerBaseRewardInEth", "minerNephewRewardInEth", "minerTxFeeInEth",
-			"minerUncleRewardInEth", "correctingInEth", "prefundInEth",
+			"minerUncleRewardInEth", "withdrawalInEth", "correctingInEth", "prefundInEth",
 			"totalOutEth", "amountOutEth", "internalOutEth", "correctingOutEth",
 			"selfDestructOutEth", "gasOutEth", "totalOutLessGasEth", "begBalDiffEth",
 			"endBalDiffEth", "endBalCalcEth", "prevBalEth"}...)
@@ -364,6 +395,11 @@ func (s *Statement) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// WithdrawalIn
+	if err = cache.WriteValue(writer, &s.WithdrawalIn); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -527,6 +563,15 @@ func (s *Statement) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		return err
 	}
 
+	// WithdrawalIn
+	vWithdrawalIn := version.NewVersion("3.5.0")
+	if vers > vWithdrawalIn.Uint64() {
+		// WithdrawalIn
+		if err = cache.ReadValue(reader, &s.WithdrawalIn, vers); err != nil {
+			return err
+		}
+	}
+
 	s.FinishUnmarshal()
 
 	return nil
@@ -550,6 +595,7 @@ func (s *Statement) TotalIn() *base.Wei {
 		s.MinerNephewRewardIn,
 		s.MinerTxFeeIn,
 		s.MinerUncleRewardIn,
+		s.WithdrawalIn,
 		s.CorrectingIn,
 		s.PrefundIn,
 	}
@@ -621,6 +667,12 @@ func (s *Statement) IsEth() bool {
 	return s.AssetAddr == base.FAKE_ETH_ADDRESS
 }
 
+// IsNft returns true if the statement records the movement of an ERC-721 or ERC-1155 token. The
+// statement's balances and amounts are then counts of the single token identified by TokenId.
+func (s *Statement) IsNft() bool {
+	return s.AssetType == "erc721" || s.AssetType == "erc1155"
+}
+
 var (
 	sai  = base.HexToAddress("0x89d24a6b4ccb1b6faa2625fe562bdd9a23260359")
 	dai  = base.HexToAddress("0x6b175474e89094c44da98b954eedeac495271d0f")
@@ -769,6 +821,7 @@ func (s *Statement) DebugStatement(ctx Ledgerer) {
 	reportE("   minerNephewRewardIn:", &s.MinerNephewRewardIn)
 	reportE("   minerTxFeeIn:       ", &s.MinerTxFeeIn)
 	reportE("   minerUncleRewardIn: ", &s.MinerUncleRewardIn)
+	reportE("   withdrawalIn:       ", &s.WithdrawalIn)
 	reportE("   correctingIn:       ", &s.CorrectingIn)
 	reportE("   prefundIn:          ", &s.PrefundIn)
 	reportE("   selfDestructIn:     ", &s.SelfDestructIn)
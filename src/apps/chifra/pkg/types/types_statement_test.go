@@ -0,0 +1,60 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+)
+
+func TestStatementWithdrawalIn(t *testing.T) {
+	// A partial withdrawal of 0.0175 ether to a validator's withdrawal address
+	s := Statement{
+		AccountedFor:     base.HexToAddress("0xf97e180c050e5ab072211ad2c213eb5aee4df134"),
+		AssetAddr:        base.FAKE_ETH_ADDRESS,
+		BlockNumber:      17034871,
+		TransactionIndex: WithdrawalAmt,
+		Sender:           base.WithdrawalSender,
+		Recipient:        base.HexToAddress("0xf97e180c050e5ab072211ad2c213eb5aee4df134"),
+		PrevBal:          *base.NewWei(1000000000000000000),
+		BegBal:           *base.NewWei(1000000000000000000),
+		EndBal:           *base.NewWei(1017500000000000000),
+		WithdrawalIn:     *base.NewWei(17500000000000000),
+	}
+
+	if s.TotalIn().Cmp(&s.WithdrawalIn) != 0 {
+		t.Fatal("withdrawalIn is not part of totalIn", s.TotalIn().Text(10))
+	}
+	if !s.Reconciled() {
+		t.Fatal("statement does not reconcile", s.EndBalDiff().Text(10))
+	}
+	if model := s.Model("mainnet", "json", false, nil); model.Data["withdrawalIn"] != "17500000000000000" {
+		t.Fatal("withdrawalIn missing from the model", model.Data["withdrawalIn"])
+	}
+
+	store, err := cache.NewStore(&cache.StoreOptions{Location: cache.MemoryCache})
+	if err != nil {
+		t.Fatal(err)
+	}
+	group := &StatementGroup{
+		BlockNumber:      s.BlockNumber,
+		TransactionIndex: s.TransactionIndex,
+		Address:          s.AccountedFor,
+		Statements:       []Statement{s},
+	}
+	if err := store.Write(group, nil); err != nil {
+		t.Fatal(err)
+	}
+	readBack := &StatementGroup{
+		BlockNumber:      s.BlockNumber,
+		TransactionIndex: s.TransactionIndex,
+		Address:          s.AccountedFor,
+	}
+	if err := store.Read(readBack, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(group.Statements, readBack.Statements) {
+		t.Fatalf("value mismatch:\n\tgot %+v\n\twant %+v\n", readBack.Statements, group.Statements)
+	}
+}
@@ -41,8 +41,9 @@ type Status struct {
 	RpcProvider   string      `json:"rpcProvider,omitempty"`
 	Version       string      `json:"version,omitempty"`
 	// EXISTING_CODE
-	Meta  *MetaData `json:"meta,omitempty"`
-	Diffs *MetaData `json:"diffs,omitempty"`
+	Meta    *MetaData      `json:"meta,omitempty"`
+	Diffs   *MetaData      `json:"diffs,omitempty"`
+	Watcher *WatcherStatus `json:"watcher,omitempty"`
 	// EXISTING_CODE
 }
 
@@ -137,6 +138,11 @@ func (s *Status) Model(chain, format string, verbose bool, extraOpts map[string]
 		model["chains"] = chains
 		order = append(order, "chains")
 	}
+
+	if s.Watcher != nil {
+		model["watcher"] = s.Watcher
+		order = append(order, "watcher")
+	}
 	// EXISTING_CODE
 
 	return Model{
@@ -160,4 +166,14 @@ func (s *Status) ShallowCopy() Status {
 	return ret
 }
 
+// WatcherStatus reports on the monitor watcher run by chifra daemon --monitor
+type WatcherStatus struct {
+	State      string `json:"state"`
+	NMonitors  int    `json:"nMonitors"`
+	NRuns      uint64 `json:"nRuns"`
+	LastRun    string `json:"lastRun,omitempty"`
+	LastReason string `json:"lastReason,omitempty"`
+	LastError  string `json:"lastError,omitempty"`
+}
+
 // EXISTING_CODE
@@ -18,6 +18,7 @@ import (
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/version"
 )
 
 type StorageSlot struct {
@@ -47,27 +48,30 @@ func NewReward(block, nephew, txFee, uncle *base.Wei) (Rewards, base.Wei) {
 // EXISTING_CODE
 
 type Transaction struct {
-	ArticulatedTx        *Function      `json:"articulatedTx"`
-	BlockHash            base.Hash      `json:"blockHash"`
-	BlockNumber          base.Blknum    `json:"blockNumber"`
-	From                 base.Address   `json:"from"`
-	Gas                  base.Gas       `json:"gas"`
-	GasPrice             base.Gas       `json:"gasPrice"`
-	GasUsed              base.Gas       `json:"gasUsed"`
-	HasToken             bool           `json:"hasToken"`
-	Hash                 base.Hash      `json:"hash"`
-	Input                string         `json:"input"`
-	IsError              bool           `json:"isError"`
-	MaxFeePerGas         base.Gas       `json:"maxFeePerGas"`
-	MaxPriorityFeePerGas base.Gas       `json:"maxPriorityFeePerGas"`
-	Nonce                base.Value     `json:"nonce"`
-	Receipt              *Receipt       `json:"receipt"`
-	Timestamp            base.Timestamp `json:"timestamp"`
-	To                   base.Address   `json:"to"`
-	Traces               []Trace        `json:"traces"`
-	TransactionIndex     base.Txnum     `json:"transactionIndex"`
-	TransactionType      string         `json:"type"`
-	Value                base.Wei       `json:"value"`
+	ArticulatedTx        *Function       `json:"articulatedTx"`
+	AuthorizationList    []Authorization `json:"authorizationList,omitempty"`
+	BlobVersionedHashes  []base.Hash     `json:"blobVersionedHashes,omitempty"`
+	BlockHash            base.Hash       `json:"blockHash"`
+	BlockNumber          base.Blknum     `json:"blockNumber"`
+	From                 base.Address    `json:"from"`
+	Gas                  base.Gas        `json:"gas"`
+	GasPrice             base.Gas        `json:"gasPrice"`
+	GasUsed              base.Gas        `json:"gasUsed"`
+	HasToken             bool            `json:"hasToken"`
+	Hash                 base.Hash       `json:"hash"`
+	Input                string          `json:"input"`
+	IsError              bool            `json:"isError"`
+	MaxFeePerBlobGas     base.Gas        `json:"maxFeePerBlobGas,omitempty"`
+	MaxFeePerGas         base.Gas        `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas base.Gas        `json:"maxPriorityFeePerGas"`
+	Nonce                base.Value      `json:"nonce"`
+	Receipt              *Receipt        `json:"receipt"`
+	Timestamp            base.Timestamp  `json:"timestamp"`
+	To                   base.Address    `json:"to"`
+	Traces               []Trace         `json:"traces"`
+	TransactionIndex     base.Txnum      `json:"transactionIndex"`
+	TransactionType      string          `json:"type"`
+	Value                base.Wei        `json:"value"`
 	// EXISTING_CODE
 	Message    string       `json:"-"`
 	Rewards    *Rewards     `json:"-"`
@@ -168,6 +172,19 @@ func (s *Transaction) Model(chain, format string, verbose bool, extraOpts map[st
 		if len(s.TransactionType) > 0 && s.TransactionType != "0x0" {
 			model["type"] = s.TransactionType
 		}
+		if s.MaxFeePerBlobGas > 0 {
+			model["maxFeePerBlobGas"] = s.MaxFeePerBlobGas
+		}
+		if len(s.BlobVersionedHashes) > 0 {
+			model["blobVersionedHashes"] = s.BlobVersionedHashes
+		}
+		if len(s.AuthorizationList) > 0 {
+			authorizations := make([]map[string]any, 0, len(s.AuthorizationList))
+			for _, auth := range s.AuthorizationList {
+				authorizations = append(authorizations, auth.Model(chain, format, verbose, extraOpts).Data)
+			}
+			model["authorizationList"] = authorizations
+		}
 		if len(s.Input) > 2 {
 			model["input"] = s.Input
 		}
@@ -193,6 +210,10 @@ func (s *Transaction) Model(chain, format string, verbose bool, extraOpts map[st
 				"gasUsed":           s.Receipt.GasUsed,
 				"status":            status,
 			}
+			if s.Receipt.BlobGasUsed > 0 {
+				receiptModel["blobGasUsed"] = s.Receipt.BlobGasUsed
+				receiptModel["blobGasPrice"] = s.Receipt.BlobGasPrice
+			}
 
 			// TODO: We've already made a copy of the data that we've queried from the chain,
 			// TODO: why are we copying it yet again? Can't we use pointers to the one copy of the data?
@@ -338,6 +359,20 @@ func (s *Transaction) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// AuthorizationList
+	authorizationlist := make([]cache.Marshaler, 0, len(s.AuthorizationList))
+	for _, item := range s.AuthorizationList {
+		authorizationlist = append(authorizationlist, &item)
+	}
+	if err = cache.WriteValue(writer, authorizationlist); err != nil {
+		return err
+	}
+
+	// BlobVersionedHashes
+	if err = cache.WriteValue(writer, s.BlobVersionedHashes); err != nil {
+		return err
+	}
+
 	// BlockHash
 	if err = cache.WriteValue(writer, &s.BlockHash); err != nil {
 		return err
@@ -388,6 +423,11 @@ func (s *Transaction) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// MaxFeePerBlobGas
+	if err = cache.WriteValue(writer, s.MaxFeePerBlobGas); err != nil {
+		return err
+	}
+
 	// MaxFeePerGas
 	if err = cache.WriteValue(writer, s.MaxFeePerGas); err != nil {
 		return err
@@ -453,6 +493,26 @@ func (s *Transaction) UnmarshalCache(vers uint64, reader io.Reader) (err error)
 	}
 	s.ArticulatedTx = optArticulatedTx.Get()
 
+	// AuthorizationList
+	vAuthorizationList := version.NewVersion("3.5.0")
+	if vers > vAuthorizationList.Uint64() {
+		// AuthorizationList
+		s.AuthorizationList = make([]Authorization, 0)
+		if err = cache.ReadValue(reader, &s.AuthorizationList, vers); err != nil {
+			return err
+		}
+	}
+
+	// BlobVersionedHashes
+	vBlobVersionedHashes := version.NewVersion("3.5.0")
+	if vers > vBlobVersionedHashes.Uint64() {
+		// BlobVersionedHashes
+		s.BlobVersionedHashes = make([]base.Hash, 0)
+		if err = cache.ReadValue(reader, &s.BlobVersionedHashes, vers); err != nil {
+			return err
+		}
+	}
+
 	// BlockHash
 	if err = cache.ReadValue(reader, &s.BlockHash, vers); err != nil {
 		return err
@@ -503,6 +563,15 @@ func (s *Transaction) UnmarshalCache(vers uint64, reader io.Reader) (err error)
 		return err
 	}
 
+	// MaxFeePerBlobGas
+	vMaxFeePerBlobGas := version.NewVersion("3.5.0")
+	if vers > vMaxFeePerBlobGas.Uint64() {
+		// MaxFeePerBlobGas
+		if err = cache.ReadValue(reader, &s.MaxFeePerBlobGas, vers); err != nil {
+			return err
+		}
+	}
+
 	// MaxFeePerGas
 	if err = cache.ReadValue(reader, &s.MaxFeePerGas, vers); err != nil {
 		return err
@@ -608,4 +677,13 @@ func (s *Transaction) GasCost() base.Gas {
 	return s.GasPrice * s.Receipt.GasUsed
 }
 
+// BlobGasCost returns the fee paid for the blob gas used by an EIP-4844 transaction. Unlike
+// regular gas, this fee is burned in full and is not part of GasCost.
+func (s *Transaction) BlobGasCost() base.Gas {
+	if s.Receipt == nil {
+		return 0
+	}
+	return s.Receipt.BlobGasPrice * s.Receipt.BlobGasUsed
+}
+
 // EXISTING_CODE
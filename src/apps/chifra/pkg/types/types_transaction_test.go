@@ -135,3 +135,57 @@ func TestTransactionCacheArticulated(t *testing.T) {
 		t.Fatalf("value mismatch: got %+v want %+v\n", readBack, expected)
 	}
 }
+
+func TestTransactionCacheBlobsAndAuthorizations(t *testing.T) {
+	expected := &Transaction{
+		AuthorizationList: []Authorization{
+			{
+				Address: base.HexToAddress("0x63c0c19a282a1b52b07dd5a65b58948a07dae32b"),
+				ChainId: 1,
+				Nonce:   7,
+				R:       "0x9a4ff45a5b8e3e0e8ec2a2ba1fa3fc6c9f31e9c0e5e1fc3c6e5e9a1b2c3d4e5f",
+				S:       "0x1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d",
+				YParity: 1,
+			},
+		},
+		BlobVersionedHashes: []base.Hash{
+			base.HexToHash("0x01b0761f87b081d5cf10757ccc89f12be355c70e2e29df288b65b30710dcbcd1"),
+		},
+		BlockNumber:      22431100,
+		From:             base.HexToAddress("0xea674fdde714fd979de3edf0f56aa9716b898ec8"),
+		Hash:             base.HexToHash("0x62974c8152c87e14880c54007260e0d5fe9d182c2cd22c58797735a9ae88370a"),
+		MaxFeePerBlobGas: 1000000000,
+		Receipt: &Receipt{
+			BlobGasPrice: 1,
+			BlobGasUsed:  131072,
+			GasUsed:      21000,
+		},
+		TransactionIndex: 3,
+		TransactionType:  "0x4",
+	}
+
+	store, err := cache.NewStore(&cache.StoreOptions{Location: cache.MemoryCache})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Write(expected, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	readBack := &Transaction{
+		BlockNumber:      expected.BlockNumber,
+		TransactionIndex: expected.TransactionIndex,
+	}
+	if err := store.Read(readBack, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, readBack) {
+		t.Fatalf("value mismatch: got %+v want %+v\n", readBack, expected)
+	}
+
+	if cost := readBack.BlobGasCost(); cost != 131072 {
+		t.Fatal("wrong blob gas cost", cost)
+	}
+}
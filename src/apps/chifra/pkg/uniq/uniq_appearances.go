@@ -37,6 +37,21 @@ func UniqFromReceipts(chain string, receipts []types.Receipt, addrMap AddressBoo
 	return nil
 }
 
+// UniqFromAuthorizations extracts the authorities (recovered from the signatures) and the delegated
+// code targets from the EIP-7702 authorization lists of an array of transactions
+func UniqFromAuthorizations(chain string, transactions []types.Transaction, addrMap AddressBooleanMap) (err error) {
+	for _, trans := range transactions {
+		for _, auth := range trans.AuthorizationList {
+			// An invalid signature is skipped by the node, but the delegate is still mentioned
+			if authority, err := auth.Authority(); err == nil {
+				addAddressToMaps(authority.Hex(), trans.BlockNumber, trans.TransactionIndex, addrMap)
+			}
+			addAddressToMaps(auth.Address.Hex(), trans.BlockNumber, trans.TransactionIndex, addrMap)
+		}
+	}
+	return nil
+}
+
 // uniqFromLogs extracts addresses from the logs
 func uniqFromLogs(chain string, logs []types.Log, addrMap AddressBooleanMap) (err error) {
 	for _, log := range logs {
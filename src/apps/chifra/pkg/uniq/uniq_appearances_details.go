@@ -123,6 +123,15 @@ func GetUniqAddressesInTransaction(chain string, procFunc UniqProcFunc, flow str
 		}
 	}
 
+	for a, auth := range trans.AuthorizationList {
+		if authority, err := auth.Authority(); err == nil {
+			reason := fmt.Sprintf("authorization_%d_authority", a)
+			streamAppearance(procFunc, flow, reason, authority.Hex(), bn, txid, traceid, ts, addrMap)
+		}
+		reason := fmt.Sprintf("authorization_%d_delegate", a)
+		streamAppearance(procFunc, flow, reason, auth.Address.Hex(), bn, txid, traceid, ts, addrMap)
+	}
+
 	// TODO: See issue #3195 - there are addresses on the receipt that do not appear in traces
 	if trans.Receipt != nil {
 		if err := uniqFromLogsDetails(chain, procFunc, flow, trans.Receipt.Logs, ts, addrMap); err != nil {
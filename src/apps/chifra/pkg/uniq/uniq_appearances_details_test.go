@@ -2,6 +2,9 @@ package uniq
 
 import (
 	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
 
 func TestAddressBooleanMap_Insert(t *testing.T) {
@@ -20,3 +23,27 @@ func TestAddressBooleanMap_Insert(t *testing.T) {
 		t.Fatal("value is false")
 	}
 }
+
+func TestUniqFromAuthorizations(t *testing.T) {
+	transactions := []types.Transaction{
+		{
+			BlockNumber:      22431100,
+			TransactionIndex: 3,
+			AuthorizationList: []types.Authorization{
+				// The signature is invalid, so only the delegate is an appearance
+				{Address: base.HexToAddress("0x63c0c19a282a1b52b07dd5a65b58948a07dae32b"), YParity: 2},
+				// Clearing a delegation (to the zero address) is not an appearance of the zero address
+				{Address: base.ZeroAddr, YParity: 2},
+			},
+		},
+	}
+
+	addrMap := make(AddressBooleanMap)
+	if err := UniqFromAuthorizations("mainnet", transactions, addrMap); err != nil {
+		t.Fatal(err)
+	}
+	key := "0x63c0c19a282a1b52b07dd5a65b58948a07dae32b	022431100	00003"
+	if len(addrMap) != 1 || !addrMap[key] {
+		t.Fatal("wrong appearances", addrMap)
+	}
+}
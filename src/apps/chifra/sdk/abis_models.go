@@ -0,0 +1,80 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	abis "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/abis"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// AbisOptions provides typed, in-process access to the chifra abis command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type AbisOptions struct {
+	Addrs     []string          `json:"addrs,omitempty"`
+	Known     bool              `json:"known,omitempty"`
+	ProxyFor  string            `json:"proxyFor,omitempty"`
+	List      bool              `json:"list,omitempty"`
+	Count     bool              `json:"count,omitempty"`
+	Find      []string          `json:"find,omitempty"`
+	Hint      []string          `json:"hint,omitempty"`
+	Encode    string            `json:"encode,omitempty"`
+	RenderCtx *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// Abis implements the chifra abis command in-process.
+func (opts AbisOptions) Abis() ([]types.Function, *types.MetaData, error) {
+	return queryModels[types.Function](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// AbisList implements the chifra abis --list command in-process.
+func (opts AbisOptions) AbisList() ([]types.Abi, *types.MetaData, error) {
+	opts.List = true
+	return queryModels[types.Abi](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// AbisCount implements the chifra abis --count command in-process.
+func (opts AbisOptions) AbisCount() ([]types.Count, *types.MetaData, error) {
+	opts.Count = true
+	return queryModels[types.Count](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// AbisFind implements the chifra abis --find command in-process.
+func (opts AbisOptions) AbisFind(val []string) ([]types.Function, *types.MetaData, error) {
+	opts.Find = val
+	return queryModels[types.Function](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// AbisEncode implements the chifra abis --encode command in-process.
+func (opts AbisOptions) AbisEncode(val string) ([]types.Function, *types.MetaData, error) {
+	opts.Encode = val
+	return queryModels[types.Function](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra abis in-process using the given render context.
+func (opts AbisOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	abis.ResetOptions(sdkTestMode)
+	in := abis.AbisFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.AbisInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
@@ -0,0 +1,159 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	blocks "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/blocks"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// BlocksOptions provides typed, in-process access to the chifra blocks command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type BlocksOptions struct {
+	BlockIds    []string          `json:"blocks,omitempty"`
+	Hashes      bool              `json:"hashes,omitempty"`
+	Uncles      bool              `json:"uncles,omitempty"`
+	Traces      bool              `json:"traces,omitempty"`
+	Uniq        bool              `json:"uniq,omitempty"`
+	Flow        string            `json:"flow,omitempty"`
+	Logs        bool              `json:"logs,omitempty"`
+	Emitter     []string          `json:"emitter,omitempty"`
+	Topic       []string          `json:"topic,omitempty"`
+	Withdrawals bool              `json:"withdrawals,omitempty"`
+	Articulate  bool              `json:"articulate,omitempty"`
+	Count       bool              `json:"count,omitempty"`
+	CacheTxs    bool              `json:"cacheTxs,omitempty"`
+	CacheTraces bool              `json:"cacheTraces,omitempty"`
+	RenderCtx   *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// Blocks implements the chifra blocks command in-process.
+func (opts BlocksOptions) Blocks() ([]types.Block, *types.MetaData, error) {
+	return queryModels[types.Block](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// BlocksHashes implements the chifra blocks --hashes command in-process.
+func (opts BlocksOptions) BlocksHashes() ([]types.LightBlock, *types.MetaData, error) {
+	opts.Hashes = true
+	return queryModels[types.LightBlock](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// BlocksUncles implements the chifra blocks --uncles command in-process.
+func (opts BlocksOptions) BlocksUncles() ([]types.LightBlock, *types.MetaData, error) {
+	opts.Uncles = true
+	return queryModels[types.LightBlock](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// BlocksTraces implements the chifra blocks --traces command in-process.
+func (opts BlocksOptions) BlocksTraces() ([]types.Trace, *types.MetaData, error) {
+	opts.Traces = true
+	return queryModels[types.Trace](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// BlocksUniq implements the chifra blocks --uniq command in-process.
+func (opts BlocksOptions) BlocksUniq() ([]types.Appearance, *types.MetaData, error) {
+	opts.Uniq = true
+	return queryModels[types.Appearance](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// BlocksLogs implements the chifra blocks --logs command in-process.
+func (opts BlocksOptions) BlocksLogs() ([]types.Log, *types.MetaData, error) {
+	opts.Logs = true
+	return queryModels[types.Log](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// BlocksWithdrawals implements the chifra blocks --withdrawals command in-process.
+func (opts BlocksOptions) BlocksWithdrawals() ([]types.Withdrawal, *types.MetaData, error) {
+	opts.Withdrawals = true
+	return queryModels[types.Withdrawal](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// BlocksCount implements the chifra blocks --count command in-process.
+func (opts BlocksOptions) BlocksCount() ([]types.BlockCount, *types.MetaData, error) {
+	opts.Count = true
+	return queryModels[types.BlockCount](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// BlocksStream is the streaming version of Blocks. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts BlocksOptions) BlocksStream() Seq2[types.Block] {
+	return streamModels[types.Block](opts.RenderCtx, opts.run)
+}
+
+// BlocksHashesStream is the streaming version of BlocksHashes. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts BlocksOptions) BlocksHashesStream() Seq2[types.LightBlock] {
+	opts.Hashes = true
+	return streamModels[types.LightBlock](opts.RenderCtx, opts.run)
+}
+
+// BlocksUnclesStream is the streaming version of BlocksUncles. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts BlocksOptions) BlocksUnclesStream() Seq2[types.LightBlock] {
+	opts.Uncles = true
+	return streamModels[types.LightBlock](opts.RenderCtx, opts.run)
+}
+
+// BlocksTracesStream is the streaming version of BlocksTraces. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts BlocksOptions) BlocksTracesStream() Seq2[types.Trace] {
+	opts.Traces = true
+	return streamModels[types.Trace](opts.RenderCtx, opts.run)
+}
+
+// BlocksUniqStream is the streaming version of BlocksUniq. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts BlocksOptions) BlocksUniqStream() Seq2[types.Appearance] {
+	opts.Uniq = true
+	return streamModels[types.Appearance](opts.RenderCtx, opts.run)
+}
+
+// BlocksLogsStream is the streaming version of BlocksLogs. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts BlocksOptions) BlocksLogsStream() Seq2[types.Log] {
+	opts.Logs = true
+	return streamModels[types.Log](opts.RenderCtx, opts.run)
+}
+
+// BlocksWithdrawalsStream is the streaming version of BlocksWithdrawals. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts BlocksOptions) BlocksWithdrawalsStream() Seq2[types.Withdrawal] {
+	opts.Withdrawals = true
+	return streamModels[types.Withdrawal](opts.RenderCtx, opts.run)
+}
+
+// BlocksCountStream is the streaming version of BlocksCount. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts BlocksOptions) BlocksCountStream() Seq2[types.BlockCount] {
+	opts.Count = true
+	return streamModels[types.BlockCount](opts.RenderCtx, opts.run)
+}
+
+// run invokes chifra blocks in-process using the given render context.
+func (opts BlocksOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	blocks.ResetOptions(sdkTestMode)
+	in := blocks.BlocksFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.BlocksInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
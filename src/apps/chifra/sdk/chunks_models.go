@@ -0,0 +1,207 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	chunks "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/chunks"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	// EXISTING_CODE
+)
+
+// ChunksOptions provides typed, in-process access to the chifra chunks command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type ChunksOptions struct {
+	Mode       string            `json:"mode,omitempty"`
+	BlockIds   []string          `json:"blocks,omitempty"`
+	Check      bool              `json:"check,omitempty"`
+	Pin        bool              `json:"pin,omitempty"`
+	Publish    bool              `json:"publish,omitempty"`
+	Publisher  string            `json:"publisher,omitempty"`
+	Truncate   base.Blknum       `json:"truncate,omitempty"`
+	Remote     bool              `json:"remote,omitempty"`
+	Belongs    []string          `json:"belongs,omitempty"`
+	Diff       bool              `json:"diff,omitempty"`
+	FirstBlock base.Blknum       `json:"firstBlock,omitempty"`
+	LastBlock  base.Blknum       `json:"lastBlock,omitempty"`
+	MaxAddrs   uint64            `json:"maxAddrs,omitempty"`
+	Deep       bool              `json:"deep,omitempty"`
+	Rewrite    bool              `json:"rewrite,omitempty"`
+	List       bool              `json:"list,omitempty"`
+	Unpin      bool              `json:"unpin,omitempty"`
+	Count      bool              `json:"count,omitempty"`
+	Tag        string            `json:"tag,omitempty"`
+	Sleep      float64           `json:"sleep,omitempty"`
+	RenderCtx  *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// ChunksManifest implements the chifra chunks manifest command in-process.
+func (opts ChunksOptions) ChunksManifest() ([]types.ChunkManifest, *types.MetaData, error) {
+	opts.Mode = "manifest"
+	return queryModels[types.ChunkManifest](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ChunksIndex implements the chifra chunks index command in-process.
+func (opts ChunksOptions) ChunksIndex() ([]types.ChunkIndex, *types.MetaData, error) {
+	opts.Mode = "index"
+	return queryModels[types.ChunkIndex](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ChunksBlooms implements the chifra chunks blooms command in-process.
+func (opts ChunksOptions) ChunksBlooms() ([]types.ChunkBloom, *types.MetaData, error) {
+	opts.Mode = "blooms"
+	return queryModels[types.ChunkBloom](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ChunksPins implements the chifra chunks pins command in-process.
+func (opts ChunksOptions) ChunksPins() ([]types.ChunkPin, *types.MetaData, error) {
+	opts.Mode = "pins"
+	return queryModels[types.ChunkPin](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ChunksAddresses implements the chifra chunks addresses command in-process.
+func (opts ChunksOptions) ChunksAddresses() ([]types.ChunkAddress, *types.MetaData, error) {
+	opts.Mode = "addresses"
+	return queryModels[types.ChunkAddress](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ChunksAppearances implements the chifra chunks appearances command in-process.
+func (opts ChunksOptions) ChunksAppearances() ([]types.ChunkAppearance, *types.MetaData, error) {
+	opts.Mode = "appearances"
+	return queryModels[types.ChunkAppearance](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ChunksStats implements the chifra chunks stats command in-process.
+func (opts ChunksOptions) ChunksStats() ([]types.ChunkStats, *types.MetaData, error) {
+	opts.Mode = "stats"
+	return queryModels[types.ChunkStats](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ChunksTruncate implements the chifra chunks --truncate command in-process.
+func (opts ChunksOptions) ChunksTruncate(val base.Blknum) ([]types.Message, *types.MetaData, error) {
+	opts.Truncate = val
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ChunksDiff implements the chifra chunks --diff command in-process.
+func (opts ChunksOptions) ChunksDiff() ([]types.Message, *types.MetaData, error) {
+	opts.Diff = true
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ChunksCount implements the chifra chunks --count command in-process.
+func (opts ChunksOptions) ChunksCount() ([]types.Count, *types.MetaData, error) {
+	opts.Count = true
+	return queryModels[types.Count](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ChunksTag implements the chifra chunks --tag command in-process.
+func (opts ChunksOptions) ChunksTag(val string) ([]types.Message, *types.MetaData, error) {
+	opts.Tag = val
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ChunksManifestStream is the streaming version of ChunksManifest. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ChunksOptions) ChunksManifestStream() Seq2[types.ChunkManifest] {
+	opts.Mode = "manifest"
+	return streamModels[types.ChunkManifest](opts.RenderCtx, opts.run)
+}
+
+// ChunksIndexStream is the streaming version of ChunksIndex. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ChunksOptions) ChunksIndexStream() Seq2[types.ChunkIndex] {
+	opts.Mode = "index"
+	return streamModels[types.ChunkIndex](opts.RenderCtx, opts.run)
+}
+
+// ChunksBloomsStream is the streaming version of ChunksBlooms. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ChunksOptions) ChunksBloomsStream() Seq2[types.ChunkBloom] {
+	opts.Mode = "blooms"
+	return streamModels[types.ChunkBloom](opts.RenderCtx, opts.run)
+}
+
+// ChunksPinsStream is the streaming version of ChunksPins. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ChunksOptions) ChunksPinsStream() Seq2[types.ChunkPin] {
+	opts.Mode = "pins"
+	return streamModels[types.ChunkPin](opts.RenderCtx, opts.run)
+}
+
+// ChunksAddressesStream is the streaming version of ChunksAddresses. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ChunksOptions) ChunksAddressesStream() Seq2[types.ChunkAddress] {
+	opts.Mode = "addresses"
+	return streamModels[types.ChunkAddress](opts.RenderCtx, opts.run)
+}
+
+// ChunksAppearancesStream is the streaming version of ChunksAppearances. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ChunksOptions) ChunksAppearancesStream() Seq2[types.ChunkAppearance] {
+	opts.Mode = "appearances"
+	return streamModels[types.ChunkAppearance](opts.RenderCtx, opts.run)
+}
+
+// ChunksStatsStream is the streaming version of ChunksStats. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ChunksOptions) ChunksStatsStream() Seq2[types.ChunkStats] {
+	opts.Mode = "stats"
+	return streamModels[types.ChunkStats](opts.RenderCtx, opts.run)
+}
+
+// ChunksTruncateStream is the streaming version of ChunksTruncate. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ChunksOptions) ChunksTruncateStream(val base.Blknum) Seq2[types.Message] {
+	opts.Truncate = val
+	return streamModels[types.Message](opts.RenderCtx, opts.run)
+}
+
+// ChunksDiffStream is the streaming version of ChunksDiff. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ChunksOptions) ChunksDiffStream() Seq2[types.Message] {
+	opts.Diff = true
+	return streamModels[types.Message](opts.RenderCtx, opts.run)
+}
+
+// ChunksCountStream is the streaming version of ChunksCount. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ChunksOptions) ChunksCountStream() Seq2[types.Count] {
+	opts.Count = true
+	return streamModels[types.Count](opts.RenderCtx, opts.run)
+}
+
+// ChunksTagStream is the streaming version of ChunksTag. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ChunksOptions) ChunksTagStream(val string) Seq2[types.Message] {
+	opts.Tag = val
+	return streamModels[types.Message](opts.RenderCtx, opts.run)
+}
+
+// run invokes chifra chunks in-process using the given render context.
+func (opts ChunksOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	chunks.ResetOptions(sdkTestMode)
+	in := chunks.ChunksFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.ChunksInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
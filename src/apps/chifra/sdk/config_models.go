@@ -0,0 +1,58 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	config "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// ConfigOptions provides typed, in-process access to the chifra config command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type ConfigOptions struct {
+	Mode      string            `json:"mode,omitempty"`
+	Paths     bool              `json:"paths,omitempty"`
+	Session   bool              `json:"session,omitempty"`
+	RenderCtx *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// ConfigPaths implements the chifra config --paths command in-process.
+func (opts ConfigOptions) ConfigPaths() ([]types.CacheItem, *types.MetaData, error) {
+	opts.Paths = true
+	return queryModels[types.CacheItem](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ConfigSession implements the chifra config --session command in-process.
+func (opts ConfigOptions) ConfigSession() ([]types.Session, *types.MetaData, error) {
+	opts.Session = true
+	return queryModels[types.Session](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra config in-process using the given render context.
+func (opts ConfigOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	config.ResetOptions(sdkTestMode)
+	in := config.ConfigFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.ConfigInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
@@ -0,0 +1,53 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	explore "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/explore"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// ExploreOptions provides typed, in-process access to the chifra explore command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type ExploreOptions struct {
+	Terms     []string          `json:"terms,omitempty"`
+	NoOpen    bool              `json:"noOpen,omitempty"`
+	Local     bool              `json:"local,omitempty"`
+	Google    bool              `json:"google,omitempty"`
+	Dalle     bool              `json:"dalle,omitempty"`
+	RenderCtx *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// Explore implements the chifra explore command in-process.
+func (opts ExploreOptions) Explore() ([]types.Destination, *types.MetaData, error) {
+	return queryModels[types.Destination](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra explore in-process using the given render context.
+func (opts ExploreOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	explore.ResetOptions(sdkTestMode)
+	in := explore.ExploreFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.ExploreInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
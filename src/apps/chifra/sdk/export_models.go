@@ -0,0 +1,250 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	export "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/export"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	// EXISTING_CODE
+)
+
+// ExportOptions provides typed, in-process access to the chifra export command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type ExportOptions struct {
+	Addrs       []string          `json:"addrs,omitempty"`
+	Topics      []string          `json:"topics,omitempty"`
+	Fourbytes   []string          `json:"fourbytes,omitempty"`
+	Appearances bool              `json:"appearances,omitempty"`
+	Receipts    bool              `json:"receipts,omitempty"`
+	Logs        bool              `json:"logs,omitempty"`
+	Traces      bool              `json:"traces,omitempty"`
+	Neighbors   bool              `json:"neighbors,omitempty"`
+	Accounting  bool              `json:"accounting,omitempty"`
+	Statements  bool              `json:"statements,omitempty"`
+	Balances    bool              `json:"balances,omitempty"`
+	Withdrawals bool              `json:"withdrawals,omitempty"`
+	Articulate  bool              `json:"articulate,omitempty"`
+	CacheTraces bool              `json:"cacheTraces,omitempty"`
+	Count       bool              `json:"count,omitempty"`
+	FirstRecord uint64            `json:"firstRecord,omitempty"`
+	MaxRecords  uint64            `json:"maxRecords,omitempty"`
+	Relevant    bool              `json:"relevant,omitempty"`
+	Emitter     []string          `json:"emitter,omitempty"`
+	Topic       []string          `json:"topic,omitempty"`
+	Reverted    bool              `json:"reverted,omitempty"`
+	Asset       []string          `json:"asset,omitempty"`
+	Nfts        bool              `json:"nfts,omitempty"`
+	Diagnose    bool              `json:"diagnose,omitempty"`
+	Flow        string            `json:"flow,omitempty"`
+	Entity      string            `json:"entity,omitempty"`
+	Intra       string            `json:"intra,omitempty"`
+	Currency    string            `json:"currency,omitempty"`
+	Gains       bool              `json:"gains,omitempty"`
+	CostBasis   string            `json:"costBasis,omitempty"`
+	Period      string            `json:"period,omitempty"`
+	Journal     string            `json:"journal,omitempty"`
+	Factory     bool              `json:"factory,omitempty"`
+	Unripe      bool              `json:"unripe,omitempty"`
+	Cursor      string            `json:"cursor,omitempty"`
+	Reversed    bool              `json:"reversed,omitempty"`
+	NoZero      bool              `json:"noZero,omitempty"`
+	FirstBlock  base.Blknum       `json:"firstBlock,omitempty"`
+	LastBlock   base.Blknum       `json:"lastBlock,omitempty"`
+	RenderCtx   *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// Export implements the chifra export command in-process.
+func (opts ExportOptions) Export() ([]types.Transaction, *types.MetaData, error) {
+	return queryModels[types.Transaction](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportAppearances implements the chifra export --appearances command in-process.
+func (opts ExportOptions) ExportAppearances() ([]types.Appearance, *types.MetaData, error) {
+	opts.Appearances = true
+	return queryModels[types.Appearance](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportReceipts implements the chifra export --receipts command in-process.
+func (opts ExportOptions) ExportReceipts() ([]types.Receipt, *types.MetaData, error) {
+	opts.Receipts = true
+	return queryModels[types.Receipt](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportLogs implements the chifra export --logs command in-process.
+func (opts ExportOptions) ExportLogs() ([]types.Log, *types.MetaData, error) {
+	opts.Logs = true
+	return queryModels[types.Log](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportTraces implements the chifra export --traces command in-process.
+func (opts ExportOptions) ExportTraces() ([]types.Trace, *types.MetaData, error) {
+	opts.Traces = true
+	return queryModels[types.Trace](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportNeighbors implements the chifra export --neighbors command in-process.
+func (opts ExportOptions) ExportNeighbors() ([]types.Message, *types.MetaData, error) {
+	opts.Neighbors = true
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportStatements implements the chifra export --statements command in-process.
+func (opts ExportOptions) ExportStatements() ([]types.Statement, *types.MetaData, error) {
+	opts.Statements = true
+	return queryModels[types.Statement](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportBalances implements the chifra export --balances command in-process.
+func (opts ExportOptions) ExportBalances() ([]types.State, *types.MetaData, error) {
+	opts.Balances = true
+	return queryModels[types.State](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportWithdrawals implements the chifra export --withdrawals command in-process.
+func (opts ExportOptions) ExportWithdrawals() ([]types.Withdrawal, *types.MetaData, error) {
+	opts.Withdrawals = true
+	return queryModels[types.Withdrawal](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportCount implements the chifra export --count command in-process.
+func (opts ExportOptions) ExportCount() ([]types.Monitor, *types.MetaData, error) {
+	opts.Count = true
+	return queryModels[types.Monitor](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportDiagnose implements the chifra export --diagnose command in-process.
+func (opts ExportOptions) ExportDiagnose() ([]types.Discrepancy, *types.MetaData, error) {
+	opts.Diagnose = true
+	return queryModels[types.Discrepancy](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportGains implements the chifra export --gains command in-process.
+func (opts ExportOptions) ExportGains() ([]types.Disposal, *types.MetaData, error) {
+	opts.Gains = true
+	return queryModels[types.Disposal](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportPeriod implements the chifra export --period command in-process.
+func (opts ExportOptions) ExportPeriod(val string) ([]types.GainSummary, *types.MetaData, error) {
+	opts.Period = val
+	return queryModels[types.GainSummary](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ExportStream is the streaming version of Export. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportStream() Seq2[types.Transaction] {
+	return streamModels[types.Transaction](opts.RenderCtx, opts.run)
+}
+
+// ExportAppearancesStream is the streaming version of ExportAppearances. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportAppearancesStream() Seq2[types.Appearance] {
+	opts.Appearances = true
+	return streamModels[types.Appearance](opts.RenderCtx, opts.run)
+}
+
+// ExportReceiptsStream is the streaming version of ExportReceipts. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportReceiptsStream() Seq2[types.Receipt] {
+	opts.Receipts = true
+	return streamModels[types.Receipt](opts.RenderCtx, opts.run)
+}
+
+// ExportLogsStream is the streaming version of ExportLogs. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportLogsStream() Seq2[types.Log] {
+	opts.Logs = true
+	return streamModels[types.Log](opts.RenderCtx, opts.run)
+}
+
+// ExportTracesStream is the streaming version of ExportTraces. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportTracesStream() Seq2[types.Trace] {
+	opts.Traces = true
+	return streamModels[types.Trace](opts.RenderCtx, opts.run)
+}
+
+// ExportNeighborsStream is the streaming version of ExportNeighbors. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportNeighborsStream() Seq2[types.Message] {
+	opts.Neighbors = true
+	return streamModels[types.Message](opts.RenderCtx, opts.run)
+}
+
+// ExportStatementsStream is the streaming version of ExportStatements. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportStatementsStream() Seq2[types.Statement] {
+	opts.Statements = true
+	return streamModels[types.Statement](opts.RenderCtx, opts.run)
+}
+
+// ExportBalancesStream is the streaming version of ExportBalances. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportBalancesStream() Seq2[types.State] {
+	opts.Balances = true
+	return streamModels[types.State](opts.RenderCtx, opts.run)
+}
+
+// ExportWithdrawalsStream is the streaming version of ExportWithdrawals. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportWithdrawalsStream() Seq2[types.Withdrawal] {
+	opts.Withdrawals = true
+	return streamModels[types.Withdrawal](opts.RenderCtx, opts.run)
+}
+
+// ExportCountStream is the streaming version of ExportCount. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportCountStream() Seq2[types.Monitor] {
+	opts.Count = true
+	return streamModels[types.Monitor](opts.RenderCtx, opts.run)
+}
+
+// ExportDiagnoseStream is the streaming version of ExportDiagnose. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportDiagnoseStream() Seq2[types.Discrepancy] {
+	opts.Diagnose = true
+	return streamModels[types.Discrepancy](opts.RenderCtx, opts.run)
+}
+
+// ExportGainsStream is the streaming version of ExportGains. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportGainsStream() Seq2[types.Disposal] {
+	opts.Gains = true
+	return streamModels[types.Disposal](opts.RenderCtx, opts.run)
+}
+
+// ExportPeriodStream is the streaming version of ExportPeriod. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ExportOptions) ExportPeriodStream(val string) Seq2[types.GainSummary] {
+	opts.Period = val
+	return streamModels[types.GainSummary](opts.RenderCtx, opts.run)
+}
+
+// run invokes chifra export in-process using the given render context.
+func (opts ExportOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	export.ResetOptions(sdkTestMode)
+	in := export.ExportFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.ExportInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
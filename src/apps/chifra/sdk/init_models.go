@@ -0,0 +1,68 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	initPkg "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/init"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	// EXISTING_CODE
+)
+
+// InitOptions provides typed, in-process access to the chifra init command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type InitOptions struct {
+	All        bool              `json:"all,omitempty"`
+	Example    string            `json:"example,omitempty"`
+	DryRun     bool              `json:"dryRun,omitempty"`
+	Publisher  string            `json:"publisher,omitempty"`
+	FirstBlock base.Blknum       `json:"firstBlock,omitempty"`
+	Sleep      float64           `json:"sleep,omitempty"`
+	RenderCtx  *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// InitAll implements the chifra init --all command in-process.
+func (opts InitOptions) InitAll() ([]types.Message, *types.MetaData, error) {
+	opts.All = true
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// InitExample implements the chifra init --example command in-process.
+func (opts InitOptions) InitExample(val string) ([]types.Message, *types.MetaData, error) {
+	opts.Example = val
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// InitDryRun implements the chifra init --dryrun command in-process.
+func (opts InitOptions) InitDryRun() ([]types.Message, *types.MetaData, error) {
+	opts.DryRun = true
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra init in-process using the given render context.
+func (opts InitOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	initPkg.ResetOptions(sdkTestMode)
+	in := initPkg.InitFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.InitInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
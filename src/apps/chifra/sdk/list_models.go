@@ -0,0 +1,93 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	list "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/list"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	// EXISTING_CODE
+)
+
+// ListOptions provides typed, in-process access to the chifra list command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type ListOptions struct {
+	Addrs       []string          `json:"addrs,omitempty"`
+	Count       bool              `json:"count,omitempty"`
+	NoZero      bool              `json:"noZero,omitempty"`
+	Bounds      bool              `json:"bounds,omitempty"`
+	Unripe      bool              `json:"unripe,omitempty"`
+	Silent      bool              `json:"silent,omitempty"`
+	FirstRecord uint64            `json:"firstRecord,omitempty"`
+	MaxRecords  uint64            `json:"maxRecords,omitempty"`
+	Reversed    bool              `json:"reversed,omitempty"`
+	Publisher   string            `json:"publisher,omitempty"`
+	FirstBlock  base.Blknum       `json:"firstBlock,omitempty"`
+	LastBlock   base.Blknum       `json:"lastBlock,omitempty"`
+	RenderCtx   *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// List implements the chifra list command in-process.
+func (opts ListOptions) List() ([]types.Appearance, *types.MetaData, error) {
+	return queryModels[types.Appearance](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ListCount implements the chifra list --count command in-process.
+func (opts ListOptions) ListCount() ([]types.Monitor, *types.MetaData, error) {
+	opts.Count = true
+	return queryModels[types.Monitor](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ListBounds implements the chifra list --bounds command in-process.
+func (opts ListOptions) ListBounds() ([]types.Bounds, *types.MetaData, error) {
+	opts.Bounds = true
+	return queryModels[types.Bounds](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ListStream is the streaming version of List. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ListOptions) ListStream() Seq2[types.Appearance] {
+	return streamModels[types.Appearance](opts.RenderCtx, opts.run)
+}
+
+// ListCountStream is the streaming version of ListCount. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ListOptions) ListCountStream() Seq2[types.Monitor] {
+	opts.Count = true
+	return streamModels[types.Monitor](opts.RenderCtx, opts.run)
+}
+
+// ListBoundsStream is the streaming version of ListBounds. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts ListOptions) ListBoundsStream() Seq2[types.Bounds] {
+	opts.Bounds = true
+	return streamModels[types.Bounds](opts.RenderCtx, opts.run)
+}
+
+// run invokes chifra list in-process using the given render context.
+func (opts ListOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	list.ResetOptions(sdkTestMode)
+	in := list.ListFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.ListInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
@@ -0,0 +1,58 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	logs "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/logs"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// LogsOptions provides typed, in-process access to the chifra logs command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type LogsOptions struct {
+	TransactionIds []string          `json:"transactions,omitempty"`
+	Emitter        []string          `json:"emitter,omitempty"`
+	Topic          []string          `json:"topic,omitempty"`
+	Articulate     bool              `json:"articulate,omitempty"`
+	RenderCtx      *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// Logs implements the chifra logs command in-process.
+func (opts LogsOptions) Logs() ([]types.Log, *types.MetaData, error) {
+	return queryModels[types.Log](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// LogsStream is the streaming version of Logs. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts LogsOptions) LogsStream() Seq2[types.Log] {
+	return streamModels[types.Log](opts.RenderCtx, opts.run)
+}
+
+// run invokes chifra logs in-process using the given render context.
+func (opts LogsOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	logs.ResetOptions(sdkTestMode)
+	in := logs.LogsFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.LogsInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
@@ -0,0 +1,148 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// Globals are the global options available to each of the typed (in-process) SDK commands.
+type Globals struct {
+	Ether   bool   `json:"ether,omitempty"`
+	Cache   bool   `json:"cache,omitempty"`
+	Decache bool   `json:"decache,omitempty"`
+	Verbose bool   `json:"verbose,omitempty"`
+	Chain   string `json:"chain,omitempty"`
+}
+
+// queryModels runs a command in-process against a streaming render context and collects the
+// types.Modeler values sent down the command's fetchData channels. Nothing is written as or
+// parsed from JSON. If rCtx is nil, a new one is created. Models of types other than T are
+// ignored. Non-fatal errors sent by the command are joined and returned alongside the results.
+func queryModels[T any](rCtx *output.RenderCtx, chain string, runFunc func(*output.RenderCtx) error) ([]T, *types.MetaData, error) {
+	streamCtx := newStreamingContext(rCtx)
+	defer streamCtx.Cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- runFunc(streamCtx)
+	}()
+
+	ret := make([]T, 0)
+	errs := make([]error, 0)
+	for {
+		select {
+		case model := <-streamCtx.ModelChan:
+			if v, ok := asModel[T](model); ok {
+				ret = append(ret, v)
+			}
+		case err := <-streamCtx.ErrorChan:
+			errs = append(errs, err)
+		case err := <-done:
+			if err != nil {
+				return nil, nil, err
+			}
+			meta, err := getMetaData(chain)
+			if err != nil {
+				return ret, nil, err
+			}
+			return ret, meta, errors.Join(errs...)
+		}
+	}
+}
+
+// newStreamingContext returns a streaming render context. If the caller provided a render
+// context, the new context is derived from it, so cancelling the caller's context cancels
+// the command, but the command cancelling itself (which it does on some errors) does not
+// cancel the caller.
+func newStreamingContext(rCtx *output.RenderCtx) *output.RenderCtx {
+	ret := output.NewStreamingContext()
+	if rCtx != nil && rCtx.Ctx != nil {
+		ret.Cancel()
+		ret.Ctx, ret.Cancel = context.WithCancel(rCtx.Ctx)
+	}
+	return ret
+}
+
+// asModel returns the model as a T if it is a T (or a pointer to one)
+func asModel[T any](model types.Modeler) (T, bool) {
+	switch v := any(model).(type) {
+	case *T:
+		return *v, true
+	case T:
+		return v, true
+	}
+	var zero T
+	return zero, false
+}
+
+// getMetaData returns the meta data for the given chain (or the default chain if empty)
+func getMetaData(chain string) (*types.MetaData, error) {
+	if len(chain) == 0 {
+		chain = config.GetSettings().DefaultChain
+	}
+	conn := rpc.TempConnection(chain)
+	return conn.GetMetaData(sdkTestMode)
+}
+
+// structToValues converts an options struct into the url.Values the internal packages expect.
+// The keys are taken from the fields' json tags. Zero values are skipped, so each command's
+// defaults apply to any option left unset.
+func structToValues(opts any) (url.Values, error) {
+	values := url.Values{}
+	if err := addStructValues(reflect.ValueOf(opts), values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func addStructValues(v reflect.Value, values url.Values) error {
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("structToValues: expected a struct, got %s", v.Kind())
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		if field.Anonymous {
+			if err := addStructValues(value, values); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := strings.Split(field.Tag.Get("json"), ",")[0]
+		if key == "" || key == "-" || value.IsZero() {
+			continue
+		}
+
+		switch value.Kind() {
+		case reflect.Bool:
+			values.Set(key, "true")
+		case reflect.Slice:
+			for j := 0; j < value.Len(); j++ {
+				values.Add(key, fmt.Sprint(value.Index(j).Interface()))
+			}
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			values.Set(key, fmt.Sprint(value.Interface()))
+		default:
+			return fmt.Errorf("structToValues: unsupported type %s for field %s", value.Kind(), field.Name)
+		}
+	}
+	return nil
+}
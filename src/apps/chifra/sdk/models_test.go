@@ -0,0 +1,52 @@
+package sdk
+
+import (
+	"testing"
+)
+
+func TestStructToValues(t *testing.T) {
+	opts := ExportOptions{
+		Addrs:      []string{"0x1", "0x2"},
+		Logs:       true,
+		MaxRecords: 10,
+		Flow:       "in",
+		LastBlock:  2000,
+		Globals: Globals{
+			Chain: "sepolia",
+			Ether: true,
+		},
+	}
+
+	values, err := structToValues(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"addrs":      {"0x1", "0x2"},
+		"logs":       {"true"},
+		"maxRecords": {"10"},
+		"flow":       {"in"},
+		"lastBlock":  {"2000"},
+		"chain":      {"sepolia"},
+		"ether":      {"true"},
+	}
+	if len(values) != len(expected) {
+		t.Fatal("wrong number of values", len(values), values)
+	}
+	for key, want := range expected {
+		got := values[key]
+		if len(got) != len(want) {
+			t.Fatal("wrong values for", key, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatal("wrong value for", key, got[i], want[i])
+			}
+		}
+	}
+
+	if _, err := structToValues("not a struct"); err == nil {
+		t.Fatal("expected an error for a non-struct")
+	}
+}
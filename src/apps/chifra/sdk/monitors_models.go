@@ -0,0 +1,80 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	monitors "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/monitors"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// MonitorsOptions provides typed, in-process access to the chifra monitors command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type MonitorsOptions struct {
+	Addrs     []string          `json:"addrs,omitempty"`
+	Delete    bool              `json:"delete,omitempty"`
+	Undelete  bool              `json:"undelete,omitempty"`
+	Remove    bool              `json:"remove,omitempty"`
+	Clean     bool              `json:"clean,omitempty"`
+	List      bool              `json:"list,omitempty"`
+	Count     bool              `json:"count,omitempty"`
+	Staged    bool              `json:"staged,omitempty"`
+	Watch     bool              `json:"watch,omitempty"`
+	Watchlist string            `json:"watchlist,omitempty"`
+	Commands  string            `json:"commands,omitempty"`
+	BatchSize uint64            `json:"batchSize,omitempty"`
+	RunCount  uint64            `json:"runCount,omitempty"`
+	Sleep     float64           `json:"sleep,omitempty"`
+	RenderCtx *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// Monitors implements the chifra monitors command in-process.
+func (opts MonitorsOptions) Monitors() ([]types.Message, *types.MetaData, error) {
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// MonitorsClean implements the chifra monitors --clean command in-process.
+func (opts MonitorsOptions) MonitorsClean() ([]types.MonitorClean, *types.MetaData, error) {
+	opts.Clean = true
+	return queryModels[types.MonitorClean](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// MonitorsList implements the chifra monitors --list command in-process.
+func (opts MonitorsOptions) MonitorsList() ([]types.Monitor, *types.MetaData, error) {
+	opts.List = true
+	return queryModels[types.Monitor](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// MonitorsCount implements the chifra monitors --count command in-process.
+func (opts MonitorsOptions) MonitorsCount() ([]types.Count, *types.MetaData, error) {
+	opts.Count = true
+	return queryModels[types.Count](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra monitors in-process using the given render context.
+func (opts MonitorsOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	monitors.ResetOptions(sdkTestMode)
+	in := monitors.MonitorsFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.MonitorsInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
@@ -0,0 +1,119 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	names "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/names"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// NamesOptions provides typed, in-process access to the chifra names command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type NamesOptions struct {
+	Terms     []string          `json:"terms,omitempty"`
+	Expand    bool              `json:"expand,omitempty"`
+	MatchCase bool              `json:"matchCase,omitempty"`
+	All       bool              `json:"all,omitempty"`
+	Custom    bool              `json:"custom,omitempty"`
+	Prefund   bool              `json:"prefund,omitempty"`
+	Addr      bool              `json:"addr,omitempty"`
+	Tags      bool              `json:"tags,omitempty"`
+	Clean     bool              `json:"clean,omitempty"`
+	Regular   bool              `json:"regular,omitempty"`
+	DryRun    bool              `json:"dryRun,omitempty"`
+	Autoname  string            `json:"autoname,omitempty"`
+	Create    bool              `json:"create,omitempty"`
+	Update    bool              `json:"update,omitempty"`
+	Delete    bool              `json:"delete,omitempty"`
+	Undelete  bool              `json:"undelete,omitempty"`
+	Remove    bool              `json:"remove,omitempty"`
+	RenderCtx *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// Names implements the chifra names command in-process.
+func (opts NamesOptions) Names() ([]types.Name, *types.MetaData, error) {
+	return queryModels[types.Name](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// NamesAddr implements the chifra names --addr command in-process.
+func (opts NamesOptions) NamesAddr() ([]types.Name, *types.MetaData, error) {
+	opts.Addr = true
+	return queryModels[types.Name](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// NamesTags implements the chifra names --tags command in-process.
+func (opts NamesOptions) NamesTags() ([]types.Name, *types.MetaData, error) {
+	opts.Tags = true
+	return queryModels[types.Name](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// NamesClean implements the chifra names --clean command in-process.
+func (opts NamesOptions) NamesClean() ([]types.Message, *types.MetaData, error) {
+	opts.Clean = true
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// NamesAutoname implements the chifra names --autoname command in-process.
+func (opts NamesOptions) NamesAutoname(val string) ([]types.Message, *types.MetaData, error) {
+	opts.Autoname = val
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// NamesCreate implements the chifra names --create command in-process.
+func (opts NamesOptions) NamesCreate() ([]types.Name, *types.MetaData, error) {
+	opts.Create = true
+	return queryModels[types.Name](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// NamesUpdate implements the chifra names --update command in-process.
+func (opts NamesOptions) NamesUpdate() ([]types.Name, *types.MetaData, error) {
+	opts.Update = true
+	return queryModels[types.Name](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// NamesDelete implements the chifra names --delete command in-process.
+func (opts NamesOptions) NamesDelete() ([]types.Name, *types.MetaData, error) {
+	opts.Delete = true
+	return queryModels[types.Name](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// NamesUndelete implements the chifra names --undelete command in-process.
+func (opts NamesOptions) NamesUndelete() ([]types.Name, *types.MetaData, error) {
+	opts.Undelete = true
+	return queryModels[types.Name](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// NamesRemove implements the chifra names --remove command in-process.
+func (opts NamesOptions) NamesRemove() ([]types.Name, *types.MetaData, error) {
+	opts.Remove = true
+	return queryModels[types.Name](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra names in-process using the given render context.
+func (opts NamesOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	names.ResetOptions(sdkTestMode)
+	in := names.NamesFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.NamesInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
@@ -0,0 +1,50 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	receipts "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/receipts"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// ReceiptsOptions provides typed, in-process access to the chifra receipts command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type ReceiptsOptions struct {
+	TransactionIds []string          `json:"transactions,omitempty"`
+	Articulate     bool              `json:"articulate,omitempty"`
+	RenderCtx      *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// Receipts implements the chifra receipts command in-process.
+func (opts ReceiptsOptions) Receipts() ([]types.Receipt, *types.MetaData, error) {
+	return queryModels[types.Receipt](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra receipts in-process using the given render context.
+func (opts ReceiptsOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	receipts.ResetOptions(sdkTestMode)
+	in := receipts.ReceiptsFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.ReceiptsInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
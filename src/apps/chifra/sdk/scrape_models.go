@@ -0,0 +1,69 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	scrape "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/scrape"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	// EXISTING_CODE
+)
+
+// ScrapeOptions provides typed, in-process access to the chifra scrape command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type ScrapeOptions struct {
+	BlockCnt  uint64            `json:"blockCnt,omitempty"`
+	Sleep     float64           `json:"sleep,omitempty"`
+	Publisher string            `json:"publisher,omitempty"`
+	Touch     base.Blknum       `json:"touch,omitempty"`
+	RunCount  uint64            `json:"runCount,omitempty"`
+	DryRun    bool              `json:"dryRun,omitempty"`
+	Notify    bool              `json:"notify,omitempty"`
+	RenderCtx *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// ScrapeTouch implements the chifra scrape --touch command in-process.
+func (opts ScrapeOptions) ScrapeTouch(val base.Blknum) ([]types.Message, *types.MetaData, error) {
+	opts.Touch = val
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ScrapeRunCount implements the chifra scrape --runcount command in-process.
+func (opts ScrapeOptions) ScrapeRunCount(val uint64) ([]types.Message, *types.MetaData, error) {
+	opts.RunCount = val
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// ScrapeDryRun implements the chifra scrape --dryrun command in-process.
+func (opts ScrapeOptions) ScrapeDryRun() ([]types.Message, *types.MetaData, error) {
+	opts.DryRun = true
+	return queryModels[types.Message](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra scrape in-process using the given render context.
+func (opts ScrapeOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	scrape.ResetOptions(sdkTestMode)
+	in := scrape.ScrapeFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.ScrapeInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
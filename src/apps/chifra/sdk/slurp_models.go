@@ -0,0 +1,71 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	slurp "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/slurp"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// SlurpOptions provides typed, in-process access to the chifra slurp command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type SlurpOptions struct {
+	Addrs       []string          `json:"addrs,omitempty"`
+	BlockIds    []string          `json:"blocks,omitempty"`
+	Parts       []string          `json:"parts,omitempty"`
+	Appearances bool              `json:"appearances,omitempty"`
+	Articulate  bool              `json:"articulate,omitempty"`
+	Source      string            `json:"source,omitempty"`
+	Count       bool              `json:"count,omitempty"`
+	Page        uint64            `json:"page,omitempty"`
+	PageId      string            `json:"pageId,omitempty"`
+	PerPage     uint64            `json:"perPage,omitempty"`
+	Sleep       float64           `json:"sleep,omitempty"`
+	RenderCtx   *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// Slurp implements the chifra slurp command in-process.
+func (opts SlurpOptions) Slurp() ([]types.Slurp, *types.MetaData, error) {
+	return queryModels[types.Slurp](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// SlurpAppearances implements the chifra slurp --appearances command in-process.
+func (opts SlurpOptions) SlurpAppearances() ([]types.Appearance, *types.MetaData, error) {
+	opts.Appearances = true
+	return queryModels[types.Appearance](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// SlurpCount implements the chifra slurp --count command in-process.
+func (opts SlurpOptions) SlurpCount() ([]types.Monitor, *types.MetaData, error) {
+	opts.Count = true
+	return queryModels[types.Monitor](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra slurp in-process using the given render context.
+func (opts SlurpOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	slurp.ResetOptions(sdkTestMode)
+	in := slurp.SlurpFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.SlurpInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
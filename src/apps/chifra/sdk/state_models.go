@@ -0,0 +1,62 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	state "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/state"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// StateOptions provides typed, in-process access to the chifra state command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type StateOptions struct {
+	Addrs      []string          `json:"addrs,omitempty"`
+	BlockIds   []string          `json:"blocks,omitempty"`
+	Parts      []string          `json:"parts,omitempty"`
+	Changes    bool              `json:"changes,omitempty"`
+	NoZero     bool              `json:"noZero,omitempty"`
+	Call       string            `json:"call,omitempty"`
+	Articulate bool              `json:"articulate,omitempty"`
+	ProxyFor   string            `json:"proxyFor,omitempty"`
+	RenderCtx  *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// State implements the chifra state command in-process.
+func (opts StateOptions) State() ([]types.State, *types.MetaData, error) {
+	return queryModels[types.State](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StateCall implements the chifra state --call command in-process.
+func (opts StateOptions) StateCall(val string) ([]types.Result, *types.MetaData, error) {
+	opts.Call = val
+	return queryModels[types.Result](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra state in-process using the given render context.
+func (opts StateOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	state.ResetOptions(sdkTestMode)
+	in := state.StateFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.StateInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
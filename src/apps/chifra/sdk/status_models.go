@@ -0,0 +1,175 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	status "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/status"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// StatusOptions provides typed, in-process access to the chifra status command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type StatusOptions struct {
+	Modes       []string          `json:"modes,omitempty"`
+	Diagnose    bool              `json:"diagnose,omitempty"`
+	FirstRecord uint64            `json:"firstRecord,omitempty"`
+	MaxRecords  uint64            `json:"maxRecords,omitempty"`
+	Chains      bool              `json:"chains,omitempty"`
+	Healthcheck bool              `json:"healthcheck,omitempty"`
+	RenderCtx   *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// StatusIndex implements the chifra status index command in-process.
+func (opts StatusOptions) StatusIndex() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"index"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusBlooms implements the chifra status blooms command in-process.
+func (opts StatusOptions) StatusBlooms() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"blooms"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusBlocks implements the chifra status blocks command in-process.
+func (opts StatusOptions) StatusBlocks() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"blocks"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusTransactions implements the chifra status transactions command in-process.
+func (opts StatusOptions) StatusTransactions() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"transactions"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusTraces implements the chifra status traces command in-process.
+func (opts StatusOptions) StatusTraces() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"traces"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusLogs implements the chifra status logs command in-process.
+func (opts StatusOptions) StatusLogs() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"logs"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusStatements implements the chifra status statements command in-process.
+func (opts StatusOptions) StatusStatements() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"statements"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusResults implements the chifra status results command in-process.
+func (opts StatusOptions) StatusResults() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"results"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusState implements the chifra status state command in-process.
+func (opts StatusOptions) StatusState() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"state"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusTokens implements the chifra status tokens command in-process.
+func (opts StatusOptions) StatusTokens() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"tokens"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusMonitors implements the chifra status monitors command in-process.
+func (opts StatusOptions) StatusMonitors() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"monitors"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusNames implements the chifra status names command in-process.
+func (opts StatusOptions) StatusNames() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"names"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusAbis implements the chifra status abis command in-process.
+func (opts StatusOptions) StatusAbis() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"abis"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusSlurps implements the chifra status slurps command in-process.
+func (opts StatusOptions) StatusSlurps() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"slurps"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusStaging implements the chifra status staging command in-process.
+func (opts StatusOptions) StatusStaging() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"staging"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusUnripe implements the chifra status unripe command in-process.
+func (opts StatusOptions) StatusUnripe() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"unripe"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusMaps implements the chifra status maps command in-process.
+func (opts StatusOptions) StatusMaps() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"maps"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusSome implements the chifra status some command in-process.
+func (opts StatusOptions) StatusSome() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"some"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusAll implements the chifra status all command in-process.
+func (opts StatusOptions) StatusAll() ([]types.Status, *types.MetaData, error) {
+	opts.Modes = []string{"all"}
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusDiagnose implements the chifra status --diagnose command in-process.
+func (opts StatusOptions) StatusDiagnose() ([]types.Status, *types.MetaData, error) {
+	opts.Diagnose = true
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// StatusHealthcheck implements the chifra status --healthcheck command in-process.
+func (opts StatusOptions) StatusHealthcheck() ([]types.Status, *types.MetaData, error) {
+	opts.Healthcheck = true
+	return queryModels[types.Status](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra status in-process using the given render context.
+func (opts StatusOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	status.ResetOptions(sdkTestMode)
+	in := status.StatusFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.StatusInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
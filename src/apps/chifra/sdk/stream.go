@@ -0,0 +1,61 @@
+package sdk
+
+import (
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+)
+
+// Seq2 is a streaming sequence of models. It has the same shape as Go 1.23's iter.Seq2[T, error]
+// (and may be used with range over func in code built with Go 1.23 or later), but it's defined
+// here so the module continues to build with Go 1.22. On older versions, call it directly:
+//
+//	opts.ExportAppearancesStream()(func(app types.Appearance, err error) bool {
+//		...
+//		return true // return false to stop
+//	})
+//
+// A non-nil error is yielded (with a zero model) for each error the command reports. Most such
+// errors are not fatal and the stream continues.
+type Seq2[T any] func(yield func(T, error) bool)
+
+// streamModels returns a sequence that runs a command in-process and yields its models of type
+// T as they are produced. Nothing is buffered. If the caller stops early (yield returns false)
+// or cancels rCtx, the command's context is cancelled and the remainder of its output is drained
+// (and dropped) until its fetchData goroutines notice the cancel and return.
+func streamModels[T any](rCtx *output.RenderCtx, runFunc func(*output.RenderCtx) error) Seq2[T] {
+	return func(yield func(T, error) bool) {
+		streamCtx := newStreamingContext(rCtx)
+		defer streamCtx.Cancel()
+
+		done := make(chan error)
+		go func() {
+			done <- runFunc(streamCtx)
+		}()
+
+		var zero T
+		stopped := false
+		for {
+			select {
+			case model := <-streamCtx.ModelChan:
+				if v, ok := asModel[T](model); ok && !stopped {
+					stopped = !yield(v, nil)
+				}
+			case err := <-streamCtx.ErrorChan:
+				if !stopped {
+					stopped = !yield(zero, err)
+				}
+			case err := <-done:
+				if err != nil && !stopped && !streamCtx.WasCanceled() {
+					yield(zero, err)
+				}
+				return
+			}
+
+			if !stopped && rCtx != nil && rCtx.Ctx != nil && rCtx.WasCanceled() {
+				stopped = true
+			}
+			if stopped {
+				streamCtx.Cancel()
+			}
+		}
+	}
+}
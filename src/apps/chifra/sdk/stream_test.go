@@ -0,0 +1,99 @@
+package sdk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// countingRun returns a run function that streams n appearances (sending an error half way
+// through) and reports on finished when its fetchData returns.
+func countingRun(n int, finished chan<- int) func(*output.RenderCtx) error {
+	return func(rCtx *output.RenderCtx) error {
+		fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
+			sent := 0
+			defer func() { finished <- sent }()
+			for i := 0; i < n; i++ {
+				if rCtx.WasCanceled() {
+					return
+				}
+				if i == n/2 {
+					errorChan <- errors.New("non-fatal")
+				}
+				modelChan <- &types.Appearance{BlockNumber: uint32(i)}
+				sent++
+			}
+		}
+		return output.StreamMany(rCtx, fetchData, output.OutputOptions{})
+	}
+}
+
+func TestStreamModels(t *testing.T) {
+	finished := make(chan int, 1)
+	nModels, nErrors := 0, 0
+	streamModels[types.Appearance](nil, countingRun(10, finished))(func(app types.Appearance, err error) bool {
+		if err != nil {
+			nErrors++
+			return true
+		}
+		if base.Blknum(app.BlockNumber) != base.Blknum(nModels) {
+			t.Fatal("models out of order", app.BlockNumber, nModels)
+		}
+		nModels++
+		return true
+	})
+	if nModels != 10 || nErrors != 1 {
+		t.Fatal("wrong counts", nModels, nErrors)
+	}
+	if sent := <-finished; sent != 10 {
+		t.Fatal("wrong number sent", sent)
+	}
+}
+
+func TestStreamModelsStop(t *testing.T) {
+	finished := make(chan int, 1)
+	nModels := 0
+	streamModels[types.Appearance](nil, countingRun(1000, finished))(func(app types.Appearance, err error) bool {
+		if err == nil {
+			nModels++
+		}
+		return nModels < 3
+	})
+	if nModels != 3 {
+		t.Fatal("yield called after stopping", nModels)
+	}
+
+	select {
+	case sent := <-finished:
+		if sent >= 1000 {
+			t.Fatal("fetchData did not notice the cancel", sent)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetchData never returned")
+	}
+}
+
+func TestStreamModelsCancel(t *testing.T) {
+	finished := make(chan int, 1)
+	rCtx := output.NewRenderContext()
+	nModels := 0
+	streamModels[types.Appearance](rCtx, countingRun(1000, finished))(func(app types.Appearance, err error) bool {
+		if err == nil {
+			nModels++
+		}
+		if nModels == 5 {
+			rCtx.Cancel()
+		}
+		return true
+	})
+	if nModels != 5 {
+		t.Fatal("yield called after cancel", nModels)
+	}
+	if sent := <-finished; sent >= 1000 {
+		t.Fatal("fetchData did not notice the cancel", sent)
+	}
+}
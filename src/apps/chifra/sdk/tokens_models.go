@@ -0,0 +1,54 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	tokens "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/tokens"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// TokensOptions provides typed, in-process access to the chifra tokens command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type TokensOptions struct {
+	Addrs     []string          `json:"addrs,omitempty"`
+	BlockIds  []string          `json:"blocks,omitempty"`
+	Parts     []string          `json:"parts,omitempty"`
+	ByAcct    bool              `json:"byAcct,omitempty"`
+	Changes   bool              `json:"changes,omitempty"`
+	NoZero    bool              `json:"noZero,omitempty"`
+	RenderCtx *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// Tokens implements the chifra tokens command in-process.
+func (opts TokensOptions) Tokens() ([]types.Token, *types.MetaData, error) {
+	return queryModels[types.Token](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra tokens in-process using the given render context.
+func (opts TokensOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	tokens.ResetOptions(sdkTestMode)
+	in := tokens.TokensFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.TokensInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
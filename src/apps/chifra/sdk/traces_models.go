@@ -0,0 +1,71 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	traces "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/traces"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// TracesOptions provides typed, in-process access to the chifra traces command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type TracesOptions struct {
+	TransactionIds []string          `json:"transactions,omitempty"`
+	Articulate     bool              `json:"articulate,omitempty"`
+	Filter         string            `json:"filter,omitempty"`
+	Count          bool              `json:"count,omitempty"`
+	RenderCtx      *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// Traces implements the chifra traces command in-process.
+func (opts TracesOptions) Traces() ([]types.Trace, *types.MetaData, error) {
+	return queryModels[types.Trace](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// TracesCount implements the chifra traces --count command in-process.
+func (opts TracesOptions) TracesCount() ([]types.TraceCount, *types.MetaData, error) {
+	opts.Count = true
+	return queryModels[types.TraceCount](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// TracesStream is the streaming version of Traces. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts TracesOptions) TracesStream() Seq2[types.Trace] {
+	return streamModels[types.Trace](opts.RenderCtx, opts.run)
+}
+
+// TracesCountStream is the streaming version of TracesCount. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts TracesOptions) TracesCountStream() Seq2[types.TraceCount] {
+	opts.Count = true
+	return streamModels[types.TraceCount](opts.RenderCtx, opts.run)
+}
+
+// run invokes chifra traces in-process using the given render context.
+func (opts TracesOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	traces.ResetOptions(sdkTestMode)
+	in := traces.TracesFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.TracesInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
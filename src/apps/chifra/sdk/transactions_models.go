@@ -0,0 +1,75 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	transactions "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/transactions"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+// TransactionsOptions provides typed, in-process access to the chifra transactions command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type TransactionsOptions struct {
+	TransactionIds []string          `json:"transactions,omitempty"`
+	Articulate     bool              `json:"articulate,omitempty"`
+	Traces         bool              `json:"traces,omitempty"`
+	Uniq           bool              `json:"uniq,omitempty"`
+	Flow           string            `json:"flow,omitempty"`
+	Logs           bool              `json:"logs,omitempty"`
+	Emitter        []string          `json:"emitter,omitempty"`
+	Topic          []string          `json:"topic,omitempty"`
+	CacheTraces    bool              `json:"cacheTraces,omitempty"`
+	RenderCtx      *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// Transactions implements the chifra transactions command in-process.
+func (opts TransactionsOptions) Transactions() ([]types.Transaction, *types.MetaData, error) {
+	return queryModels[types.Transaction](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// TransactionsTraces implements the chifra transactions --traces command in-process.
+func (opts TransactionsOptions) TransactionsTraces() ([]types.Trace, *types.MetaData, error) {
+	opts.Traces = true
+	return queryModels[types.Trace](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// TransactionsUniq implements the chifra transactions --uniq command in-process.
+func (opts TransactionsOptions) TransactionsUniq() ([]types.Appearance, *types.MetaData, error) {
+	opts.Uniq = true
+	return queryModels[types.Appearance](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// TransactionsLogs implements the chifra transactions --logs command in-process.
+func (opts TransactionsOptions) TransactionsLogs() ([]types.Log, *types.MetaData, error) {
+	opts.Logs = true
+	return queryModels[types.Log](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra transactions in-process using the given render context.
+func (opts TransactionsOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	transactions.ResetOptions(sdkTestMode)
+	in := transactions.TransactionsFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.TransactionsInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
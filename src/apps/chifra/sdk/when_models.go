@@ -0,0 +1,76 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package sdk
+
+import (
+	"io"
+
+	when "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/when"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	// EXISTING_CODE
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	// EXISTING_CODE
+)
+
+// WhenOptions provides typed, in-process access to the chifra when command. Its
+// endpoints return the models produced by the command instead of writing JSON.
+type WhenOptions struct {
+	BlockIds   []string          `json:"blocks,omitempty"`
+	List       bool              `json:"list,omitempty"`
+	Timestamps bool              `json:"timestamps,omitempty"`
+	Count      bool              `json:"count,omitempty"`
+	Truncate   base.Blknum       `json:"truncate,omitempty"`
+	Repair     bool              `json:"repair,omitempty"`
+	Check      bool              `json:"check,omitempty"`
+	Update     bool              `json:"update,omitempty"`
+	Deep       bool              `json:"deep,omitempty"`
+	RenderCtx  *output.RenderCtx `json:"-"`
+	Globals
+}
+
+// When implements the chifra when command in-process.
+func (opts WhenOptions) When() ([]types.NamedBlock, *types.MetaData, error) {
+	return queryModels[types.NamedBlock](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// WhenList implements the chifra when --list command in-process.
+func (opts WhenOptions) WhenList() ([]types.NamedBlock, *types.MetaData, error) {
+	opts.List = true
+	return queryModels[types.NamedBlock](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// WhenTimestamps implements the chifra when --timestamps command in-process.
+func (opts WhenOptions) WhenTimestamps() ([]types.Timestamp, *types.MetaData, error) {
+	opts.Timestamps = true
+	return queryModels[types.Timestamp](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// WhenCount implements the chifra when --count command in-process.
+func (opts WhenOptions) WhenCount() ([]types.Count, *types.MetaData, error) {
+	opts.Count = true
+	return queryModels[types.Count](opts.RenderCtx, opts.Chain, opts.run)
+}
+
+// run invokes chifra when in-process using the given render context.
+func (opts WhenOptions) run(rCtx *output.RenderCtx) error {
+	values, err := structToValues(opts)
+	if err != nil {
+		return err
+	}
+
+	when.ResetOptions(sdkTestMode)
+	in := when.WhenFinishParseInternal(io.Discard, values)
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return in.WhenInternal(rCtx)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
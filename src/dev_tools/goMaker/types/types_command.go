@@ -196,6 +196,8 @@ var globals = []Option{
 	{LongName: "decache", HotKey: "D", OptionType: "switch", Description: "removes related items from the cache", DataType: "boolean"},
 	{LongName: "ether", HotKey: "H", OptionType: "switch", Description: "export values in ether", DataType: "boolean"},
 	{LongName: "fmt", HotKey: "x", OptionType: "flag", Description: "export format, one of [ txt | csv | json ]", DataType: "string"},
+	{LongName: "fields", HotKey: "", OptionType: "flag", Description: "output only these comma-separated fields (or dotted paths or arithmetic expressions) in this order", DataType: "string"},
+	{LongName: "where", HotKey: "", OptionType: "flag", Description: "output only the records matching this expression (for example, 'value > 1e18 && to in @exchanges')", DataType: "string"},
 	// Not available for API or Python SDK:
 	// "append","file","names","noColor","noop","output","verbose","version","wei",
 }
@@ -647,6 +649,37 @@ func (c *Command) SdkEndpoints() string {
 	return strings.Join(ret, "\n")
 }
 
+func (c *Command) SdkModelsEndpoints() string {
+	ret := []string{}
+	for _, op := range c.Options {
+		if len(op.ReturnType) > 0 {
+			v := op.SdkModelsEndpoint()
+			if len(v) > 0 {
+				ret = append(ret, v)
+			}
+		}
+	}
+	return strings.Join(ret, "\n")
+}
+
+// IsStreaming returns true if the command's in-process SDK should include streaming endpoints
+func (c *Command) IsStreaming() bool {
+	return strings.Contains(c.Attributes, "streaming")
+}
+
+func (c *Command) SdkStreamEndpoints() string {
+	ret := []string{}
+	for _, op := range c.Options {
+		if len(op.ReturnType) > 0 {
+			v := op.SdkStreamEndpoint()
+			if len(v) > 0 {
+				ret = append(ret, v)
+			}
+		}
+	}
+	return strings.Join(ret, "\n")
+}
+
 // ----------------------------------------------------------------------
 func (c *Command) FuzzerSwitches() string {
 	ret := []string{}
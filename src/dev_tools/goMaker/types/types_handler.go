@@ -28,6 +28,7 @@ func (h *Handler) Test() string {
 	} else {
 		if h.Option.IsArray() ||
 			strings.Contains(h.Option.DataType, "string") ||
+			strings.Contains(h.Option.DataType, "enum") ||
 			strings.Contains(h.Option.DataType, "address") {
 			return "len(opts." + h.Name + ") > 0"
 		} else {
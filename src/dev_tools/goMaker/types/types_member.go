@@ -39,7 +39,8 @@ func (m *Member) LowerSingular() string {
 	if strings.HasSuffix(m.GoName(), "s") {
 		return strings.ToLower(m.GoName())[:len(m.GoName())-1]
 	}
-	return strings.ToLower(m.GoName())
+	// The plural and singular would collide (for example, AuthorizationList)
+	return "item"
 }
 
 func (m *Member) Container() string {
@@ -238,7 +239,8 @@ func (m *Member) MarshalCode() string {
 		m.GoName() != "Topics" &&
 		m.GoName() != "Transactions" &&
 		m.GoName() != "TraceAddress" &&
-		m.GoName() != "Uncles" {
+		m.GoName() != "Uncles" &&
+		m.GoName() != "BlobVersionedHashes" {
 		tmplName += "3"
 		tmpl = `// {{.GoName}}
 	{{.Lower}} := make([]cache.Marshaler, 0, len(s.{{.GoName}}))
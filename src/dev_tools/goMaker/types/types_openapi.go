@@ -0,0 +1,472 @@
+package types
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+)
+
+// The OpenAPI document served by the daemon (and used by it to validate requests). Unlike the
+// openapi.yaml file written for the documentation website, every parameter a route accepts
+// appears, including the hidden ones (marked x-hidden) and the globals the route allows.
+
+type openApi struct {
+	OpenApi    string                 `json:"openapi"`
+	Info       openApiInfo            `json:"info"`
+	Servers    []openApiServer        `json:"servers"`
+	Tags       []openApiTag           `json:"tags"`
+	Paths      map[string]openApiPath `json:"paths"`
+	Components openApiComponents      `json:"components"`
+}
+
+type openApiInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type openApiServer struct {
+	Url         string `json:"url"`
+	Description string `json:"description"`
+}
+
+type openApiTag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type openApiPath struct {
+	Get    *openApiOperation `json:"get,omitempty"`
+	Post   *openApiOperation `json:"post,omitempty"`
+	Delete *openApiOperation `json:"delete,omitempty"`
+}
+
+type openApiOperation struct {
+	Tags        []string                   `json:"tags"`
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description"`
+	OperationId string                     `json:"operationId"`
+	Parameters  []openApiParameter         `json:"parameters"`
+	RequestBody *openApiRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openApiResponse `json:"responses"`
+}
+
+type openApiRequestBody struct {
+	Description string                      `json:"description"`
+	Required    bool                        `json:"required"`
+	Content     map[string]openApiMediaType `json:"content"`
+}
+
+type openApiParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description"`
+	Required    bool           `json:"required"`
+	Deprecated  bool           `json:"deprecated,omitempty"`
+	Hidden      bool           `json:"x-hidden,omitempty"`
+	Schema      *openApiSchema `json:"schema"`
+}
+
+type openApiResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openApiMediaType `json:"content,omitempty"`
+}
+
+type openApiMediaType struct {
+	Schema *openApiSchema `json:"schema"`
+}
+
+type openApiSchema struct {
+	Ref         string                    `json:"$ref,omitempty"`
+	Type        string                    `json:"type,omitempty"`
+	Format      string                    `json:"format,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Enum        []string                  `json:"enum,omitempty"`
+	Items       *openApiSchema            `json:"items,omitempty"`
+	OneOf       []*openApiSchema          `json:"oneOf,omitempty"`
+	Properties  map[string]*openApiSchema `json:"properties,omitempty"`
+}
+
+type openApiComponents struct {
+	Schemas map[string]*openApiSchema `json:"schemas"`
+}
+
+// apiGlobals are the global options accepted by the API, in the order they are listed, along with
+// the capability that enables each (the format and input file options are accepted by every
+// route, see caps.HasKey). Those not shown in the documentation are hidden.
+var apiGlobals = []struct {
+	cap string
+	op  Option
+}{
+	{"chain", Option{LongName: "chain", Description: "the chain to use", DataType: "<string>", Attributes: "visible|docs"}},
+	{"noHeader", Option{LongName: "noHeader", Description: "suppress the header in the output", DataType: "<boolean>", Attributes: "visible|docs"}},
+	{"caching", Option{LongName: "cache", Description: "force the results of the query into the cache", DataType: "<boolean>", Attributes: "visible|docs"}},
+	{"caching", Option{LongName: "decache", Description: "removes related items from the cache", DataType: "<boolean>", Attributes: "visible|docs"}},
+	{"ether", Option{LongName: "ether", Description: "export values in ether", DataType: "<boolean>", Attributes: "visible|docs"}},
+	{"wei", Option{LongName: "wei", Description: "export values in wei (the default)", DataType: "<boolean>"}},
+	{"", Option{LongName: "fmt", Description: "export format", DataType: "enum", Enums: []string{"none", "json", "txt", "csv", "parquet", "sqlite"}, Attributes: "visible|docs"}},
+	{"fmt", Option{LongName: "fields", Description: "output only these comma-separated fields (or dotted paths or arithmetic expressions) in this order", DataType: "<string>", Attributes: "visible|docs"}},
+	{"fmt", Option{LongName: "where", Description: "output only the records matching this expression (for example, 'value > 1e18 && to in @exchanges')", DataType: "<string>", Attributes: "visible|docs"}},
+	{"", Option{LongName: "file", Description: "specify multiple sets of command line options in a file", DataType: "<string>"}},
+	{"output", Option{LongName: "output", Description: "write the results to this file on the server", DataType: "<string>"}},
+	{"append", Option{LongName: "append", Description: "append to the output file rather than replacing it", DataType: "<boolean>"}},
+	{"verbose", Option{LongName: "verbose", Description: "enable verbose output", DataType: "<boolean>"}},
+	{"version", Option{LongName: "version", Description: "display the current version of the tool", DataType: "<boolean>"}},
+	{"noop", Option{LongName: "noop", Description: "does nothing", DataType: "<boolean>"}},
+	{"noColor", Option{LongName: "nocolor", Description: "turn off colored output", DataType: "<boolean>"}},
+}
+
+// defaultCaps are the capabilities included in the default capability (see pkg/caps)
+var defaultCaps = []string{"verbose", "fmt", "version", "noop", "noColor", "chain", "noHeader", "file", "output", "append"}
+
+// OpenApiJson - returns the OpenAPI document describing every route of the API server
+func (cb *CodeBase) OpenApiJson() string {
+	sort.Slice(cb.Commands, func(i, j int) bool {
+		return cb.Commands[i].Num < cb.Commands[j].Num
+	})
+
+	doc := openApi{
+		OpenApi: "3.1.0",
+		Info: openApiInfo{
+			Title:       "TrueBlocks API",
+			Description: cb.Description(),
+			Version:     cb.Version(false),
+		},
+		Servers:    []openApiServer{{Url: "http://localhost:8080", Description: "Local endpoints"}},
+		Tags:       []openApiTag{},
+		Paths:      map[string]openApiPath{},
+		Components: openApiComponents{Schemas: cb.openApiSchemas()},
+	}
+
+	cur := ""
+	for _, c := range cb.Commands {
+		if cur != c.Group {
+			doc.Tags = append(doc.Tags, openApiTag{Name: c.Group, Description: c.Description})
+		}
+		cur = c.Group
+		// every command but the daemon itself is served (see the daemon's routes.go), including
+		// those not otherwise documented as part of the API
+		if c.Route != "" && c.Route != "daemon" {
+			op := c.openApiOperation()
+			doc.Paths["/"+c.Route] = openApiPath{Get: &op}
+		}
+	}
+
+	doc.Tags = append(doc.Tags, openApiTag{Name: serverTag, Description: "Jobs, notifications, and the other endpoints of the server itself."})
+	for path, item := range serverPaths() {
+		doc.Paths[path] = item
+	}
+
+	bytes, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		logger.Fatal(err)
+	}
+	return string(bytes)
+}
+
+// serverTag is the tag of the server's own endpoints
+const serverTag = "Server"
+
+// serverPaths returns the server's endpoints that do not run a command (see the EXISTING_CODE
+// section of the daemon's routes.go)
+func serverPaths() map[string]openApiPath {
+	jsonOf := func(schema *openApiSchema) map[string]openApiMediaType {
+		return map[string]openApiMediaType{"application/json": {Schema: schema}}
+	}
+	job := jsonOf(&openApiSchema{Ref: "#/components/schemas/job"})
+	failure := func(description string) openApiResponse {
+		return openApiResponse{Description: description, Content: jsonOf(&openApiSchema{Ref: "#/components/schemas/error"})}
+	}
+	operation := func(id, summary, description string, params []openApiParameter, responses map[string]openApiResponse) *openApiOperation {
+		if params == nil {
+			params = []openApiParameter{}
+		}
+		return &openApiOperation{
+			Tags:        []string{serverTag},
+			Summary:     summary,
+			Description: description,
+			OperationId: "server-" + id,
+			Parameters:  params,
+			Responses:   responses,
+		}
+	}
+	jobId := []openApiParameter{{Name: "id", In: "path", Description: "the id of the job", Required: true, Schema: &openApiSchema{Type: "string"}}}
+	watcher := map[string]openApiResponse{
+		"200": {Description: "returns the status of the monitor watcher", Content: jsonOf(&openApiSchema{
+			Type:       "object",
+			Properties: map[string]*openApiSchema{"data": {Type: "array", Items: &openApiSchema{Type: "object"}}},
+		})},
+		"404": failure("the monitor watcher is not running"),
+	}
+
+	notify := operation("notify", "Publish a notification",
+		"Publish a notification (such as the appearances found by a scraper running in another process) to the server's websocket clients.",
+		nil, map[string]openApiResponse{
+			"200": {Description: "the notification was published"},
+			"400": failure("the notification is invalid"),
+		})
+	notify.RequestBody = &openApiRequestBody{
+		Description: "the notification's message (appearance, reorg, chunkWritten, or stageUpdated), its metadata, and its payload",
+		Required:    true,
+		Content: jsonOf(&openApiSchema{
+			Type: "object",
+			Properties: map[string]*openApiSchema{
+				"msg":     {Type: "string", Enum: []string{"appearance", "reorg", "chunkWritten", "stageUpdated"}},
+				"meta":    {Type: "object"},
+				"payload": {},
+			},
+		}),
+	}
+
+	return map[string]openApiPath{
+		"/jobs": {
+			Post: operation("jobs-start", "Start a job",
+				"Run one of the GET routes in the background. The route's own parameters, which are validated as they would be for the route itself, are passed along with the route parameter.",
+				[]openApiParameter{{Name: "route", In: "query", Description: "the route to run (for example, export)", Required: true, Schema: &openApiSchema{Type: "string"}}},
+				map[string]openApiResponse{
+					"202": {Description: "the job was started (its location is in the Location header)", Content: job},
+					"400": failure("bad input parameter"),
+					"503": failure("jobs are not available"),
+				}),
+		},
+		"/jobs/{id}": {
+			Get: operation("jobs-status", "Get a job", "Return the job's description, including its progress.", jobId,
+				map[string]openApiResponse{
+					"200": {Description: "returns the job", Content: job},
+					"404": failure("the job was not found"),
+				}),
+			Delete: operation("jobs-cancel", "Cancel or delete a job", "Cancel a running job or delete a job that has ended (along with its result).", jobId,
+				map[string]openApiResponse{
+					"200": {Description: "returns the job", Content: job},
+					"404": failure("the job was not found"),
+				}),
+		},
+		"/jobs/{id}/result": {
+			Get: operation("jobs-result", "Get a job's result",
+				"Return the output of a job that has ended in the job's content type. Range requests are honored.", jobId,
+				map[string]openApiResponse{
+					"200": {Description: "returns the job's output"},
+					"206": {Description: "returns part of the job's output"},
+					"404": failure("the job was not found"),
+					"409": failure("the job has not ended"),
+				}),
+		},
+		"/websocket": {
+			Get: operation("websocket", "Open a websocket",
+				"Upgrade the connection to a websocket on which the client subscribes to the appearances of addresses and is told of reorgs.",
+				nil, map[string]openApiResponse{
+					"101": {Description: "the connection was upgraded"},
+				}),
+		},
+		"/monitor/pause": {
+			Post: operation("monitor-pause", "Pause the monitor watcher", "Pause the monitor watcher of a daemon started with --monitor.", nil, watcher),
+		},
+		"/monitor/resume": {
+			Post: operation("monitor-resume", "Resume the monitor watcher", "Resume the monitor watcher of a daemon started with --monitor.", nil, watcher),
+		},
+		"/notify": {
+			Post: notify,
+		},
+		"/openapi.json": {
+			Get: operation("openapi", "Get the OpenAPI document", "Return this document.", nil,
+				map[string]openApiResponse{
+					"200": {Description: "returns the OpenAPI document", Content: jsonOf(&openApiSchema{Type: "object"})},
+				}),
+		},
+	}
+}
+
+func (c *Command) openApiOperation() openApiOperation {
+	op := openApiOperation{
+		Tags:        []string{c.Group},
+		Summary:     c.Summary,
+		Description: c.Description,
+		OperationId: c.GroupName() + "-" + c.Route,
+		Parameters:  []openApiParameter{},
+		Responses: map[string]openApiResponse{
+			"200": {
+				Description: "returns the requested data",
+				Content: map[string]openApiMediaType{
+					"application/json": {Schema: &openApiSchema{
+						Type: "object",
+						Properties: map[string]*openApiSchema{
+							"data": {Type: "array", Items: c.openApiProduces()},
+						},
+					}},
+				},
+			},
+			"400": {
+				Description: "bad input parameter",
+				Content: map[string]openApiMediaType{
+					"application/json": {Schema: &openApiSchema{Ref: "#/components/schemas/error"}},
+				},
+			},
+		},
+	}
+
+	for i := range c.Options {
+		op.Parameters = append(op.Parameters, c.Options[i].openApiParameter())
+	}
+
+	has := map[string]bool{"": true}
+	for _, cap := range strings.Split(c.Capabilities, "|") {
+		if cap == "default" {
+			for _, d := range defaultCaps {
+				has[d] = true
+			}
+		} else if len(cap) > 0 {
+			has[cap] = true
+		}
+	}
+	for _, glob := range apiGlobals {
+		if has[glob.cap] {
+			op.Parameters = append(op.Parameters, glob.op.openApiParameter())
+		}
+	}
+
+	return op
+}
+
+func (c *Command) openApiProduces() *openApiSchema {
+	refs := []*openApiSchema{}
+	for _, production := range c.Productions {
+		refs = append(refs, &openApiSchema{Ref: "#/components/schemas/" + CamelCase(production.Class)})
+	}
+	switch len(refs) {
+	case 0:
+		return &openApiSchema{Type: "object"}
+	case 1:
+		return refs[0]
+	default:
+		return &openApiSchema{OneOf: refs}
+	}
+}
+
+func (op *Option) openApiParameter() openApiParameter {
+	return openApiParameter{
+		Name:        CamelCase(op.LongName),
+		In:          "query",
+		Description: op.Description,
+		Required:    op.IsRequired(),
+		Deprecated:  op.IsDeprecated(),
+		Hidden:      op.IsApiHidden(),
+		Schema:      op.openApiSchema(),
+	}
+}
+
+// openApiSchema returns the schema of the option's values, which the server uses to validate them
+func (op *Option) openApiSchema() *openApiSchema {
+	if op.DataType == "enum" {
+		return &openApiSchema{Type: "string", Enum: op.Enums}
+	} else if op.DataType == "list<enum>" {
+		return &openApiSchema{Type: "array", Items: &openApiSchema{Type: "string", Enum: op.Enums}}
+	} else if strings.HasPrefix(op.DataType, "list<") {
+		format := strings.TrimSuffix(strings.TrimPrefix(op.DataType, "list<"), ">")
+		return &openApiSchema{Type: "array", Items: &openApiSchema{Type: "string", Format: format}}
+	}
+
+	switch op.DataType {
+	case "<boolean>":
+		return &openApiSchema{Type: "boolean"}
+	case "<uint64>", "<blknum>":
+		return &openApiSchema{Type: "integer", Format: strings.Trim(op.DataType, "<>")}
+	case "<float64>":
+		return &openApiSchema{Type: "number", Format: "double"}
+	case "<address>":
+		return &openApiSchema{Type: "string", Format: "address"}
+	case "<string>":
+		return &openApiSchema{Type: "string"}
+	}
+	logger.Fatal("unknown data type", op.DataType, "for option", op.LongName)
+	return nil
+}
+
+// openApiSchemas returns the schemas of the data models produced by the routes
+func (cb *CodeBase) openApiSchemas() map[string]*openApiSchema {
+	schemas := map[string]*openApiSchema{
+		"error": {
+			Type: "object",
+			Properties: map[string]*openApiSchema{
+				"errors": {Type: "array", Items: &openApiSchema{Type: "string"}},
+				"invalid": {Type: "array", Items: &openApiSchema{
+					Type: "object",
+					Properties: map[string]*openApiSchema{
+						"parameter": {Type: "string"},
+						"value":     {Type: "string"},
+						"reason":    {Type: "string"},
+					},
+				}},
+			},
+		},
+		"job": {
+			Type:        "object",
+			Description: "a route run in the background by the server",
+			Properties: map[string]*openApiSchema{
+				"id":          {Type: "string"},
+				"route":       {Type: "string"},
+				"query":       {Type: "string"},
+				"state":       {Type: "string", Enum: []string{"running", "finished", "failed", "canceled"}},
+				"progress":    {Type: "object", Description: "the job's latest progress report"},
+				"status":      {Type: "integer", Description: "the route's status if it failed"},
+				"contentType": {Type: "string"},
+				"nBytes":      {Type: "integer", Format: "int64"},
+				"started":     {Type: "string", Format: "datetime"},
+				"ended":       {Type: "string", Format: "datetime"},
+				"error":       {Type: "string"},
+			},
+		},
+	}
+
+	for _, st := range cb.Structures {
+		if st.Class == "" {
+			continue
+		}
+		schema := &openApiSchema{
+			Type:        "object",
+			Description: st.DocDescr,
+			Properties:  map[string]*openApiSchema{},
+		}
+		for i := range st.Members {
+			m := &st.Members[i]
+			if m.DocOrder == 0 {
+				continue
+			}
+			prop := m.openApiSchema()
+			prop.Description = m.Description
+			if m.IsCalc() && len(m.Description) > 0 {
+				prop.Description += " (calculated)"
+			}
+			schema.Properties[m.Name] = prop
+		}
+		schemas[CamelCase(st.Class)] = schema
+	}
+	return schemas
+}
+
+func (m *Member) openApiSchema() *openApiSchema {
+	var ret *openApiSchema
+	if m.IsObject() {
+		ret = &openApiSchema{Ref: "#/components/schemas/" + CamelCase(m.Type)}
+	} else {
+		switch m.Type {
+		case "blknum", "txnum", "lognum", "timestamp", "gas", "uint64", "int64", "uint32", "int", "value":
+			ret = &openApiSchema{Type: "integer", Format: m.Type}
+		case "float", "float64":
+			ret = &openApiSchema{Type: "number", Format: m.Type}
+		case "bool", "uint8":
+			ret = &openApiSchema{Type: "boolean"}
+		case "address", "datetime", "hash", "ipfshash", "blkrange", "topic", "int256", "uint256", "wei", "bytes", "string", "ether":
+			ret = &openApiSchema{Type: "string", Format: m.Type}
+		case "any":
+			ret = &openApiSchema{}
+		default:
+			logger.Fatal("unknown type", m.Type, "in member", m.Name)
+		}
+	}
+	if m.IsArray {
+		return &openApiSchema{Type: "array", Items: ret}
+	}
+	return ret
+}
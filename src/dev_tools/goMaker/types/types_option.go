@@ -833,6 +833,91 @@ func (op *Option) SdkEndpoint() string {
 	return copy.executeTemplate(tmplName, tmpl)
 }
 
+var sdkModelsEndpoint = `// {{firstUpper .Route}}{{.GoName}} implements the chifra {{toLower .Route}} {{.ToolTurd}}command in-process.
+func (opts {{firstUpper .Route}}Options) {{firstUpper .Route}}{{.GoName}}({{.ModelsParameters}}) ([]{{.SdkCoreType}}, *types.MetaData, error) {
+{{if not .IsPositional}}	opts.{{.GoSdkName}} = {{.ModelsAssignment}}
+{{end}}	return queryModels[{{.SdkCoreType}}](opts.RenderCtx, opts.Chain, opts.run)
+}
+`
+
+var sdkStreamEndpoint = `// {{firstUpper .Route}}{{.GoName}}Stream is the streaming version of {{firstUpper .Route}}{{.GoName}}. Models are yielded
+// as the command produces them. Stopping early or cancelling opts.RenderCtx stops the command.
+func (opts {{firstUpper .Route}}Options) {{firstUpper .Route}}{{.GoName}}Stream({{.ModelsParameters}}) Seq2[{{.SdkCoreType}}] {
+{{if not .IsPositional}}	opts.{{.GoSdkName}} = {{.ModelsAssignment}}
+{{end}}	return streamModels[{{.SdkCoreType}}](opts.RenderCtx, opts.run)
+}
+`
+
+// SdkModelsEndpoint returns the in-process (typed) SDK endpoint(s) for this option. Modes
+// produce one endpoint per enum value.
+func (op *Option) SdkModelsEndpoint() string {
+	tmplName := "sdkModelsEndpoint"
+	tmpl := sdkModelsEndpoint
+
+	copy := *op
+	if op.IsMode() {
+		copy.OptionType = "" // not positional any longer
+		ret := []string{}
+		for _, enum := range op.Enums {
+			copy.GoName = FirstUpper(enum)
+			copy.DefVal = enum
+			ret = append(ret, copy.executeTemplate(tmplName, tmpl))
+		}
+		return strings.Join(ret, "\n")
+	}
+
+	if copy.IsPositional() {
+		copy.GoName = ""
+	}
+
+	return copy.executeTemplate(tmplName, tmpl)
+}
+
+// SdkStreamEndpoint returns the streaming version of the in-process SDK endpoint(s) for this option.
+func (op *Option) SdkStreamEndpoint() string {
+	tmplName := "sdkStreamEndpoint"
+	tmpl := sdkStreamEndpoint
+
+	copy := *op
+	if op.IsMode() {
+		copy.OptionType = "" // not positional any longer
+		ret := []string{}
+		for _, enum := range op.Enums {
+			copy.GoName = FirstUpper(enum)
+			copy.DefVal = enum
+			ret = append(ret, copy.executeTemplate(tmplName, tmpl))
+		}
+		return strings.Join(ret, "\n")
+	}
+
+	if copy.IsPositional() {
+		copy.GoName = ""
+	}
+
+	return copy.executeTemplate(tmplName, tmpl)
+}
+
+// ModelsParameters returns the parameter list for an in-process SDK endpoint
+func (op *Option) ModelsParameters() string {
+	if op.IsPositional() || op.IsMode() || op.GoOptionsType == "bool" {
+		return ""
+	}
+	return "val " + op.GoOptionsType
+}
+
+// ModelsAssignment returns the value an in-process SDK endpoint assigns to its option
+func (op *Option) ModelsAssignment() string {
+	if op.IsMode() {
+		if op.IsArray() {
+			return "[]string{\"" + op.DefVal + "\"}"
+		}
+		return "\"" + op.DefVal + "\""
+	} else if op.GoOptionsType == "bool" {
+		return "true"
+	}
+	return "val"
+}
+
 func (op *Option) SdkCoreType() string {
 	v := op.ReturnType
 	if v == "mode" {
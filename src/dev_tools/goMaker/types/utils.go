@@ -68,6 +68,7 @@ func convertToDestPath(source, routeTag, typeTag, groupTag, reason string) strin
 	dest = strings.ReplaceAll(dest, ".tmpl", "")
 	dest = strings.ReplaceAll(dest, "_route_", "/"+routeTag+"/")
 	dest = strings.ReplaceAll(dest, "route+internal", routeTag+"+internal")
+	dest = strings.ReplaceAll(dest, "route+models", routeTag+"+models")
 	dest = strings.ReplaceAll(dest, "route.go", routeTag+".go")
 	dest = strings.ReplaceAll(dest, "route.md", routeTag+".md")
 	dest = strings.ReplaceAll(dest, "route.py", routeTag+".py")
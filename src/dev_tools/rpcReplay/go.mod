@@ -0,0 +1,13 @@
+module github.com/TrueBlocks/trueblocks-core/rpcReplay
+
+// Go Version
+go 1.22
+
+replace github.com/TrueBlocks/trueblocks-core/src/apps/chifra => ../../apps/chifra
+
+require github.com/TrueBlocks/trueblocks-core/src/apps/chifra v0.0.0-20241029040126-dfdcbfaef4e9
+
+require (
+	golang.org/x/sys v0.22.0 // indirect
+	golang.org/x/term v0.22.0 // indirect
+)
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/replay"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8545", "the address on which to serve JSON-RPC requests")
+	record := flag.String("record", "", "record the node's responses to fixtures in this folder")
+	play := flag.String("replay", "", "answer from the fixtures in this folder without contacting a node")
+	upstream := flag.String("upstream", "", "the node to which requests are forwarded while recording")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: rpcReplay (--record <folder> --upstream <url> | --replay <folder>) [--addr <host:port>]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var transport *replay.Transport
+	var err error
+	switch {
+	case len(*record) > 0 && len(*play) > 0:
+		err = fmt.Errorf("--record and --replay may not both be given")
+	case len(*record) > 0:
+		if len(*upstream) == 0 {
+			err = fmt.Errorf("--record requires an --upstream node")
+		} else {
+			transport, err = replay.NewTransport(replay.Record, *record, nil)
+		}
+	case len(*play) > 0:
+		transport, err = replay.NewTransport(replay.Replay, *play, nil)
+	default:
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	logger.Info("Serving JSON-RPC at", *addr, "in", transport.Mode, "mode using fixtures in", transport.Dir)
+	if err := replay.ListenAndServe(*addr, transport, *upstream); err != nil {
+		logger.Fatal(err)
+	}
+}
@@ -37,11 +37,19 @@ func DoExport() {
 	topic := fuzzTopics
 	reverted := []bool{false, true}
 	asset := fuzzAssets
+	nfts := []bool{false, true}
 	// Option 'flow.enum' is an emum
+	// Option 'intra.enum' is an emum
+	// Option 'costBasis.enum' is an emum
+	// Option 'period.enum' is an emum
+	// Option 'journal.enum' is an emum
 	factory := []bool{false, true}
 	unripe := []bool{false, true}
 	reversed := []bool{false, true}
 	noZero := []bool{false, true}
+	// entity is a <string> --other
+	// currency is a <string> --other
+	// cursor is a <string> --other
 	// firstBlock is a <blknum> --other
 	// lastBlock is a <blknum> --other
 	// firstRecord is not fuzzed
@@ -52,6 +60,7 @@ func DoExport() {
 	_ = topic
 	_ = fourbytes
 	_ = articulate
+	_ = nfts
 	baseFn := "export/export"
 	opts = sdk.ExportOptions{
 		Addrs:       fuzzAddresses,
@@ -194,6 +203,36 @@ func TestExport(which, value, fn string, opts *sdk.ExportOptions) {
 				ReportOkay(fn)
 			}
 		}
+	case "diagnose":
+		if diagnose, _, err := opts.ExportDiagnose(); err != nil {
+			ReportError(fn, opts, err)
+		} else {
+			if err := SaveToFile[types.Discrepancy](fn, diagnose); err != nil {
+				ReportError2(fn, err)
+			} else {
+				ReportOkay(fn)
+			}
+		}
+	case "gains":
+		if gains, _, err := opts.ExportGains(); err != nil {
+			ReportError(fn, opts, err)
+		} else {
+			if err := SaveToFile[types.Disposal](fn, gains); err != nil {
+				ReportError2(fn, err)
+			} else {
+				ReportOkay(fn)
+			}
+		}
+	case "period":
+		if period, _, err := opts.ExportPeriod(value); err != nil {
+			ReportError(fn, opts, err)
+		} else {
+			if err := SaveToFile[types.GainSummary](fn, period); err != nil {
+				ReportError2(fn, err)
+			} else {
+				ReportOkay(fn)
+			}
+		}
 	default:
 		ReportError(fn, opts, fmt.Errorf("unknown which: %s", which))
 		logger.Fatal("Quitting...")